@@ -78,20 +78,21 @@ func (at *AssertionType) SequenceForming() bool {
 
 // Understood assertion types.
 var (
-	AccountType         = &AssertionType{"account", []string{"account-id"}, assembleAccount, 0}
-	AccountKeyType      = &AssertionType{"account-key", []string{"public-key-sha3-384"}, assembleAccountKey, 0}
-	RepairType          = &AssertionType{"repair", []string{"brand-id", "repair-id"}, assembleRepair, sequenceForming}
-	ModelType           = &AssertionType{"model", []string{"series", "brand-id", "model"}, assembleModel, 0}
-	SerialType          = &AssertionType{"serial", []string{"brand-id", "model", "serial"}, assembleSerial, 0}
-	BaseDeclarationType = &AssertionType{"base-declaration", []string{"series"}, assembleBaseDeclaration, 0}
-	SnapDeclarationType = &AssertionType{"snap-declaration", []string{"series", "snap-id"}, assembleSnapDeclaration, 0}
-	SnapBuildType       = &AssertionType{"snap-build", []string{"snap-sha3-384"}, assembleSnapBuild, 0}
-	SnapRevisionType    = &AssertionType{"snap-revision", []string{"snap-sha3-384"}, assembleSnapRevision, 0}
-	SnapDeveloperType   = &AssertionType{"snap-developer", []string{"snap-id", "publisher-id"}, assembleSnapDeveloper, 0}
-	SystemUserType      = &AssertionType{"system-user", []string{"brand-id", "email"}, assembleSystemUser, 0}
-	ValidationType      = &AssertionType{"validation", []string{"series", "snap-id", "approved-snap-id", "approved-snap-revision"}, assembleValidation, 0}
-	ValidationSetType   = &AssertionType{"validation-set", []string{"series", "account-id", "name", "sequence"}, assembleValidationSet, sequenceForming}
-	StoreType           = &AssertionType{"store", []string{"store"}, assembleStore, 0}
+	AccountType           = &AssertionType{"account", []string{"account-id"}, assembleAccount, 0}
+	AccountKeyType        = &AssertionType{"account-key", []string{"public-key-sha3-384"}, assembleAccountKey, 0}
+	RepairType            = &AssertionType{"repair", []string{"brand-id", "repair-id"}, assembleRepair, sequenceForming}
+	ModelType             = &AssertionType{"model", []string{"series", "brand-id", "model"}, assembleModel, 0}
+	SerialType            = &AssertionType{"serial", []string{"brand-id", "model", "serial"}, assembleSerial, 0}
+	BaseDeclarationType   = &AssertionType{"base-declaration", []string{"series"}, assembleBaseDeclaration, 0}
+	SnapDeclarationType   = &AssertionType{"snap-declaration", []string{"series", "snap-id"}, assembleSnapDeclaration, 0}
+	SnapBuildType         = &AssertionType{"snap-build", []string{"snap-sha3-384"}, assembleSnapBuild, 0}
+	SnapRevisionType      = &AssertionType{"snap-revision", []string{"snap-sha3-384"}, assembleSnapRevision, 0}
+	SnapDeveloperType     = &AssertionType{"snap-developer", []string{"snap-id", "publisher-id"}, assembleSnapDeveloper, 0}
+	SystemUserType        = &AssertionType{"system-user", []string{"brand-id", "email"}, assembleSystemUser, 0}
+	ValidationType        = &AssertionType{"validation", []string{"series", "snap-id", "approved-snap-id", "approved-snap-revision"}, assembleValidation, 0}
+	ValidationSetType     = &AssertionType{"validation-set", []string{"series", "account-id", "name", "sequence"}, assembleValidationSet, sequenceForming}
+	StoreType             = &AssertionType{"store", []string{"store"}, assembleStore, 0}
+	ConnectionProfileType = &AssertionType{"connection-profile", []string{"brand-id", "model", "name"}, assembleConnectionProfile, 0}
 
 // ...
 )
@@ -104,20 +105,21 @@ var (
 )
 
 var typeRegistry = map[string]*AssertionType{
-	AccountType.Name:         AccountType,
-	AccountKeyType.Name:      AccountKeyType,
-	ModelType.Name:           ModelType,
-	SerialType.Name:          SerialType,
-	BaseDeclarationType.Name: BaseDeclarationType,
-	SnapDeclarationType.Name: SnapDeclarationType,
-	SnapBuildType.Name:       SnapBuildType,
-	SnapRevisionType.Name:    SnapRevisionType,
-	SnapDeveloperType.Name:   SnapDeveloperType,
-	SystemUserType.Name:      SystemUserType,
-	ValidationType.Name:      ValidationType,
-	ValidationSetType.Name:   ValidationSetType,
-	RepairType.Name:          RepairType,
-	StoreType.Name:           StoreType,
+	AccountType.Name:           AccountType,
+	AccountKeyType.Name:        AccountKeyType,
+	ModelType.Name:             ModelType,
+	SerialType.Name:            SerialType,
+	BaseDeclarationType.Name:   BaseDeclarationType,
+	SnapDeclarationType.Name:   SnapDeclarationType,
+	SnapBuildType.Name:         SnapBuildType,
+	SnapRevisionType.Name:      SnapRevisionType,
+	SnapDeveloperType.Name:     SnapDeveloperType,
+	SystemUserType.Name:        SystemUserType,
+	ValidationType.Name:        ValidationType,
+	ValidationSetType.Name:     ValidationSetType,
+	RepairType.Name:            RepairType,
+	StoreType.Name:             StoreType,
+	ConnectionProfileType.Name: ConnectionProfileType,
 	// no authority
 	DeviceSessionRequestType.Name: DeviceSessionRequestType,
 	SerialRequestType.Name:        SerialRequestType,
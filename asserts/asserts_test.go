@@ -52,6 +52,7 @@ func (as *assertsSuite) TestTypeNames(c *C) {
 		"account-key",
 		"account-key-request",
 		"base-declaration",
+		"connection-profile",
 		"device-session-request",
 		"model",
 		"repair",
@@ -941,6 +942,7 @@ func (as *assertsSuite) TestWithAuthority(c *C) {
 		"account",
 		"account-key",
 		"base-declaration",
+		"connection-profile",
 		"store",
 		"snap-declaration",
 		"snap-build",
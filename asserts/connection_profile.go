@@ -0,0 +1,180 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package asserts
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/snap/naming"
+)
+
+var validConnectionProfileName = regexp.MustCompile("^[a-z0-9](?:-?[a-z0-9])*$")
+
+// ConnectionProfileEntry holds the details about a connection listed
+// by a connection-profile assertion.
+type ConnectionProfileEntry struct {
+	// Plug is the plug of the connection as "snap:plug".
+	Plug string
+	// Slot is the slot of the connection as "snap:slot".
+	Slot string
+	// Interface is the interface of the plug and slot.
+	Interface string
+}
+
+// ID returns the identifier of the connection, in the format
+// used by the interfaces code.
+func (e *ConnectionProfileEntry) ID() string {
+	return e.Plug + " " + e.Slot
+}
+
+// ConnectionProfile holds a connection-profile assertion, which is a
+// statement by a brand about the connections that must, or must not, be
+// established on devices of one of its models.
+type ConnectionProfile struct {
+	assertionBase
+	connect   []*ConnectionProfileEntry
+	forbid    []*ConnectionProfileEntry
+	timestamp time.Time
+}
+
+// BrandID returns the brand identifier. Same as the authority id.
+func (prof *ConnectionProfile) BrandID() string {
+	return prof.HeaderString("brand-id")
+}
+
+// Model returns the name of the model the profile applies to.
+func (prof *ConnectionProfile) Model() string {
+	return prof.HeaderString("model")
+}
+
+// Name returns the name of the profile.
+func (prof *ConnectionProfile) Name() string {
+	return prof.HeaderString("name")
+}
+
+// Connect returns the connections that must be established.
+func (prof *ConnectionProfile) Connect() []*ConnectionProfileEntry {
+	return prof.connect
+}
+
+// Forbid returns the connections that must not be established.
+func (prof *ConnectionProfile) Forbid() []*ConnectionProfileEntry {
+	return prof.forbid
+}
+
+// Timestamp returns the time when the connection-profile was issued.
+func (prof *ConnectionProfile) Timestamp() time.Time {
+	return prof.timestamp
+}
+
+func checkConnectionProfileRef(entry map[string]interface{}, name, what string) (string, error) {
+	ref, err := checkNotEmptyStringWhat(entry, name, what)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.Split(ref, ":")
+	if len(parts) != 2 || naming.ValidateInstance(parts[0]) != nil || !validInterfaceName.MatchString(parts[1]) {
+		return "", fmt.Errorf("%q %s must be of the form snap:name, got %q", name, what, ref)
+	}
+	return ref, nil
+}
+
+func checkConnectionProfileEntries(headers map[string]interface{}, name string) ([]*ConnectionProfileEntry, error) {
+	value, ok := headers[name]
+	if !ok {
+		return nil, nil
+	}
+	wrongHeaderType := fmt.Sprintf("%q header must be a list of maps", name)
+	lst, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf(wrongHeaderType)
+	}
+	entries := make([]*ConnectionProfileEntry, 0, len(lst))
+	for _, v := range lst {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(wrongHeaderType)
+		}
+		what := fmt.Sprintf("of connection in %q header", name)
+		plug, err := checkConnectionProfileRef(m, "plug", what)
+		if err != nil {
+			return nil, err
+		}
+		slot, err := checkConnectionProfileRef(m, "slot", what)
+		if err != nil {
+			return nil, err
+		}
+		iface, err := checkStringMatchesWhat(m, "interface", what, validInterfaceName)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &ConnectionProfileEntry{
+			Plug:      plug,
+			Slot:      slot,
+			Interface: iface,
+		})
+	}
+	return entries, nil
+}
+
+func assembleConnectionProfile(assert assertionBase) (Assertion, error) {
+	if err := checkAuthorityMatchesBrand(&assert); err != nil {
+		return nil, err
+	}
+
+	if _, err := checkModel(assert.headers); err != nil {
+		return nil, err
+	}
+
+	if _, err := checkStringMatches(assert.headers, "name", validConnectionProfileName); err != nil {
+		return nil, err
+	}
+
+	connect, err := checkConnectionProfileEntries(assert.headers, "connect")
+	if err != nil {
+		return nil, err
+	}
+	forbid, err := checkConnectionProfileEntries(assert.headers, "forbid")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(connect)+len(forbid))
+	for _, entry := range append(connect, forbid...) {
+		if seen[entry.ID()] {
+			return nil, fmt.Errorf("cannot list the same connection %q multiple times", entry.ID())
+		}
+		seen[entry.ID()] = true
+	}
+
+	timestamp, err := checkRFC3339Date(assert.headers, "timestamp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConnectionProfile{
+		assertionBase: assert,
+		connect:       connect,
+		forbid:        forbid,
+		timestamp:     timestamp,
+	}, nil
+}
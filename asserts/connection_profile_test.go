@@ -0,0 +1,127 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2016 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package asserts_test
+
+import (
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+)
+
+type connectionProfileSuite struct {
+	ts     time.Time
+	tsLine string
+}
+
+var _ = Suite(&connectionProfileSuite{})
+
+func (s *connectionProfileSuite) SetUpSuite(c *C) {
+	s.ts = time.Now().Truncate(time.Second).UTC()
+	s.tsLine = "timestamp: " + s.ts.Format(time.RFC3339) + "\n"
+}
+
+const (
+	profileConnect = "connect:\n" +
+		"  -\n" +
+		"    plug: consumer:plug\n" +
+		"    slot: producer:slot\n" +
+		"    interface: test\n"
+	profileForbid = "forbid:\n" +
+		"  -\n" +
+		"    plug: consumer:camera\n" +
+		"    slot: core:camera\n" +
+		"    interface: camera\n"
+
+	connectionProfileExample = "type: connection-profile\n" +
+		"authority-id: brand-id1\n" +
+		"brand-id: brand-id1\n" +
+		"model: baz-3000\n" +
+		"name: default\n" +
+		profileConnect +
+		profileForbid +
+		"TSLINE" +
+		"body-length: 0\n" +
+		"sign-key-sha3-384: Jv8_JiHiIzJVcO9M55pPdqSDWUvuhfDIBJUS-3VW7F_idjix7Ffn5qMxB21ZQuij" +
+		"\n\n" +
+		"AXNpZw=="
+)
+
+func (s *connectionProfileSuite) TestDecodeOK(c *C) {
+	encoded := strings.Replace(connectionProfileExample, "TSLINE", s.tsLine, 1)
+	a, err := asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	c.Check(a.Type(), Equals, asserts.ConnectionProfileType)
+	prof := a.(*asserts.ConnectionProfile)
+	c.Check(prof.AuthorityID(), Equals, "brand-id1")
+	c.Check(prof.BrandID(), Equals, "brand-id1")
+	c.Check(prof.Model(), Equals, "baz-3000")
+	c.Check(prof.Name(), Equals, "default")
+	c.Check(prof.Timestamp(), Equals, s.ts)
+	c.Check(prof.Connect(), DeepEquals, []*asserts.ConnectionProfileEntry{
+		{Plug: "consumer:plug", Slot: "producer:slot", Interface: "test"},
+	})
+	c.Check(prof.Forbid(), DeepEquals, []*asserts.ConnectionProfileEntry{
+		{Plug: "consumer:camera", Slot: "core:camera", Interface: "camera"},
+	})
+	c.Check(prof.Connect()[0].ID(), Equals, "consumer:plug producer:slot")
+}
+
+func (s *connectionProfileSuite) TestDecodeConnectAndForbidAreOptional(c *C) {
+	encoded := strings.Replace(connectionProfileExample, "TSLINE", s.tsLine, 1)
+	encoded = strings.Replace(encoded, profileConnect, "", 1)
+	encoded = strings.Replace(encoded, profileForbid, "", 1)
+	a, err := asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	prof := a.(*asserts.ConnectionProfile)
+	c.Check(prof.Connect(), HasLen, 0)
+	c.Check(prof.Forbid(), HasLen, 0)
+}
+
+const connectionProfileErrPrefix = "assertion connection-profile: "
+
+func (s *connectionProfileSuite) TestDecodeInvalid(c *C) {
+	encoded := strings.Replace(connectionProfileExample, "TSLINE", s.tsLine, 1)
+
+	invalidTests := []struct{ original, invalid, expectedErr string }{
+		{"brand-id: brand-id1\n", "brand-id: random\n", `authority-id and brand-id must match, connection-profile assertions are expected to be signed by the brand: "brand-id1" != "random"`},
+		{"model: baz-3000\n", "", `"model" header is mandatory`},
+		{"model: baz-3000\n", "model: BAZ-3000\n", `"model" header cannot contain uppercase letters`},
+		{"name: default\n", "", `"name" header is mandatory`},
+		{"name: default\n", "name: -default\n", `"name" header contains invalid characters: "-default"`},
+		{profileConnect, "connect: foo\n", `"connect" header must be a list of maps`},
+		{profileConnect, "connect:\n  - foo\n", `"connect" header must be a list of maps`},
+		{"    plug: consumer:plug\n", "", `"plug" of connection in "connect" header is mandatory`},
+		{"plug: consumer:plug\n", "plug: consumer\n", `"plug" of connection in "connect" header must be of the form snap:name, got "consumer"`},
+		{"slot: producer:slot\n", "slot: producer:slot:x\n", `"slot" of connection in "connect" header must be of the form snap:name, got "producer:slot:x"`},
+		{"    interface: test\n", "", `"interface" of connection in "connect" header is mandatory`},
+		{"    interface: test\n", "    interface: Test\n", `"interface" of connection in "connect" header contains invalid characters: "Test"`},
+		{"plug: consumer:camera\n    slot: core:camera\n", "plug: consumer:plug\n    slot: producer:slot\n", `cannot list the same connection "consumer:plug producer:slot" multiple times`},
+		{s.tsLine, "", `"timestamp" header is mandatory`},
+	}
+
+	for _, test := range invalidTests {
+		invalid := strings.Replace(encoded, test.original, test.invalid, 1)
+		_, err := asserts.Decode([]byte(invalid))
+		c.Check(err, ErrorMatches, connectionProfileErrPrefix+test.expectedErr)
+	}
+}
@@ -403,7 +403,11 @@ type Model struct {
 
 	serialAuthority  []string
 	sysUserAuthority []string
-	timestamp        time.Time
+
+	allowedInterfaces    []string
+	disallowedInterfaces []string
+
+	timestamp time.Time
 }
 
 // BrandID returns the brand identifier. Same as the authority id.
@@ -552,6 +556,28 @@ func (mod *Model) SystemUserAuthority() []string {
 	return mod.sysUserAuthority
 }
 
+// AllowedInterfaces returns the names of the interfaces that are
+// exclusively permitted on devices of this model. An empty list means
+// that no such restriction applies.
+func (mod *Model) AllowedInterfaces() []string {
+	return mod.allowedInterfaces
+}
+
+// DisallowedInterfaces returns the names of the interfaces that are not
+// permitted on devices of this model.
+func (mod *Model) DisallowedInterfaces() []string {
+	return mod.disallowedInterfaces
+}
+
+// InterfaceAllowed returns whether the named interface is permitted on
+// devices of this model.
+func (mod *Model) InterfaceAllowed(name string) bool {
+	if len(mod.allowedInterfaces) != 0 && !strutil.ListContains(mod.allowedInterfaces, name) {
+		return false
+	}
+	return !strutil.ListContains(mod.disallowedInterfaces, name)
+}
+
 // Timestamp returns the time when the model assertion was issued.
 func (mod *Model) Timestamp() time.Time {
 	return mod.timestamp
@@ -631,6 +657,23 @@ func checkOptionalSystemUserAuthority(headers map[string]interface{}, brandID st
 	return nil, fmt.Errorf("%q header must be '*' or a list of account ids", name)
 }
 
+var validInterfaceName = regexp.MustCompile("^[a-z](?:-?[a-z0-9])*$")
+
+func checkOptionalInterfaces(headers map[string]interface{}) (allowed, disallowed []string, err error) {
+	allowed, err = checkStringListMatches(headers, "allowed-interfaces", validInterfaceName)
+	if err != nil {
+		return nil, nil, err
+	}
+	disallowed, err = checkStringListMatches(headers, "disallowed-interfaces", validInterfaceName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(allowed) != 0 && len(disallowed) != 0 {
+		return nil, nil, fmt.Errorf(`cannot specify both "allowed-interfaces" and "disallowed-interfaces" headers`)
+	}
+	return allowed, disallowed, nil
+}
+
 var (
 	modelMandatory           = []string{"architecture", "gadget", "kernel"}
 	extendedCoreMandatory    = []string{"architecture", "base"}
@@ -824,6 +867,11 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 		return nil, err
 	}
 
+	allowedInterfaces, disallowedInterfaces, err := checkOptionalInterfaces(assert.headers)
+	if err != nil {
+		return nil, err
+	}
+
 	timestamp, err := checkRFC3339Date(assert.headers, "timestamp")
 	if err != nil {
 		return nil, err
@@ -852,6 +900,8 @@ func assembleModel(assert assertionBase) (Assertion, error) {
 		numEssentialSnaps:          numEssentialSnaps,
 		serialAuthority:            serialAuthority,
 		sysUserAuthority:           sysUserAuthority,
+		allowedInterfaces:          allowedInterfaces,
+		disallowedInterfaces:       disallowedInterfaces,
 		timestamp:                  timestamp,
 	}, nil
 }
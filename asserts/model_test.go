@@ -402,6 +402,33 @@ func (mods *modelSuite) TestDecodeSystemUserAuthorityIsOptional(c *C) {
 	c.Check(model.SystemUserAuthority(), DeepEquals, []string{"brand-id1", "foo", "bar"})
 }
 
+func (mods *modelSuite) TestDecodeInterfaces(c *C) {
+	withTimestamp := strings.Replace(modelExample, "TSLINE", mods.tsLine, 1)
+	a, err := asserts.Decode([]byte(withTimestamp))
+	c.Assert(err, IsNil)
+	model := a.(*asserts.Model)
+	// no restrictions by default
+	c.Check(model.AllowedInterfaces(), HasLen, 0)
+	c.Check(model.DisallowedInterfaces(), HasLen, 0)
+	c.Check(model.InterfaceAllowed("network"), Equals, true)
+
+	encoded := strings.Replace(withTimestamp, reqSnaps, reqSnaps+"allowed-interfaces:\n  - network\n  - home\n", 1)
+	a, err = asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	model = a.(*asserts.Model)
+	c.Check(model.AllowedInterfaces(), DeepEquals, []string{"network", "home"})
+	c.Check(model.InterfaceAllowed("network"), Equals, true)
+	c.Check(model.InterfaceAllowed("camera"), Equals, false)
+
+	encoded = strings.Replace(withTimestamp, reqSnaps, reqSnaps+"disallowed-interfaces:\n  - camera\n", 1)
+	a, err = asserts.Decode([]byte(encoded))
+	c.Assert(err, IsNil)
+	model = a.(*asserts.Model)
+	c.Check(model.DisallowedInterfaces(), DeepEquals, []string{"camera"})
+	c.Check(model.InterfaceAllowed("network"), Equals, true)
+	c.Check(model.InterfaceAllowed("camera"), Equals, false)
+}
+
 func (mods *modelSuite) TestDecodeKernelTrack(c *C) {
 	withTimestamp := strings.Replace(modelExample, "TSLINE", mods.tsLine, 1)
 	encoded := strings.Replace(withTimestamp, "kernel: baz-linux\n", "kernel: baz-linux=18\n", 1)
@@ -481,6 +508,10 @@ func (mods *modelSuite) TestDecodeInvalid(c *C) {
 		{sysUserAuths, "system-user-authority:\n  a: 1\n", `"system-user-authority" header must be '\*' or a list of account ids`},
 		{sysUserAuths, "system-user-authority:\n  - 5_6\n", `"system-user-authority" header must be '\*' or a list of account ids`},
 		{reqSnaps, "grade: dangerous\n", `cannot specify a grade for model without the extended snaps header`},
+		{reqSnaps, reqSnaps + "allowed-interfaces: network\n", `"allowed-interfaces" header must be a list of strings`},
+		{reqSnaps, reqSnaps + "allowed-interfaces:\n  - Network\n", `"allowed-interfaces" header contains an invalid element: "Network"`},
+		{reqSnaps, reqSnaps + "disallowed-interfaces:\n  - -camera\n", `"disallowed-interfaces" header contains an invalid element: "-camera"`},
+		{reqSnaps, reqSnaps + "allowed-interfaces:\n  - network\ndisallowed-interfaces:\n  - camera\n", `cannot specify both "allowed-interfaces" and "disallowed-interfaces" headers`},
 	}
 
 	for _, test := range invalidTests {
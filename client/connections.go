@@ -20,7 +20,11 @@
 package client
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
 	"net/url"
+	"time"
 )
 
 // Connection describes a connection between a plug and a slot.
@@ -60,6 +64,9 @@ type ConnectionOptions struct {
 	// All when true, selects established and undesired connections as well
 	// as all disconnected plugs and slots.
 	All bool
+	// Unconnected when true, selects only plugs and slots that are not
+	// connected, without any connections. It takes precedence over All.
+	Unconnected bool
 }
 
 // Connections returns matching plugs, slots and their connections. Unless
@@ -76,6 +83,160 @@ func (client *Client) Connections(opts *ConnectionOptions) (Connections, error)
 	if opts != nil && opts.All {
 		query.Set("select", "all")
 	}
+	if opts != nil && opts.Unconnected {
+		query.Set("select", "unconnected")
+	}
 	_, err := client.doSync("GET", "/v2/connections", query, nil, nil, &conns)
+	if isEndpointNotFound(err) {
+		// snapd predating the connections API
+		return client.legacyConnections(opts)
+	}
 	return conns, err
 }
+
+// isEndpointNotFound returns true if the error was returned by snapd for
+// an unknown API endpoint.
+func isEndpointNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == http.StatusNotFound && e.Kind == ""
+}
+
+// legacyConnections computes the connections from the legacy interfaces
+// listing. Manual, gadget and undesired connections are not known there.
+func (client *Client) legacyConnections(opts *ConnectionOptions) (Connections, error) {
+	var legacy struct {
+		Plugs []Plug `json:"plugs"`
+		Slots []Slot `json:"slots"`
+	}
+	if _, err := client.doSync("GET", "/v2/interfaces", nil, nil, nil, &legacy); err != nil {
+		return Connections{}, err
+	}
+	if opts == nil {
+		opts = &ConnectionOptions{}
+	}
+	wanted := func(snapName, ifaceName string, connected bool) bool {
+		if opts.Snap != "" && snapName != opts.Snap {
+			return false
+		}
+		if opts.Interface != "" && ifaceName != opts.Interface {
+			return false
+		}
+		if opts.Unconnected {
+			return !connected
+		}
+		return connected || opts.All
+	}
+
+	var conns Connections
+	for _, plug := range legacy.Plugs {
+		for _, slotRef := range plug.Connections {
+			if opts.Unconnected {
+				break
+			}
+			if opts.Snap != "" && plug.Snap != opts.Snap && slotRef.Snap != opts.Snap {
+				continue
+			}
+			if opts.Interface != "" && plug.Interface != opts.Interface {
+				continue
+			}
+			conns.Established = append(conns.Established, Connection{
+				Plug:      PlugRef{Snap: plug.Snap, Name: plug.Name},
+				Slot:      slotRef,
+				Interface: plug.Interface,
+			})
+		}
+		if wanted(plug.Snap, plug.Interface, len(plug.Connections) > 0) {
+			conns.Plugs = append(conns.Plugs, plug)
+		}
+	}
+	for _, slot := range legacy.Slots {
+		if wanted(slot.Snap, slot.Interface, len(slot.Connections) > 0) {
+			conns.Slots = append(conns.Slots, slot)
+		}
+	}
+	return conns, nil
+}
+
+// ExportConnections returns the profile of the connections of the device,
+// as accepted by ImportConnections. The profile is returned as is, so that
+// it can be stored and imported again without being altered.
+func (client *Client) ExportConnections() (json.RawMessage, error) {
+	var profile json.RawMessage
+	_, err := client.doSync("GET", "/v2/connections/profile", nil, nil, nil, &profile)
+	return profile, err
+}
+
+// ImportConnectionsOptions holds options for importing a connections profile.
+type ImportConnectionsOptions struct {
+	// Replace makes the connections match the profile, connections that
+	// are not in the profile are disconnected. Otherwise the connections
+	// of the profile are merged with the existing ones.
+	Replace bool
+}
+
+// ImportConnections restores the connections of a profile obtained with
+// ExportConnections.
+func (client *Client) ImportConnections(profile json.RawMessage, opts *ImportConnectionsOptions) (changeID string, err error) {
+	if opts == nil {
+		opts = &ImportConnectionsOptions{}
+	}
+	b, err := json.Marshal(map[string]interface{}{
+		"action":  "import",
+		"profile": profile,
+		"replace": opts.Replace,
+	})
+	if err != nil {
+		return "", err
+	}
+	return client.doAsync("POST", "/v2/connections/profile", nil, nil, bytes.NewReader(b))
+}
+
+// ApplyConnectionProfile establishes the connections listed by the given
+// encoded connection-profile assertion, signed by the brand of the device,
+// and disconnects the connections it forbids.
+func (client *Client) ApplyConnectionProfile(assertion []byte) (changeID string, err error) {
+	b, err := json.Marshal(map[string]interface{}{
+		"action":    "apply",
+		"assertion": string(assertion),
+	})
+	if err != nil {
+		return "", err
+	}
+	return client.doAsync("POST", "/v2/connections/profile", nil, nil, bytes.NewReader(b))
+}
+
+// ConnectionsSnapshot describes the set of connections as it was before a
+// change first modified it.
+type ConnectionsSnapshot struct {
+	// Time is when the change first modified the connections.
+	Time time.Time `json:"time"`
+	// ChangeID is the ID of the change.
+	ChangeID string `json:"change-id"`
+	// Connections are the IDs of the connections, as in
+	// "<snap>:<plug> <snap>:<slot>".
+	Connections []string `json:"connections"`
+}
+
+// ConnectionsHistory returns the recorded sets of connections, oldest
+// first.
+func (client *Client) ConnectionsHistory() ([]ConnectionsSnapshot, error) {
+	var history []ConnectionsSnapshot
+	_, err := client.doSync("GET", "/v2/connections/history", nil, nil, nil, &history)
+	return history, err
+}
+
+// RevertConnections restores the connections to what they were at the given
+// time, or before the last change that modified them if the time is zero.
+func (client *Client) RevertConnections(at time.Time) (changeID string, err error) {
+	action := map[string]interface{}{
+		"action": "revert",
+	}
+	if !at.IsZero() {
+		action["time"] = at
+	}
+	b, err := json.Marshal(action)
+	if err != nil {
+		return "", err
+	}
+	return client.doAsync("POST", "/v2/connections/history", nil, nil, bytes.NewReader(b))
+}
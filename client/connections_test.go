@@ -20,7 +20,9 @@
 package client_test
 
 import (
+	"encoding/json"
 	"net/url"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -249,6 +251,11 @@ func (cs *clientSuite) TestClientConnectionsFilter(c *check.C) {
 	c.Check(cs.req.URL.Path, check.Equals, "/v2/connections")
 	c.Check(cs.req.URL.RawQuery, check.Equals, "select=all")
 
+	_, err = cs.cli.Connections(&client.ConnectionOptions{Unconnected: true})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/connections")
+	c.Check(cs.req.URL.RawQuery, check.Equals, "select=unconnected")
+
 	_, err = cs.cli.Connections(&client.ConnectionOptions{Snap: "foo"})
 	c.Assert(err, check.IsNil)
 	c.Check(cs.req.URL.Path, check.Equals, "/v2/connections")
@@ -268,3 +275,197 @@ func (cs *clientSuite) TestClientConnectionsFilter(c *check.C) {
 		"snap":      []string{"foo"},
 	})
 }
+
+func (cs *clientSuite) TestClientConnectionsLegacyFallback(c *check.C) {
+	cs.status = 404
+	cs.rsps = []string{`{
+		"type": "error",
+		"status-code": 404,
+		"result": {"message": "not found"}
+	}`, `{
+		"type": "sync",
+		"result": {
+			"plugs": [
+				{
+					"snap": "canonical-pi2",
+					"plug": "pin-13",
+					"interface": "bool-file",
+					"connections": [
+						{"snap": "keyboard-lights", "slot": "capslock-led"}
+					]
+				},
+				{
+					"snap": "canonical-pi2",
+					"plug": "pin-14",
+					"interface": "bool-file"
+				}
+			],
+			"slots": [
+				{
+					"snap": "keyboard-lights",
+					"slot": "capslock-led",
+					"interface": "bool-file",
+					"connections": [
+						{"snap": "canonical-pi2", "plug": "pin-13"}
+					]
+				}
+			]
+		}
+	}`}
+	conns, err := cs.cli.Connections(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(cs.reqs, check.HasLen, 2)
+	c.Check(cs.reqs[0].URL.Path, check.Equals, "/v2/connections")
+	c.Check(cs.reqs[1].URL.Path, check.Equals, "/v2/interfaces")
+	c.Check(conns, check.DeepEquals, client.Connections{
+		Established: []client.Connection{
+			{
+				Plug:      client.PlugRef{Snap: "canonical-pi2", Name: "pin-13"},
+				Slot:      client.SlotRef{Snap: "keyboard-lights", Name: "capslock-led"},
+				Interface: "bool-file",
+			},
+		},
+		Plugs: []client.Plug{
+			{
+				Snap:      "canonical-pi2",
+				Name:      "pin-13",
+				Interface: "bool-file",
+				Connections: []client.SlotRef{
+					{Snap: "keyboard-lights", Name: "capslock-led"},
+				},
+			},
+		},
+		Slots: []client.Slot{
+			{
+				Snap:      "keyboard-lights",
+				Name:      "capslock-led",
+				Interface: "bool-file",
+				Connections: []client.PlugRef{
+					{Snap: "canonical-pi2", Name: "pin-13"},
+				},
+			},
+		},
+	})
+
+	// unconnected plugs and slots are listed with --all
+	cs.doCalls = 0
+	cs.reqs = nil
+	conns, err = cs.cli.Connections(&client.ConnectionOptions{All: true, Snap: "canonical-pi2"})
+	c.Assert(err, check.IsNil)
+	c.Check(conns.Established, check.HasLen, 1)
+	c.Check(conns.Plugs, check.HasLen, 2)
+	c.Check(conns.Slots, check.HasLen, 0)
+}
+
+func (cs *clientSuite) TestClientConnectionsSnapNotFoundNoFallback(c *check.C) {
+	cs.status = 404
+	cs.rsp = `{
+		"type": "error",
+		"status-code": 404,
+		"result": {"message": "snap \"foo\" not found", "kind": "snap-not-found"}
+	}`
+	_, err := cs.cli.Connections(&client.ConnectionOptions{Snap: "foo"})
+	c.Assert(err, check.ErrorMatches, `snap "foo" not found`)
+	c.Check(cs.reqs, check.HasLen, 1)
+}
+
+func (cs *clientSuite) TestClientExportConnections(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": {"connections": {"consumer:plug producer:slot": {"interface": "test"}}, "checksum": "1234"}
+	}`
+	profile, err := cs.cli.ExportConnections()
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/connections/profile")
+	c.Check(string(profile), check.Equals, `{"connections": {"consumer:plug producer:slot": {"interface": "test"}}, "checksum": "1234"}`)
+}
+
+func (cs *clientSuite) TestClientImportConnections(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"result": { },
+		"change": "foo"
+	}`
+	profile := json.RawMessage(`{"connections":{},"checksum":"1234"}`)
+	id, err := cs.cli.ImportConnections(profile, &client.ImportConnectionsOptions{Replace: true})
+	c.Assert(err, check.IsNil)
+	c.Check(id, check.Equals, "foo")
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/connections/profile")
+	var body map[string]interface{}
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&body), check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]interface{}{
+		"action": "import",
+		"profile": map[string]interface{}{
+			"connections": map[string]interface{}{},
+			"checksum":    "1234",
+		},
+		"replace": true,
+	})
+}
+
+func (cs *clientSuite) TestClientApplyConnectionProfile(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"result": { },
+		"change": "foo"
+	}`
+	id, err := cs.cli.ApplyConnectionProfile([]byte("type: connection-profile\n"))
+	c.Assert(err, check.IsNil)
+	c.Check(id, check.Equals, "foo")
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/connections/profile")
+	var body map[string]interface{}
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&body), check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]interface{}{
+		"action":    "apply",
+		"assertion": "type: connection-profile\n",
+	})
+}
+
+func (cs *clientSuite) TestClientConnectionsHistory(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [{"time": "2021-06-01T10:00:00Z", "change-id": "1", "connections": ["consumer:plug producer:slot"]}]
+	}`
+	history, err := cs.cli.ConnectionsHistory()
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/connections/history")
+	c.Check(history, check.DeepEquals, []client.ConnectionsSnapshot{{
+		Time:        time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+		ChangeID:    "1",
+		Connections: []string{"consumer:plug producer:slot"},
+	}})
+}
+
+func (cs *clientSuite) TestClientRevertConnections(c *check.C) {
+	for _, t := range []struct {
+		at   time.Time
+		body map[string]interface{}
+	}{
+		{time.Time{}, map[string]interface{}{"action": "revert"}},
+		{time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC), map[string]interface{}{"action": "revert", "time": "2021-06-01T10:00:00Z"}},
+	} {
+		cs.status = 202
+		cs.rsp = `{
+			"type": "async",
+			"status-code": 202,
+			"result": { },
+			"change": "foo"
+		}`
+		id, err := cs.cli.RevertConnections(t.at)
+		c.Assert(err, check.IsNil)
+		c.Check(id, check.Equals, "foo")
+		c.Check(cs.req.Method, check.Equals, "POST")
+		c.Check(cs.req.URL.Path, check.Equals, "/v2/connections/history")
+		var body map[string]interface{}
+		c.Assert(json.NewDecoder(cs.req.Body).Decode(&body), check.IsNil)
+		c.Check(body, check.DeepEquals, t.body)
+	}
+}
@@ -118,6 +118,12 @@ const (
 	// ErrorKindInterfacesUnchanged: the requested interfaces'
 	// operation would have no effect.
 	ErrorKindInterfacesUnchanged ErrorKind = "interfaces-unchanged"
+	// ErrorKindInterfacesPlugOrSlotNotFound: the plug or slot
+	// referenced by an interfaces' operation does not exist.
+	ErrorKindInterfacesPlugOrSlotNotFound ErrorKind = "interfaces-plug-or-slot-not-found"
+	// ErrorKindInterfacesConnectionNotAllowed: the policy rules of
+	// the snap declarations do not allow the requested connection.
+	ErrorKindInterfacesConnectionNotAllowed ErrorKind = "interfaces-connection-not-allowed"
 
 	// ErrorKindBadQuery: a bad query was provided.
 	ErrorKindBadQuery ErrorKind = "bad-query"
@@ -20,9 +20,12 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -67,14 +70,21 @@ type Interface struct {
 	DocURL  string `json:"doc-url,omitempty"`
 	Plugs   []Plug `json:"plugs,omitempty"`
 	Slots   []Slot `json:"slots,omitempty"`
+
+	ImplicitOnCore    bool `json:"implicit-on-core,omitempty"`
+	ImplicitOnClassic bool `json:"implicit-on-classic,omitempty"`
 }
 
 // InterfaceAction represents an action performed on the interface system.
 type InterfaceAction struct {
 	Action string `json:"action"`
 	Forget bool   `json:"forget,omitempty"`
+	Force  bool   `json:"force,omitempty"`
+	DryRun bool   `json:"dry-run,omitempty"`
 	Plugs  []Plug `json:"plugs,omitempty"`
 	Slots  []Slot `json:"slots,omitempty"`
+	// Interface is the interface of a pending connection.
+	Interface string `json:"interface,omitempty"`
 }
 
 // InterfaceOptions represents opt-in elements include in responses.
@@ -84,11 +94,21 @@ type InterfaceOptions struct {
 	Plugs     bool
 	Slots     bool
 	Connected bool
+	// Connections includes the connections of each returned plug and slot.
+	Connections bool
+	// After returns only the interfaces with names sorting after it,
+	// typically the name of the last interface of the previous page.
+	After string
+	// Limit returns at most this many interfaces if non-zero.
+	Limit int
 }
 
 // DisconnectOptions represents extra options for disconnect op
 type DisconnectOptions struct {
 	Forget bool
+	// Force disconnects all connections of the given plug and slot,
+	// an empty plug or slot name stands for all plugs or slots of the snap.
+	Force bool
 }
 
 func (client *Client) Interfaces(opts *InterfaceOptions) ([]*Interface, error) {
@@ -106,6 +126,15 @@ func (client *Client) Interfaces(opts *InterfaceOptions) ([]*Interface, error) {
 		if opts.Slots {
 			query.Set("slots", "true") // Return slots of each selected interface.
 		}
+		if opts.Connections {
+			query.Set("connections", "true") // Return connections of each plug and slot.
+		}
+		if opts.After != "" {
+			query.Set("after", opts.After) // Return interfaces past this one.
+		}
+		if opts.Limit > 0 {
+			query.Set("limit", strconv.Itoa(opts.Limit)) // Return at most this many interfaces.
+		}
 	}
 	// NOTE: Presence of "select" triggers the use of the new response format.
 	if opts != nil && opts.Connected {
@@ -138,12 +167,118 @@ func (client *Client) Connect(plugSnapName, plugName, slotSnapName, slotName str
 	})
 }
 
+// ConnectPending records the intent to connect a plug to a slot of the
+// given interface once both of them are available, that is once the snap
+// missing one of them is installed or the hotplug device providing the slot
+// shows up.
+func (client *Client) ConnectPending(plugSnapName, plugName, slotSnapName, slotName, iface string) error {
+	b, err := json.Marshal(&InterfaceAction{
+		Action:    "connect-pending",
+		Plugs:     []Plug{{Snap: plugSnapName, Name: plugName}},
+		Slots:     []Slot{{Snap: slotSnapName, Name: slotName}},
+		Interface: iface,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = client.doSync("POST", "/v2/interfaces", nil, nil, bytes.NewReader(b), nil)
+	return err
+}
+
+// ConnectionPreview holds the snippets a connection would add to the
+// security artefacts of the snaps involved, keyed by security system and
+// then by security tag.
+type ConnectionPreview struct {
+	Plug     PlugRef                        `json:"plug"`
+	Slot     SlotRef                        `json:"slot"`
+	Snippets map[string]map[string][]string `json:"snippets,omitempty"`
+}
+
+// PreviewConnect reports the security policy that connecting a plug to a
+// slot would introduce, without establishing the connection.
+func (client *Client) PreviewConnect(plugSnapName, plugName, slotSnapName, slotName string) ([]ConnectionPreview, error) {
+	b, err := json.Marshal(&InterfaceAction{
+		Action: "connect",
+		DryRun: true,
+		Plugs:  []Plug{{Snap: plugSnapName, Name: plugName}},
+		Slots:  []Slot{{Snap: slotSnapName, Name: slotName}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var previews []ConnectionPreview
+	if _, err := client.doSync("POST", "/v2/interfaces", nil, nil, bytes.NewReader(b), &previews); err != nil {
+		return nil, err
+	}
+	return previews, nil
+}
+
 // Disconnect breaks the connection between a plug and a slot.
 func (client *Client) Disconnect(plugSnapName, plugName, slotSnapName, slotName string, opts *DisconnectOptions) (changeID string, err error) {
 	return client.performInterfaceAction(&InterfaceAction{
 		Action: "disconnect",
 		Forget: opts != nil && opts.Forget,
+		Force:  opts != nil && opts.Force,
 		Plugs:  []Plug{{Snap: plugSnapName, Name: plugName}},
 		Slots:  []Slot{{Snap: slotSnapName, Name: slotName}},
 	})
 }
+
+// InterfaceEvent describes a change made to the plugs, slots or connections
+// known to snapd.
+type InterfaceEvent struct {
+	// Kind is one of snap-added, snap-removed, plug-added, plug-removed,
+	// slot-added, slot-removed, connected or disconnected.
+	Kind string `json:"kind"`
+	Snap string `json:"snap"`
+	// Name is the name of the plug or slot of plug and slot events.
+	Name string `json:"name,omitempty"`
+	// Plug and Slot are set for connection events only.
+	Plug *PlugRef `json:"plug,omitempty"`
+	Slot *SlotRef `json:"slot,omitempty"`
+	// Error is set on the last event of the stream when snapd gave up on
+	// sending events, e.g. because they were not consumed fast enough.
+	Error string `json:"error,omitempty"`
+}
+
+// InterfaceEvents streams changes to the plugs, slots and connections as
+// they happen. The returned channel is closed when the stream ends, which
+// happens when the context is cancelled or snapd goes away.
+func (client *Client) InterfaceEvents(ctx context.Context) (<-chan InterfaceEvent, error) {
+	rsp, err := client.raw(ctx, "GET", "/v2/interfaces/events", nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.StatusCode != 200 {
+		var r response
+		defer rsp.Body.Close()
+		if err := decodeInto(rsp.Body, &r); err != nil {
+			return nil, err
+		}
+		return nil, r.err(client, rsp.StatusCode)
+	}
+
+	ch := make(chan InterfaceEvent, 20)
+	go func() {
+		// events come in application/json-seq, described in RFC7464, see
+		// Logs for details
+		scanner := bufio.NewScanner(rsp.Body)
+		for scanner.Scan() {
+			buf := scanner.Bytes()
+			idx := bytes.IndexByte(buf, 0x1E)
+			if idx < 0 {
+				continue
+			}
+			var ev InterfaceEvent
+			if err := json.Unmarshal(buf[idx+1:], &ev); err != nil {
+				continue
+			}
+			ch <- ev
+		}
+		close(ch)
+		rsp.Body.Close()
+	}()
+
+	return ch, nil
+}
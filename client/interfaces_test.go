@@ -20,6 +20,7 @@
 package client_test
 
 import (
+	"context"
 	"encoding/json"
 
 	"gopkg.in/check.v1"
@@ -42,6 +43,18 @@ func (cs *clientSuite) TestClientInterfacesOptionEncoding(c *check.C) {
 		"doc=true&names=a%2Cb&plugs=true&select=connected&slots=true")
 }
 
+func (cs *clientSuite) TestClientInterfacesOptionEncodingConnections(c *check.C) {
+	_, _ = cs.cli.Interfaces(&client.InterfaceOptions{
+		Plugs:       true,
+		Slots:       true,
+		Connections: true,
+	})
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/interfaces")
+	c.Check(cs.req.URL.RawQuery, check.Equals,
+		"connections=true&plugs=true&select=all&slots=true")
+}
+
 func (cs *clientSuite) TestClientInterfacesAll(c *check.C) {
 	// Ask for a summary of all interfaces.
 	cs.rsp = `{
@@ -135,6 +148,41 @@ func (cs *clientSuite) TestClientInterfacesSelectedDetails(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestClientInterfacesImplicit(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [
+			{
+				"name": "iface-a",
+				"summary": "the A iface",
+				"doc-url": "http://example.org/ifaces/a",
+				"implicit-on-core": true,
+				"implicit-on-classic": true
+			}
+		]
+	}`
+	opts := &client.InterfaceOptions{Names: []string{"iface-a"}, Doc: true}
+	ifaces, err := cs.cli.Interfaces(opts)
+	c.Assert(err, check.IsNil)
+	c.Check(ifaces, check.DeepEquals, []*client.Interface{
+		{
+			Name:              "iface-a",
+			Summary:           "the A iface",
+			DocURL:            "http://example.org/ifaces/a",
+			ImplicitOnCore:    true,
+			ImplicitOnClassic: true,
+		},
+	})
+}
+
+func (cs *clientSuite) TestClientInterfacesOptionEncodingPaging(c *check.C) {
+	// Choose the page of interfaces to return.
+	_, _ = cs.cli.Interfaces(&client.InterfaceOptions{After: "iface-a", Limit: 10})
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/interfaces")
+	c.Check(cs.req.URL.RawQuery, check.Equals, "after=iface-a&limit=10&select=all")
+}
+
 func (cs *clientSuite) TestClientInterfacesMultiple(c *check.C) {
 	// Ask for multiple interfaces.
 	cs.rsp = `{
@@ -266,3 +314,144 @@ func (cs *clientSuite) TestClientDisconnectForget(c *check.C) {
 		},
 	})
 }
+
+func (cs *clientSuite) TestClientDisconnectForce(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"type": "async",
+		"status-code": 202,
+		"result": { },
+		"change": "42"
+	}`
+	opts := &client.DisconnectOptions{Force: true}
+	id, err := cs.cli.Disconnect("consumer", "", "", "", opts)
+	c.Assert(err, check.IsNil)
+	c.Check(id, check.Equals, "42")
+	var body map[string]interface{}
+	decoder := json.NewDecoder(cs.req.Body)
+	err = decoder.Decode(&body)
+	c.Check(err, check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]interface{}{
+		"action": "disconnect",
+		"force":  true,
+		"plugs": []interface{}{
+			map[string]interface{}{
+				"snap": "consumer",
+				"plug": "",
+			},
+		},
+		"slots": []interface{}{
+			map[string]interface{}{
+				"snap": "",
+				"slot": "",
+			},
+		},
+	})
+}
+
+func (cs *clientSuite) TestClientConnectPending(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": null
+	}`
+	err := cs.cli.ConnectPending("consumer", "plug", "producer", "slot", "test")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/interfaces")
+	var body map[string]interface{}
+	c.Assert(json.NewDecoder(cs.req.Body).Decode(&body), check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]interface{}{
+		"action": "connect-pending",
+		"plugs": []interface{}{
+			map[string]interface{}{
+				"snap": "consumer",
+				"plug": "plug",
+			},
+		},
+		"slots": []interface{}{
+			map[string]interface{}{
+				"snap": "producer",
+				"slot": "slot",
+			},
+		},
+		"interface": "test",
+	})
+}
+
+func (cs *clientSuite) TestClientPreviewConnect(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": [{
+			"plug": {"snap": "consumer", "plug": "plug"},
+			"slot": {"snap": "producer", "slot": "slot"},
+			"snippets": {
+				"apparmor": {"snap.consumer.app": ["/dev/foo rw,"]}
+			}
+		}]
+	}`
+	previews, err := cs.cli.PreviewConnect("consumer", "plug", "producer", "slot")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/interfaces")
+	c.Check(previews, check.DeepEquals, []client.ConnectionPreview{{
+		Plug: client.PlugRef{Snap: "consumer", Name: "plug"},
+		Slot: client.SlotRef{Snap: "producer", Name: "slot"},
+		Snippets: map[string]map[string][]string{
+			"apparmor": {"snap.consumer.app": {"/dev/foo rw,"}},
+		},
+	}})
+	var body map[string]interface{}
+	decoder := json.NewDecoder(cs.req.Body)
+	err = decoder.Decode(&body)
+	c.Check(err, check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]interface{}{
+		"action":  "connect",
+		"dry-run": true,
+		"plugs": []interface{}{
+			map[string]interface{}{
+				"snap": "consumer",
+				"plug": "plug",
+			},
+		},
+		"slots": []interface{}{
+			map[string]interface{}{
+				"snap": "producer",
+				"slot": "slot",
+			},
+		},
+	})
+}
+
+func (cs *clientSuite) TestClientInterfaceEvents(c *check.C) {
+	cs.rsp = "\x1e{\"kind\":\"snap-added\",\"snap\":\"consumer\"}\n" +
+		"junk\n" +
+		"\x1e{\"kind\":\"connected\",\"snap\":\"consumer\",\"plug\":{\"snap\":\"consumer\",\"plug\":\"plug\"},\"slot\":{\"snap\":\"producer\",\"slot\":\"slot\"}}\n" +
+		"\x1e{\"error\": \"too many pending events\"}\n"
+
+	ch, err := cs.cli.InterfaceEvents(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/interfaces/events")
+
+	var events []client.InterfaceEvent
+	for ev := range ch {
+		events = append(events, ev)
+	}
+	c.Check(events, check.DeepEquals, []client.InterfaceEvent{
+		{Kind: "snap-added", Snap: "consumer"},
+		{
+			Kind: "connected",
+			Snap: "consumer",
+			Plug: &client.PlugRef{Snap: "consumer", Name: "plug"},
+			Slot: &client.SlotRef{Snap: "producer", Name: "slot"},
+		},
+		{Error: "too many pending events"},
+	})
+}
+
+func (cs *clientSuite) TestClientInterfaceEventsError(c *check.C) {
+	cs.status = 500
+	cs.rsp = `{"type": "error", "result": {"message": "boom"}}`
+	_, err := cs.cli.InterfaceEvents(context.Background())
+	c.Assert(err, check.ErrorMatches, "boom")
+}
@@ -630,6 +630,7 @@ func (s *startPreseedSuite) TestReset(c *C) {
 			symlinkTarget string
 		}{
 			{dirs.SnapStateFile, ""},
+			{dirs.SnapStateFile + ".prev", ""},
 			{dirs.SnapSystemKeyFile, ""},
 			{filepath.Join(dirs.SnapDesktopFilesDir, "foo.desktop"), ""},
 			{filepath.Join(dirs.SnapDesktopIconsDir, "foo.png"), ""},
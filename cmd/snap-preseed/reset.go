@@ -46,6 +46,7 @@ func resetPreseededChroot(preseedChroot string) error {
 	// globs that yield individual files
 	globs := []string{
 		dirs.SnapStateFile,
+		dirs.SnapStateFile + ".*",
 		dirs.SnapSystemKeyFile,
 		filepath.Join(dirs.SnapBlobDir, "*.snap"),
 		filepath.Join(dirs.SnapUdevRulesDir, "*-snap.*.rules"),
@@ -20,13 +20,22 @@
 package main
 
 import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/jessevdk/go-flags"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
 )
 
 type cmdConnect struct {
 	waitMixin
+	DryRun      bool   `long:"dry-run"`
+	Interactive bool   `long:"interactive" short:"i"`
+	Format      string `long:"format" default:"pretty" choice:"pretty" choice:"json" choice:"yaml"`
 	Positionals struct {
 		PlugSpec connectPlugSpec `required:"yes"`
 		SlotSpec connectSlotSpec
@@ -52,12 +61,35 @@ $ snap connect <snap>:<plug>
 
 Connects the provided plug to the slot in the core snap with a name matching
 the plug name.
+
+With --dry-run the connection is not made. Instead, the security policy
+(such as AppArmor, seccomp and udev rules) it would add to the snaps
+involved is shown.
+
+$ snap connect --interactive <snap>
+
+Lists the disconnected plugs of the provided snap one at a time, along with
+the slots each of them can be connected to, and lets a slot be picked with
+the arrow keys. The picked connections are made once all plugs were
+visited.
+
+Besides the usual exit codes, the command exits with 31 when the plug or
+slot does not exist and 32 when the connection is not allowed by policy.
+With --format the error is also reported in the output, under "error",
+which is also used to tell that the plug was already connected to the slot.
 `)
 
 func init() {
 	addCommand("connect", shortConnectHelp, longConnectHelp, func() flags.Commander {
 		return &cmdConnect{}
-	}, waitDescs, []argDesc{
+	}, waitDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"dry-run": i18n.G("Show the security policy the connection would add, without connecting"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"interactive": i18n.G("Pick the slots to connect the plugs of a snap to"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"format": i18n.G("Use the given output format"),
+	}), []argDesc{
 		// TRANSLATORS: This needs to begin with < and end with >
 		{name: i18n.G("<snap>:<plug>")},
 		// TRANSLATORS: This needs to begin with < and end with >
@@ -70,6 +102,14 @@ func (x *cmdConnect) Execute(args []string) error {
 		return ErrExtraArgs
 	}
 
+	return reportInterfacesError(x.Format, x.connect())
+}
+
+func (x *cmdConnect) connect() error {
+	if x.Interactive {
+		return x.connectInteractively()
+	}
+
 	// snap connect <plug> <snap>[:<slot>]
 	if x.Positionals.PlugSpec.Snap != "" && x.Positionals.PlugSpec.Name == "" {
 		// Move the value of .Snap to .Name and keep .Snap empty
@@ -77,17 +117,201 @@ func (x *cmdConnect) Execute(args []string) error {
 		x.Positionals.PlugSpec.Snap = ""
 	}
 
+	if x.DryRun {
+		return x.preview()
+	}
+
 	id, err := x.client.Connect(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name)
 	if err != nil {
 		return err
 	}
 
-	if _, err := x.wait(id); err != nil {
+	chg, err := x.wait(id)
+	if err != nil {
 		if err == noWait {
 			return nil
 		}
 		return err
 	}
 
+	conns, err := changeConnections(chg, "connected")
+	if err != nil {
+		return err
+	}
+	already, err := changeConnections(chg, "already-connected")
+	if err != nil {
+		return err
+	}
+	if len(conns) == 0 && len(already) > 0 {
+		conn := already[0]
+		return &client.Error{
+			Kind: client.ErrorKindInterfacesUnchanged,
+			// TRANSLATORS: the first %s is the plug, the second %s is the slot
+			Message: fmt.Sprintf(i18n.G("%s is already connected to %s"), endpoint(conn.Plug.Snap, conn.Plug.Name), endpoint(conn.Slot.Snap, conn.Slot.Name)),
+		}
+	}
+	if x.Format != "pretty" {
+		return writeFormatted(x.Format, conns)
+	}
+	for _, conn := range conns {
+		// TRANSLATORS: the first %s is the plug, the second %s is the slot
+		fmt.Fprintf(Stdout, i18n.G("%s connected to %s\n"), endpoint(conn.Plug.Snap, conn.Plug.Name), endpoint(conn.Slot.Snap, conn.Slot.Name))
+	}
+
+	return nil
+}
+
+// connectionPreviewOutput is the machine readable form of the security
+// policy a connection would add.
+type connectionPreviewOutput struct {
+	Plug     *endpointRef                   `json:"plug" yaml:"plug"`
+	Slot     *endpointRef                   `json:"slot" yaml:"slot"`
+	Snippets map[string]map[string][]string `json:"snippets,omitempty" yaml:"snippets,omitempty"`
+}
+
+func (x *cmdConnect) preview() error {
+	previews, err := x.client.PreviewConnect(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name)
+	if err != nil {
+		return err
+	}
+
+	if x.Format != "pretty" {
+		out := make([]connectionPreviewOutput, 0, len(previews))
+		for _, preview := range previews {
+			out = append(out, connectionPreviewOutput{
+				Plug:     &endpointRef{Snap: preview.Plug.Snap, Name: preview.Plug.Name},
+				Slot:     &endpointRef{Snap: preview.Slot.Snap, Name: preview.Slot.Name},
+				Snippets: preview.Snippets,
+			})
+		}
+		return writeFormatted(x.Format, out)
+	}
+
+	for _, preview := range previews {
+		plug := endpoint(preview.Plug.Snap, preview.Plug.Name)
+		slot := endpoint(preview.Slot.Snap, preview.Slot.Name)
+		if len(preview.Snippets) == 0 {
+			// TRANSLATORS: the first %s is the plug, the second %s is the slot
+			fmt.Fprintf(Stdout, i18n.G("Connecting %s to %s would not change any security policy\n"), plug, slot)
+			continue
+		}
+		// TRANSLATORS: the first %s is the plug, the second %s is the slot
+		fmt.Fprintf(Stdout, i18n.G("Connecting %s to %s would add:\n"), plug, slot)
+		systems := make([]string, 0, len(preview.Snippets))
+		for system := range preview.Snippets {
+			systems = append(systems, system)
+		}
+		sort.Strings(systems)
+		for _, system := range systems {
+			snippets := preview.Snippets[system]
+			tags := make([]string, 0, len(snippets))
+			for tag := range snippets {
+				tags = append(tags, tag)
+			}
+			sort.Strings(tags)
+			for _, tag := range tags {
+				fmt.Fprintf(Stdout, "\n")
+				if tag == "" {
+					// TRANSLATORS: %s is the name of a security system, e.g. udev
+					fmt.Fprintf(Stdout, i18n.G("%s:\n"), system)
+				} else {
+					// TRANSLATORS: the first %s is the name of a security system, e.g. apparmor, the second %s is a security tag
+					fmt.Fprintf(Stdout, i18n.G("%s for %s:\n"), system, tag)
+				}
+				for _, snippet := range snippets[tag] {
+					for _, line := range strings.Split(snippet, "\n") {
+						fmt.Fprintf(Stdout, "  %s\n", line)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type pickedConnection struct {
+	plug *client.Plug
+	slot *client.Slot
+}
+
+func (x *cmdConnect) connectInteractively() error {
+	snapName := x.Positionals.PlugSpec.Snap
+	if x.Positionals.PlugSpec.Name != "" || x.Positionals.SlotSpec.Snap != "" || x.Positionals.SlotSpec.Name != "" {
+		return fmt.Errorf(i18n.G("cannot use --interactive with a plug or slot, pass only a snap name"))
+	}
+	if x.DryRun {
+		return fmt.Errorf(i18n.G("cannot use --interactive with --dry-run"))
+	}
+	if x.Format != "pretty" {
+		return fmt.Errorf(i18n.G("cannot use --interactive with --format"))
+	}
+	if !isStdinTTY {
+		return fmt.Errorf(i18n.G("cannot pick connections interactively without a terminal"))
+	}
+
+	plugs, err := findPlugCandidates(x.client, snapName)
+	if err != nil {
+		return err
+	}
+	if len(plugs) == 0 {
+		fmt.Fprintf(Stdout, i18n.G("No disconnected plugs in snap %q\n"), snapName)
+		return nil
+	}
+	picked, err := pickConnections(plugs)
+	if err != nil {
+		return err
+	}
+
+	for _, conn := range picked {
+		id, err := x.client.Connect(conn.plug.Snap, conn.plug.Name, conn.slot.Snap, conn.slot.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := x.wait(id); err != nil {
+			if err == noWait {
+				continue
+			}
+			return err
+		}
+		// TRANSLATORS: the first %s is the plug, the second %s is the slot
+		fmt.Fprintf(Stdout, i18n.G("%s connected to %s\n"), endpoint(conn.plug.Snap, conn.plug.Name), endpoint(conn.slot.Snap, conn.slot.Name))
+	}
 	return nil
 }
+
+// pickConnections lets the user pick a slot for each of the given plugs, or
+// leave it disconnected.
+func pickConnections(plugs []plugCandidates) ([]pickedConnection, error) {
+	restore, err := termMakeRaw()
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+
+	r := bufio.NewReader(Stdin)
+	var picked []pickedConnection
+	for i := range plugs {
+		pc := &plugs[i]
+		plugName := endpoint(pc.plug.Snap, pc.plug.Name)
+		if len(pc.candidates) == 0 {
+			// TRANSLATORS: %s is a plug; the line ends with \r\n as the terminal is in raw mode
+			fmt.Fprintf(Stdout, i18n.G("No candidate slots for %s\r\n"), plugName)
+			continue
+		}
+		items := make([]string, 0, len(pc.candidates)+1)
+		for _, slot := range pc.candidates {
+			items = append(items, endpoint(slot.Snap, slot.Name))
+		}
+		items = append(items, i18n.G("(leave disconnected)"))
+		// TRANSLATORS: the first %s is a plug, the second %s is its interface
+		title := fmt.Sprintf(i18n.G("Connect %s (%s) to:"), plugName, pc.plug.Interface)
+		choice, err := pickOne(r, Stdout, title, items)
+		if err != nil {
+			return nil, err
+		}
+		if choice < len(pc.candidates) {
+			picked = append(picked, pickedConnection{plug: &pc.plug, slot: pc.candidates[choice].Slot})
+		}
+	}
+	return picked, nil
+}
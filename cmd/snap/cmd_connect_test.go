@@ -23,12 +23,14 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/client"
 	. "github.com/snapcore/snapd/cmd/snap"
+	"github.com/snapcore/snapd/testutil"
 )
 
 func (s *SnapSuite) TestConnectHelp(c *C) {
@@ -53,9 +55,31 @@ $ snap connect <snap>:<plug>
 Connects the provided plug to the slot in the core snap with a name matching
 the plug name.
 
+With --dry-run the connection is not made. Instead, the security policy
+(such as AppArmor, seccomp and udev rules) it would add to the snaps
+involved is shown.
+
+$ snap connect --interactive <snap>
+
+Lists the disconnected plugs of the provided snap one at a time, along with
+the slots each of them can be connected to, and lets a slot be picked with
+the arrow keys. The picked connections are made once all plugs were
+visited.
+
+Besides the usual exit codes, the command exits with 31 when the plug or
+slot does not exist and 32 when the connection is not allowed by policy.
+With --format the error is also reported in the output, under "error",
+which is also used to tell that the plug was already connected to the slot.
+
 [connect command options]
-      --no-wait          Do not wait for the operation to finish but just print
-                         the change id.
+          --no-wait                   Do not wait for the operation to finish
+                                      but just print the change id.
+          --dry-run                   Show the security policy the connection
+                                      would add, without connecting
+      -i, --interactive               Pick the slots to connect the plugs of a
+                                      snap to
+          --format=[pretty|json|yaml] Use the given output format (default:
+                                      pretty)
 `
 	s.testSubCommandHelp(c, "connect", msg)
 }
@@ -94,6 +118,155 @@ func (s *SnapSuite) TestConnectExplicitEverything(c *C) {
 	c.Assert(rest, DeepEquals, []string{})
 }
 
+func (s *SnapSuite) TestConnectPrintsConnection(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done", "data": {"connected": [
+				{"plug": {"snap": "producer", "plug": "plug"}, "slot": {"snap": "consumer", "slot": "slot"}}
+			]}}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connect", "producer:plug", "consumer"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, "producer:plug connected to consumer:slot\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectFormatJSON(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done", "data": {"connected": [
+				{"plug": {"snap": "producer", "plug": "plug"}, "slot": {"snap": "consumer", "slot": "slot"}}
+			]}}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--format=json", "producer:plug", "consumer"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `[
+  {
+    "plug": {
+      "snap": "producer",
+      "name": "plug"
+    },
+    "slot": {
+      "snap": "consumer",
+      "name": "slot"
+    }
+  }
+]
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectAlreadyConnected(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done", "data": {"connected": [], "already-connected": [
+				{"plug": {"snap": "producer", "plug": "plug"}, "slot": {"snap": "core", "slot": "plug"}}
+			]}}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "producer:plug"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "producer:plug is already connected to :plug\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectFormatJSONAlreadyConnected(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done", "data": {"connected": [], "already-connected": [
+				{"plug": {"snap": "producer", "plug": "plug"}, "slot": {"snap": "core", "slot": "plug"}}
+			]}}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--format=json", "producer:plug"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `{
+  "error": {
+    "kind": "interfaces-unchanged",
+    "message": "producer:plug is already connected to :plug",
+    "exit-code": 0
+  }
+}
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectErrorExitCodes(c *C) {
+	for _, t := range []struct {
+		status   int
+		kind     string
+		exitCode int
+	}{
+		{404, "interfaces-plug-or-slot-not-found", 31},
+		{403, "interfaces-connection-not-allowed", 32},
+		{400, "snap-change-conflict", 10},
+		{500, "", 1},
+	} {
+		s.ResetStdStreams()
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			c.Check(r.URL.Path, Equals, "/v2/interfaces")
+			w.WriteHeader(t.status)
+			fmt.Fprintf(w, `{"type":"error", "status-code": %d, "result": {"message": "boom", "kind": %q}}`, t.status, t.kind)
+		})
+		_, err := Parser(Client()).ParseArgs([]string{"connect", "producer:plug", "consumer:slot"})
+		c.Assert(err, ErrorMatches, "boom")
+		c.Check(ExitCodeFromError(err), Equals, t.exitCode, Commentf("%s", t.kind))
+		c.Check(s.Stdout(), Equals, "")
+	}
+}
+
+func (s *SnapSuite) TestConnectFormatJSONError(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/interfaces")
+		w.WriteHeader(403)
+		fmt.Fprintln(w, `{"type":"error", "status-code": 403, "result": {"message": "cannot connect producer:plug consumer:slot: connection not allowed by slot rule of interface \"test\"", "kind": "interfaces-connection-not-allowed"}}`)
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--format=json", "producer:plug", "consumer:slot"})
+	c.Assert(err, ErrorMatches, `cannot connect producer:plug consumer:slot: connection not allowed by slot rule of interface "test"`)
+	c.Check(s.Stdout(), Equals, `{
+  "error": {
+    "kind": "interfaces-connection-not-allowed",
+    "message": "cannot connect producer:plug consumer:slot: connection not allowed by slot rule of interface \"test\"",
+    "exit-code": 32
+  }
+}
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
 func (s *SnapSuite) TestConnectExplicitPlugImplicitSlot(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -327,3 +500,198 @@ func (s *SnapSuite) TestConnectCompletion(c *C) {
 	c.Assert(s.Stdout(), Equals, "")
 	c.Assert(s.Stderr(), Equals, "")
 }
+
+func (s *SnapSuite) TestConnectDryRun(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/interfaces")
+		c.Check(r.Method, Equals, "POST")
+		c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+			"action":  "connect",
+			"dry-run": true,
+			"plugs": []interface{}{
+				map[string]interface{}{
+					"snap": "consumer",
+					"plug": "plug",
+				},
+			},
+			"slots": []interface{}{
+				map[string]interface{}{
+					"snap": "producer",
+					"slot": "slot",
+				},
+			},
+		})
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type": "sync",
+			"result": []map[string]interface{}{{
+				"plug": map[string]interface{}{"snap": "consumer", "plug": "plug"},
+				"slot": map[string]interface{}{"snap": "producer", "slot": "slot"},
+				"snippets": map[string]interface{}{
+					"apparmor": map[string]interface{}{
+						"snap.consumer.app": []string{"# Description: foo\n/dev/foo rw,"},
+					},
+					"udev": map[string]interface{}{
+						"":                  []string{`SUBSYSTEM=="foo"`},
+						"snap_consumer_app": []string{`KERNEL=="foo", TAG+="snap_consumer_app"`},
+					},
+				},
+			}},
+		})
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connect", "--dry-run", "consumer:plug", "producer:slot"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `Connecting consumer:plug to producer:slot would add:
+
+apparmor for snap.consumer.app:
+  # Description: foo
+  /dev/foo rw,
+
+udev:
+  SUBSYSTEM=="foo"
+
+udev for snap_consumer_app:
+  KERNEL=="foo", TAG+="snap_consumer_app"
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectDryRunNothingAdded(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/interfaces")
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type": "sync",
+			"result": []map[string]interface{}{{
+				"plug": map[string]interface{}{"snap": "consumer", "plug": "plug"},
+				"slot": map[string]interface{}{"snap": "core", "slot": "network"},
+			}},
+		})
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--dry-run", "consumer:plug", ":network"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "Connecting consumer:plug to :network would not change any security policy\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectDryRunFormatJSON(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/interfaces")
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type": "sync",
+			"result": []map[string]interface{}{{
+				"plug": map[string]interface{}{"snap": "consumer", "plug": "plug"},
+				"slot": map[string]interface{}{"snap": "producer", "slot": "slot"},
+				"snippets": map[string]interface{}{
+					"seccomp": map[string]interface{}{
+						"snap.consumer.app": []string{"listen"},
+					},
+				},
+			}},
+		})
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--dry-run", "--format=json", "consumer:plug", "producer:slot"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `[
+  {
+    "plug": {
+      "snap": "consumer",
+      "name": "plug"
+    },
+    "slot": {
+      "snap": "producer",
+      "name": "slot"
+    },
+    "snippets": {
+      "seccomp": {
+        "snap.consumer.app": [
+          "listen"
+        ]
+      }
+    }
+  }
+]
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectInteractive(c *C) {
+	var connects []string
+	s.mockTryConnectServer(c, &connects)
+	s.AddCleanup(MockIsStdinTTY(true))
+	rawCalls, restoreCalls := 0, 0
+	s.AddCleanup(MockTermMakeRaw(func() (func(), error) {
+		rawCalls++
+		return func() { restoreCalls++ }, nil
+	}))
+
+	// audio: leave disconnected, content: second slot, network: first
+	// slot after moving around, serial: second slot
+	s.stdin.WriteString("j\r" + "\x1b[B\r" + "jx\x1b[A\x1b[A\r" + "\x1b[B\r")
+
+	rest, err := Parser(Client()).ParseArgs([]string{"connect", "-i", "consumer"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(rawCalls, Equals, 1)
+	c.Check(restoreCalls, Equals, 1)
+	c.Check(connects, DeepEquals, []string{
+		"consumer:content provider-b:content",
+		"consumer:network core:network",
+		"consumer:serial modem:serial",
+	})
+
+	out := s.Stdout()
+	c.Check(out, testutil.Contains, ""+
+		"Connect consumer:audio (audio-playback) to:\r\n"+
+		"\r\x1b[K> pulse:audio\r\n"+
+		"\r\x1b[K  (leave disconnected)\r\n"+
+		"\x1b[2A"+
+		"\r\x1b[K  pulse:audio\r\n"+
+		"\r\x1b[K> (leave disconnected)\r\n"+
+		"No candidate slots for consumer:camera\r\n"+
+		"Connect consumer:content (content) to:\r\n")
+	c.Check(out, testutil.Contains, "Connect consumer:network (network) to:\r\n"+
+		"\r\x1b[K> :network\r\n"+
+		"\r\x1b[K  router:network\r\n"+
+		"\r\x1b[K  (leave disconnected)\r\n")
+	c.Check(strings.HasSuffix(out, ""+
+		"consumer:content connected to provider-b:content\n"+
+		"consumer:network connected to :network\n"+
+		"consumer:serial connected to modem:serial\n"), Equals, true, Commentf("%q", out))
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectInteractiveAbort(c *C) {
+	var connects []string
+	s.mockTryConnectServer(c, &connects)
+	s.AddCleanup(MockIsStdinTTY(true))
+	restoreCalls := 0
+	s.AddCleanup(MockTermMakeRaw(func() (func(), error) {
+		return func() { restoreCalls++ }, nil
+	}))
+
+	s.stdin.WriteString("\x1b[Bq")
+
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--interactive", "consumer"})
+	c.Assert(err, ErrorMatches, "aborted")
+	c.Check(restoreCalls, Equals, 1)
+	c.Check(connects, HasLen, 0)
+}
+
+func (s *SnapSuite) TestConnectInteractiveErrors(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("expected nothing to reach the server")
+	})
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"connect", "-i", "consumer:plug"}, `cannot use --interactive with a plug or slot, pass only a snap name`},
+		{[]string{"connect", "-i", "consumer", "producer:slot"}, `cannot use --interactive with a plug or slot, pass only a snap name`},
+		{[]string{"connect", "-i", "--dry-run", "consumer"}, `cannot use --interactive with --dry-run`},
+		{[]string{"connect", "-i", "--format=json", "consumer"}, `cannot use --interactive with --format`},
+		{[]string{"connect", "-i", "consumer"}, `cannot pick connections interactively without a terminal`},
+	} {
+		_, err := Parser(Client()).ParseArgs(t.args)
+		c.Check(err, ErrorMatches, t.err, Commentf("%v", t.args))
+	}
+}
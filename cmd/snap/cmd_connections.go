@@ -20,6 +20,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -32,7 +33,12 @@ import (
 
 type cmdConnections struct {
 	clientMixin
-	All         bool `long:"all"`
+	All         bool          `long:"all"`
+	Interface   interfaceName `long:"interface"`
+	Format      string        `long:"format" default:"pretty" choice:"pretty" choice:"json" choice:"yaml"`
+	Graph       string        `long:"graph" optional:"true" optional-value:"tree" choice:"tree" choice:"dot"`
+	Watch       bool          `long:"watch"`
+	Summary     bool          `long:"summary"`
 	Positionals struct {
 		Snap installedSnapName
 	} `positional-args:"true"`
@@ -51,6 +57,28 @@ $ snap connections <snap>
 
 Lists connected and unconnected plugs and slots for the specified
 snap.
+
+$ snap connections --interface=<interface>
+
+Constrains the listing to plugs, slots and connections of the given
+interface. It can be combined with the other forms.
+
+$ snap connections --graph[=tree|dot]
+
+Renders the established connections as a graph of snaps wired together,
+either as an indented tree (the default) or in the DOT language used by
+graphviz.
+
+$ snap connections --watch
+
+Keeps running after the listing is shown, and refreshes it whenever plugs,
+slots or connections change, such as when a hotplug device is attached.
+
+$ snap connections --summary <snap>
+
+Summarises what the snap can use through its plugs and what it offers to
+other snaps through its slots, along with the connections that could be
+made for its disconnected plugs.
 `)
 
 func init() {
@@ -58,6 +86,16 @@ func init() {
 		return &cmdConnections{}
 	}, map[string]string{
 		"all": i18n.G("Show connected and unconnected plugs and slots"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"interface": i18n.G("Constrain listing to a specific interface"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"format": i18n.G("Use the given output format"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"graph": i18n.G("Render connections as a tree or in the DOT language"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"watch": i18n.G("Refresh the listing as plugs, slots and connections change"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"summary": i18n.G("Summarise the plugs and slots of a snap"),
 	}, []argDesc{{
 		// TRANSLATORS: This needs to be wrapped in <>s.
 		name: "<snap>",
@@ -80,6 +118,8 @@ func endpoint(snap, name string) string {
 type connection struct {
 	slot                 string
 	plug                 string
+	slotRef              *endpointRef
+	plugRef              *endpointRef
 	interfaceName        string
 	interfaceDeterminant string
 	manual               bool
@@ -135,9 +175,26 @@ func (x *cmdConnections) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
 	}
+	if x.Graph != "" && x.Format != "pretty" {
+		return fmt.Errorf(i18n.G("cannot use --graph with --format"))
+	}
+	if x.Watch && x.Format != "pretty" {
+		return fmt.Errorf(i18n.G("cannot use --watch with --format"))
+	}
+	if x.Summary {
+		switch {
+		case x.Positionals.Snap == "":
+			return fmt.Errorf(i18n.G("cannot use --summary without a snap name"))
+		case x.Format != "pretty":
+			return fmt.Errorf(i18n.G("cannot use --summary with --format"))
+		case x.Graph != "" || x.Watch || x.Interface != "":
+			return fmt.Errorf(i18n.G("cannot use --summary with --graph, --watch or --interface"))
+		}
+	}
 
 	opts := client.ConnectionOptions{
-		All: x.All,
+		All:       x.All,
+		Interface: string(x.Interface),
 	}
 	wanted := string(x.Positionals.Snap)
 	if wanted != "" {
@@ -154,19 +211,121 @@ func (x *cmdConnections) Execute(args []string) error {
 		x.All = true
 	}
 
-	connections, err := x.client.Connections(&opts)
+	if x.Watch {
+		return x.watch(&opts)
+	}
+	if x.Summary {
+		return x.summary(&opts)
+	}
+	return x.show(&opts)
+}
+
+// summary prints, for a single snap, its plugs along with the slots they
+// are connected to, its slots along with the plugs connected to them and the
+// connections that could be made for its disconnected plugs.
+func (x *cmdConnections) summary(opts *client.ConnectionOptions) error {
+	snapName := opts.Snap
+	connections, err := x.client.Connections(opts)
+	if err != nil {
+		return err
+	}
+	plugs, err := findPlugCandidates(x.client, snapName)
+	if err != nil {
+		return err
+	}
+
+	var snapPlugs []client.Plug
+	for _, plug := range connections.Plugs {
+		if plug.Snap == snapName {
+			snapPlugs = append(snapPlugs, plug)
+		}
+	}
+	var snapSlots []client.Slot
+	for _, slot := range connections.Slots {
+		if slot.Snap == snapName {
+			snapSlots = append(snapSlots, slot)
+		}
+	}
+	sort.Slice(snapPlugs, func(i, j int) bool { return snapPlugs[i].Name < snapPlugs[j].Name })
+	sort.Slice(snapSlots, func(i, j int) bool { return snapSlots[i].Name < snapSlots[j].Name })
+
+	w := tabWriter()
+	defer w.Flush()
+
+	// TRANSLATORS: %q is a snap name
+	fmt.Fprintf(w, i18n.G("Snap %q uses:\n"), snapName)
+	if len(snapPlugs) == 0 {
+		fmt.Fprintf(w, "  %s\n", i18n.G("nothing"))
+	}
+	for _, plug := range snapPlugs {
+		provided := i18n.G("not connected")
+		if len(plug.Connections) > 0 {
+			slots := make([]string, 0, len(plug.Connections))
+			for _, slot := range plug.Connections {
+				slots = append(slots, endpoint(slot.Snap, slot.Name))
+			}
+			// TRANSLATORS: %s is a list of slots
+			provided = fmt.Sprintf(i18n.G("provided by %s"), strings.Join(slots, ", "))
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", plug.Name, plug.Interface, provided)
+	}
+
+	// TRANSLATORS: %q is a snap name
+	fmt.Fprintf(w, i18n.G("Snap %q offers:\n"), snapName)
+	if len(snapSlots) == 0 {
+		fmt.Fprintf(w, "  %s\n", i18n.G("nothing"))
+	}
+	for _, slot := range snapSlots {
+		used := i18n.G("not used")
+		if len(slot.Connections) > 0 {
+			plugs := make([]string, 0, len(slot.Connections))
+			for _, plug := range slot.Connections {
+				plugs = append(plugs, endpoint(plug.Snap, plug.Name))
+			}
+			// TRANSLATORS: %s is a list of plugs
+			used = fmt.Sprintf(i18n.G("used by %s"), strings.Join(plugs, ", "))
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", slot.Name, slot.Interface, used)
+	}
+
+	var suggestions []string
+	for _, pc := range plugs {
+		if slot, _ := pc.best(); slot != nil {
+			suggestions = append(suggestions, fmt.Sprintf("snap connect %s %s", endpoint(pc.plug.Snap, pc.plug.Name), endpoint(slot.Snap, slot.Name)))
+		}
+	}
+	if len(suggestions) > 0 {
+		fmt.Fprintln(w, i18n.G("Suggested connections:"))
+		for _, suggestion := range suggestions {
+			fmt.Fprintf(w, "  %s\n", suggestion)
+		}
+	}
+	return nil
+}
+
+func (x *cmdConnections) show(opts *client.ConnectionOptions) error {
+	wanted := opts.Snap
+	connections, err := x.client.Connections(opts)
 	if err != nil {
 		return err
 	}
-	if len(connections.Plugs) == 0 && len(connections.Slots) == 0 {
+	if len(connections.Plugs) == 0 && len(connections.Slots) == 0 && x.Format == "pretty" {
 		return nil
 	}
+	switch x.Graph {
+	case "tree":
+		return writeConnectionsTree(connections.Established)
+	case "dot":
+		return writeConnectionsDot(connections.Established)
+	}
 
 	annotatedConns := make([]connection, 0, len(connections.Established)+len(connections.Undesired))
 	for _, conn := range connections.Established {
 		annotatedConns = append(annotatedConns, connection{
 			plug:                 endpoint(conn.Plug.Snap, conn.Plug.Name),
 			slot:                 endpoint(conn.Slot.Snap, conn.Slot.Name),
+			plugRef:              &endpointRef{Snap: conn.Plug.Snap, Name: conn.Plug.Name},
+			slotRef:              &endpointRef{Snap: conn.Slot.Snap, Name: conn.Slot.Name},
 			manual:               conn.Manual,
 			gadget:               conn.Gadget,
 			interfaceName:        conn.Interface,
@@ -174,13 +333,11 @@ func (x *cmdConnections) Execute(args []string) error {
 		})
 	}
 
-	w := tabWriter()
-	fmt.Fprintln(w, i18n.G("Interface\tPlug\tSlot\tNotes"))
-
 	for _, plug := range connections.Plugs {
 		if len(plug.Connections) == 0 && x.All {
 			annotatedConns = append(annotatedConns, connection{
 				plug:          endpoint(plug.Snap, plug.Name),
+				plugRef:       &endpointRef{Snap: plug.Snap, Name: plug.Name},
 				slot:          "-",
 				interfaceName: plug.Interface,
 			})
@@ -196,6 +353,7 @@ func (x *cmdConnections) Execute(args []string) error {
 			annotatedConns = append(annotatedConns, connection{
 				plug:          "-",
 				slot:          endpoint(slot.Snap, slot.Name),
+				slotRef:       &endpointRef{Snap: slot.Snap, Name: slot.Name},
 				interfaceName: slot.Interface,
 			})
 		}
@@ -203,6 +361,23 @@ func (x *cmdConnections) Execute(args []string) error {
 
 	sort.Sort(byConnectionData(annotatedConns))
 
+	if x.Format != "pretty" {
+		out := make([]connectionOutput, 0, len(annotatedConns))
+		for _, conn := range annotatedConns {
+			out = append(out, connectionOutput{
+				Interface: conn.interfaceName,
+				Plug:      conn.plugRef,
+				Slot:      conn.slotRef,
+				Manual:    conn.manual,
+				Gadget:    conn.gadget,
+			})
+		}
+		return writeFormatted(x.Format, out)
+	}
+
+	w := tabWriter()
+	fmt.Fprintln(w, i18n.G("Interface\tPlug\tSlot\tNotes"))
+
 	for _, note := range annotatedConns {
 		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\n", note.interfaceName, note.interfaceDeterminant, note.plug, note.slot, note)
 	}
@@ -212,3 +387,138 @@ func (x *cmdConnections) Execute(args []string) error {
 	}
 	return nil
 }
+
+// watch shows the listing and refreshes it whenever the interfaces
+// repository of snapd changes, until the event stream ends.
+func (x *cmdConnections) watch(opts *client.ConnectionOptions) error {
+	// subscribe before showing the listing, so that no change is missed
+	events, err := x.client.InterfaceEvents(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := x.show(opts); err != nil {
+		return err
+	}
+	for ev := range events {
+		if ev.Error != "" {
+			return fmt.Errorf(i18n.G("cannot watch connections: %s"), ev.Error)
+		}
+		if !interfaceEventConcerns(&ev, opts.Snap) {
+			continue
+		}
+		if isStdoutTTY {
+			// clear the screen and start over from the top
+			fmt.Fprint(Stdout, "\033[H\033[2J")
+		} else {
+			fmt.Fprintln(Stdout)
+		}
+		fmt.Fprintln(Stdout, describeInterfaceEvent(&ev))
+		if err := x.show(opts); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf(i18n.G("cannot watch connections: event stream ended"))
+}
+
+// interfaceEventConcerns tells whether the event is relevant to the listing
+// of the given snap, or of all snaps when the snap name is empty.
+func interfaceEventConcerns(ev *client.InterfaceEvent, snapName string) bool {
+	if snapName == "" || ev.Snap == snapName {
+		return true
+	}
+	return (ev.Plug != nil && ev.Plug.Snap == snapName) || (ev.Slot != nil && ev.Slot.Snap == snapName)
+}
+
+func describeInterfaceEvent(ev *client.InterfaceEvent) string {
+	switch ev.Kind {
+	case "connected":
+		// TRANSLATORS: the first %s is a plug, the second %s is a slot
+		return fmt.Sprintf(i18n.G("%s connected to %s"), endpoint(ev.Plug.Snap, ev.Plug.Name), endpoint(ev.Slot.Snap, ev.Slot.Name))
+	case "disconnected":
+		// TRANSLATORS: the first %s is a plug, the second %s is a slot
+		return fmt.Sprintf(i18n.G("%s disconnected from %s"), endpoint(ev.Plug.Snap, ev.Plug.Name), endpoint(ev.Slot.Snap, ev.Slot.Name))
+	case "plug-added":
+		// TRANSLATORS: %s is a plug
+		return fmt.Sprintf(i18n.G("plug %s added"), endpoint(ev.Snap, ev.Name))
+	case "plug-removed":
+		// TRANSLATORS: %s is a plug
+		return fmt.Sprintf(i18n.G("plug %s removed"), endpoint(ev.Snap, ev.Name))
+	case "slot-added":
+		// TRANSLATORS: %s is a slot
+		return fmt.Sprintf(i18n.G("slot %s added"), endpoint(ev.Snap, ev.Name))
+	case "slot-removed":
+		// TRANSLATORS: %s is a slot
+		return fmt.Sprintf(i18n.G("slot %s removed"), endpoint(ev.Snap, ev.Name))
+	case "snap-added":
+		// TRANSLATORS: %q is a snap name
+		return fmt.Sprintf(i18n.G("plugs and slots of snap %q added"), ev.Snap)
+	case "snap-removed":
+		// TRANSLATORS: %q is a snap name
+		return fmt.Sprintf(i18n.G("plugs and slots of snap %q removed"), ev.Snap)
+	}
+	return fmt.Sprintf("%s: %s", ev.Kind, ev.Snap)
+}
+
+// writeConnectionsTree prints, for every snap with connected plugs, the
+// slots its plugs are connected to.
+func writeConnectionsTree(conns []client.Connection) error {
+	byPlugSnap := make(map[string][]client.Connection)
+	for _, conn := range conns {
+		byPlugSnap[conn.Plug.Snap] = append(byPlugSnap[conn.Plug.Snap], conn)
+	}
+	snaps := make([]string, 0, len(byPlugSnap))
+	for snap := range byPlugSnap {
+		snaps = append(snaps, snap)
+	}
+	sort.Strings(snaps)
+
+	for _, snap := range snaps {
+		fmt.Fprintf(Stdout, "%s\n", snap)
+		snapConns := byPlugSnap[snap]
+		sort.Slice(snapConns, func(i, j int) bool {
+			if snapConns[i].Plug.Name != snapConns[j].Plug.Name {
+				return snapConns[i].Plug.Name < snapConns[j].Plug.Name
+			}
+			return endpoint(snapConns[i].Slot.Snap, snapConns[i].Slot.Name) < endpoint(snapConns[j].Slot.Snap, snapConns[j].Slot.Name)
+		})
+		for _, conn := range snapConns {
+			fmt.Fprintf(Stdout, "  %s -> %s (%s)\n", conn.Plug.Name, endpoint(conn.Slot.Snap, conn.Slot.Name), conn.Interface)
+		}
+	}
+	return nil
+}
+
+// writeConnectionsDot prints the connections as a directed graph in the
+// DOT language, with an edge from the plug snap to the slot snap for
+// every interface connecting them.
+func writeConnectionsDot(conns []client.Connection) error {
+	type edge struct {
+		plugSnap, slotSnap, iface string
+	}
+	seen := make(map[edge]bool)
+	edges := make([]edge, 0, len(conns))
+	for _, conn := range conns {
+		e := edge{plugSnap: conn.Plug.Snap, slotSnap: conn.Slot.Snap, iface: conn.Interface}
+		if seen[e] {
+			continue
+		}
+		seen[e] = true
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].plugSnap != edges[j].plugSnap {
+			return edges[i].plugSnap < edges[j].plugSnap
+		}
+		if edges[i].slotSnap != edges[j].slotSnap {
+			return edges[i].slotSnap < edges[j].slotSnap
+		}
+		return edges[i].iface < edges[j].iface
+	})
+
+	fmt.Fprintf(Stdout, "digraph connections {\n")
+	for _, e := range edges {
+		fmt.Fprintf(Stdout, "  %q -> %q [label=%q];\n", e.plugSnap, e.slotSnap, e.iface)
+	}
+	fmt.Fprintf(Stdout, "}\n")
+	return nil
+}
@@ -0,0 +1,119 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+var shortConnectionsHistoryHelp = i18n.G("List the recorded sets of connections")
+var longConnectionsHistoryHelp = i18n.G(`
+The connections-history command lists the sets of connections of the
+system as they were before the changes that modified them, oldest first.
+`)
+
+var shortRevertConnectionsHelp = i18n.G("Revert the connections of the system")
+var longRevertConnectionsHelp = i18n.G(`
+The revert-connections command restores the connections of the system to
+what they were before the last change that modified them, or with --at to
+what they were at the given time, in RFC 3339 format.
+
+Connections whose plug or slot does not exist anymore are not restored.
+`)
+
+type cmdConnectionsHistory struct {
+	clientMixin
+	timeMixin
+}
+
+type cmdRevertConnections struct {
+	waitMixin
+	At string `long:"at"`
+}
+
+func init() {
+	addCommand("connections-history", shortConnectionsHistoryHelp, longConnectionsHistoryHelp, func() flags.Commander {
+		return &cmdConnectionsHistory{}
+	}, timeDescs, nil)
+	addCommand("revert-connections", shortRevertConnectionsHelp, longRevertConnectionsHelp, func() flags.Commander {
+		return &cmdRevertConnections{}
+	}, waitDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"at": i18n.G("Restore the connections as they were at the given time"),
+	}), nil)
+}
+
+func (x *cmdConnectionsHistory) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	history, err := x.client.ConnectionsHistory()
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		fmt.Fprintln(Stderr, i18n.G("No connections history."))
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+
+	fmt.Fprintln(w, i18n.G("Time\tChange\tConnections"))
+	for _, snapshot := range history {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", x.fmtTime(snapshot.Time), snapshot.ChangeID, len(snapshot.Connections))
+	}
+	return nil
+}
+
+func (x *cmdRevertConnections) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	var at time.Time
+	if x.At != "" {
+		var err error
+		at, err = time.Parse(time.RFC3339, x.At)
+		if err != nil {
+			return fmt.Errorf(i18n.G("cannot parse time %q: expected RFC 3339 format"), x.At)
+		}
+	}
+
+	id, err := x.client.RevertConnections(at)
+	if err != nil {
+		return err
+	}
+	if _, err := x.wait(id); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	fmt.Fprintln(Stdout, i18n.G("Reverted connections"))
+	return nil
+}
@@ -0,0 +1,94 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	. "github.com/snapcore/snapd/cmd/snap"
+)
+
+func (s *SnapSuite) TestConnectionsHistory(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections/history")
+		fmt.Fprintln(w, `{"type":"sync", "result":[
+			{"time": "2021-06-01T10:00:00Z", "change-id": "1", "connections": ["consumer:plug producer:slot"]},
+			{"time": "2021-06-02T10:00:00Z", "change-id": "7", "connections": []}
+		]}`)
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connections-history", "--abs-time"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, ""+
+		"Time                  Change  Connections\n"+
+		"2021-06-01T10:00:00Z  1       1\n"+
+		"2021-06-02T10:00:00Z  7       0\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectionsHistoryEmpty(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type":"sync", "result":[]}`)
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connections-history"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "No connections history.\n")
+}
+
+func (s *SnapSuite) TestRevertConnections(c *C) {
+	for _, t := range []struct {
+		args []string
+		body map[string]interface{}
+	}{
+		{nil, map[string]interface{}{"action": "revert"}},
+		{[]string{"--at", "2021-06-01T10:00:00Z"}, map[string]interface{}{"action": "revert", "time": "2021-06-01T10:00:00Z"}},
+	} {
+		s.ResetStdStreams()
+		s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/connections/history":
+				c.Check(r.Method, Equals, "POST")
+				c.Check(DecodedRequestBody(c, r), DeepEquals, t.body)
+				w.WriteHeader(202)
+				fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+			case "/v2/changes/zzz":
+				c.Check(r.Method, Equals, "GET")
+				fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done"}}`)
+			default:
+				c.Fatalf("unexpected path %q", r.URL.Path)
+			}
+		})
+		rest, err := Parser(Client()).ParseArgs(append([]string{"revert-connections"}, t.args...))
+		c.Assert(err, IsNil)
+		c.Assert(rest, DeepEquals, []string{})
+		c.Check(s.Stdout(), Equals, "Reverted connections\n")
+		c.Check(s.Stderr(), Equals, "")
+	}
+}
+
+func (s *SnapSuite) TestRevertConnectionsBadTime(c *C) {
+	_, err := Parser(Client()).ParseArgs([]string{"revert-connections", "--at", "yesterday"})
+	c.Assert(err, ErrorMatches, `cannot parse time "yesterday": expected RFC 3339 format`)
+}
@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/osutil"
+)
+
+var shortExportConnectionsHelp = i18n.G("Export the connections of the system")
+var longExportConnectionsHelp = i18n.G(`
+The export-connections command writes the connections of the system,
+including automatic connections that were disconnected, to the given file.
+
+The file can be used with import-connections to restore the connections,
+for instance after the device was reflashed.
+`)
+
+var shortImportConnectionsHelp = i18n.G("Import the connections of the system")
+var longImportConnectionsHelp = i18n.G(`
+The import-connections command restores the connections written by
+export-connections to the given file.
+
+The file is not signed, its checksum only detects a corrupted file. Its
+connections are established as manual connections subject to the usual
+policy checks. Connections of snaps that are not installed are established
+once the snaps are installed. By default the imported connections are
+merged with the existing ones, and existing connections take precedence.
+With --replace the connections are made to match the file, and connections
+that are not in the file are disconnected.
+
+With --signed the file holds a connection-profile assertion signed by the
+brand of the device. The connections it lists are established and the
+connections it forbids are disconnected and can no longer be connected,
+other connections are kept.
+`)
+
+type cmdExportConnections struct {
+	clientMixin
+	Positionals struct {
+		Filename string
+	} `positional-args:"true" required:"true"`
+}
+
+type cmdImportConnections struct {
+	waitMixin
+	Replace     bool `long:"replace"`
+	Signed      bool `long:"signed"`
+	Positionals struct {
+		Filename string
+	} `positional-args:"true" required:"true"`
+}
+
+func init() {
+	addCommand("export-connections", shortExportConnectionsHelp, longExportConnectionsHelp, func() flags.Commander {
+		return &cmdExportConnections{}
+	}, nil, []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<filename>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("The file to write the connections to"),
+	}})
+	addCommand("import-connections", shortImportConnectionsHelp, longImportConnectionsHelp, func() flags.Commander {
+		return &cmdImportConnections{}
+	}, waitDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"replace": i18n.G("Disconnect the connections that are not in the file"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"signed": i18n.G("The file holds a connection profile signed by the brand"),
+	}), []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<filename>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("The file to read the connections from"),
+	}})
+}
+
+func (x *cmdExportConnections) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	profile, err := x.client.ExportConnections()
+	if err != nil {
+		return err
+	}
+	if err := osutil.AtomicWriteFile(x.Positionals.Filename, profile, 0600, 0); err != nil {
+		return err
+	}
+
+	// TRANSLATORS: the argument is the file name
+	fmt.Fprintf(Stdout, i18n.G("Exported connections into %q\n"), x.Positionals.Filename)
+	return nil
+}
+
+func (x *cmdImportConnections) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	if x.Signed && x.Replace {
+		return fmt.Errorf(i18n.G("cannot use --replace with a signed connection profile"))
+	}
+
+	profile, err := ioutil.ReadFile(x.Positionals.Filename)
+	if err != nil {
+		return err
+	}
+	var id string
+	if x.Signed {
+		id, err = x.client.ApplyConnectionProfile(profile)
+	} else {
+		id, err = x.client.ImportConnections(profile, &client.ImportConnectionsOptions{Replace: x.Replace})
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := x.wait(id); err != nil {
+		if err == noWait {
+			return nil
+		}
+		return err
+	}
+
+	// TRANSLATORS: the argument is the file name
+	fmt.Fprintf(Stdout, i18n.G("Imported connections from %q\n"), x.Positionals.Filename)
+	return nil
+}
@@ -0,0 +1,115 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	. "github.com/snapcore/snapd/cmd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *SnapSuite) TestExportConnections(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections/profile")
+		fmt.Fprintln(w, `{"type":"sync", "result":{"connections":{"consumer:plug producer:slot":{"interface":"test"}},"checksum":"1234"}}`)
+	})
+	path := filepath.Join(c.MkDir(), "conns.json")
+	rest, err := Parser(Client()).ParseArgs([]string{"export-connections", path})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(path, testutil.FileEquals, `{"connections":{"consumer:plug producer:slot":{"interface":"test"}},"checksum":"1234"}`)
+	c.Check(s.Stdout(), Equals, fmt.Sprintf("Exported connections into %q\n", path))
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestImportConnections(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/connections/profile":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+				"action": "import",
+				"profile": map[string]interface{}{
+					"connections": map[string]interface{}{},
+					"checksum":    "1234",
+				},
+				"replace": true,
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	path := filepath.Join(c.MkDir(), "conns.json")
+	c.Assert(ioutil.WriteFile(path, []byte(`{"connections":{},"checksum":"1234"}`), 0600), IsNil)
+	rest, err := Parser(Client()).ParseArgs([]string{"import-connections", "--replace", path})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, fmt.Sprintf("Imported connections from %q\n", path))
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestImportConnectionsMissingFile(c *C) {
+	_, err := Parser(Client()).ParseArgs([]string{"import-connections", filepath.Join(c.MkDir(), "missing")})
+	c.Assert(err, ErrorMatches, "open .*/missing: no such file or directory")
+}
+
+func (s *SnapSuite) TestImportConnectionsSigned(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/connections/profile":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+				"action":    "apply",
+				"assertion": "type: connection-profile\n",
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done"}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	path := filepath.Join(c.MkDir(), "profile.assert")
+	c.Assert(ioutil.WriteFile(path, []byte("type: connection-profile\n"), 0600), IsNil)
+	rest, err := Parser(Client()).ParseArgs([]string{"import-connections", "--signed", path})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, fmt.Sprintf("Imported connections from %q\n", path))
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestImportConnectionsSignedReplace(c *C) {
+	_, err := Parser(Client()).ParseArgs([]string{"import-connections", "--signed", "--replace", "profile.assert"})
+	c.Assert(err, ErrorMatches, "cannot use --replace with a signed connection profile")
+}
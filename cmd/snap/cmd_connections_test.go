@@ -851,3 +851,475 @@ func (s *SnapSuite) TestConnectionsDefiningAttribute(c *C) {
 	c.Assert(s.Stdout(), Equals, expectedStdout)
 	c.Assert(s.Stderr(), Equals, "")
 }
+
+func (s *SnapSuite) TestConnectionsOldDaemon(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, Equals, "/v2/connections")
+			w.WriteHeader(404)
+			fmt.Fprintln(w, `{"type": "error", "result": {"message": "not found"}, "status-code": 404}`)
+		case 1:
+			c.Check(r.URL.Path, Equals, "/v2/interfaces")
+			EncodeResponseBody(c, w, map[string]interface{}{
+				"type": "sync",
+				"result": map[string]interface{}{
+					"plugs": []client.Plug{
+						{
+							Snap:        "keyboard-lights",
+							Name:        "capslock",
+							Interface:   "leds",
+							Connections: []client.SlotRef{{Snap: "leds-provider", Name: "capslock-led"}},
+						},
+						{
+							Snap:      "keyboard-lights",
+							Name:      "numlock",
+							Interface: "leds",
+						},
+					},
+					"slots": []client.Slot{
+						{
+							Snap:        "leds-provider",
+							Name:        "capslock-led",
+							Interface:   "leds",
+							Connections: []client.PlugRef{{Snap: "keyboard-lights", Name: "capslock"}},
+						},
+					},
+				},
+			})
+		default:
+			c.Fatalf("unexpected request: %v", r)
+		}
+		n++
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--all"})
+	c.Check(err, IsNil)
+	expectedStdout := "" +
+		"Interface  Plug                      Slot                        Notes\n" +
+		"leds       keyboard-lights:capslock  leds-provider:capslock-led  -\n" +
+		"leds       keyboard-lights:numlock   -                           -\n"
+	c.Assert(s.Stdout(), Equals, expectedStdout)
+	c.Assert(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectionsFormat(c *C) {
+	result := client.Connections{
+		Established: []client.Connection{
+			{
+				Plug:      client.PlugRef{Snap: "keyboard-lights", Name: "capslock"},
+				Slot:      client.SlotRef{Snap: "leds-provider", Name: "capslock-led"},
+				Interface: "leds",
+				Manual:    true,
+			},
+		},
+		Plugs: []client.Plug{
+			{
+				Snap:        "keyboard-lights",
+				Name:        "capslock",
+				Interface:   "leds",
+				Connections: []client.SlotRef{{Snap: "leds-provider", Name: "capslock-led"}},
+			},
+			{
+				Snap:      "keyboard-lights",
+				Name:      "numlock",
+				Interface: "leds",
+			},
+		},
+		Slots: []client.Slot{
+			{
+				Snap:        "leds-provider",
+				Name:        "capslock-led",
+				Interface:   "leds",
+				Connections: []client.PlugRef{{Snap: "keyboard-lights", Name: "capslock"}},
+			},
+		},
+	}
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type":   "sync",
+			"result": result,
+		})
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--all", "--format=json"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `[
+  {
+    "interface": "leds",
+    "plug": {
+      "snap": "keyboard-lights",
+      "name": "capslock"
+    },
+    "slot": {
+      "snap": "leds-provider",
+      "name": "capslock-led"
+    },
+    "manual": true
+  },
+  {
+    "interface": "leds",
+    "plug": {
+      "snap": "keyboard-lights",
+      "name": "numlock"
+    }
+  }
+]
+`)
+	c.Check(s.Stderr(), Equals, "")
+
+	s.ResetStdStreams()
+
+	_, err = Parser(Client()).ParseArgs([]string{"connections", "--format=yaml"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `- interface: leds
+  plug:
+    snap: keyboard-lights
+    name: capslock
+  slot:
+    snap: leds-provider
+    name: capslock-led
+  manual: true
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectionsFormatEmpty(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type":   "sync",
+			"result": client.Connections{},
+		})
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--format=json"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "[]\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectionsInterfaceFilter(c *C) {
+	result := client.Connections{
+		Established: []client.Connection{
+			{
+				Plug:      client.PlugRef{Snap: "modem-manager", Name: "serial"},
+				Slot:      client.SlotRef{Snap: "gadget", Name: "ttyS0"},
+				Interface: "serial-port",
+				Gadget:    true,
+			},
+		},
+		Plugs: []client.Plug{
+			{
+				Snap:        "modem-manager",
+				Name:        "serial",
+				Interface:   "serial-port",
+				Connections: []client.SlotRef{{Snap: "gadget", Name: "ttyS0"}},
+			},
+		},
+		Slots: []client.Slot{
+			{
+				Snap:        "gadget",
+				Name:        "ttyS0",
+				Interface:   "serial-port",
+				Connections: []client.PlugRef{{Snap: "modem-manager", Name: "serial"}},
+			},
+		},
+	}
+	var query url.Values
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		c.Check(r.URL.Query(), DeepEquals, query)
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type":   "sync",
+			"result": result,
+		})
+	})
+
+	query = url.Values{
+		"interface": []string{"serial-port"},
+	}
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--interface=serial-port"})
+	c.Assert(err, IsNil)
+	expectedStdout := "" +
+		"Interface    Plug                  Slot          Notes\n" +
+		"serial-port  modem-manager:serial  gadget:ttyS0  gadget\n"
+	c.Check(s.Stdout(), Equals, expectedStdout)
+	c.Check(s.Stderr(), Equals, "")
+
+	s.ResetStdStreams()
+
+	// the interface filter can be combined with a snap
+	query = url.Values{
+		"interface": []string{"serial-port"},
+		"snap":      []string{"gadget"},
+		"select":    []string{"all"},
+	}
+	_, err = Parser(Client()).ParseArgs([]string{"connections", "--interface=serial-port", "gadget"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, expectedStdout)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectionsGraph(c *C) {
+	result := client.Connections{
+		Established: []client.Connection{
+			{
+				Plug:      client.PlugRef{Snap: "modem-manager", Name: "serial"},
+				Slot:      client.SlotRef{Snap: "gadget", Name: "ttyS0"},
+				Interface: "serial-port",
+			},
+			{
+				Plug:      client.PlugRef{Snap: "modem-manager", Name: "network"},
+				Slot:      client.SlotRef{Snap: "core", Name: "network"},
+				Interface: "network",
+			},
+			{
+				Plug:      client.PlugRef{Snap: "modem-manager", Name: "serial-aux"},
+				Slot:      client.SlotRef{Snap: "gadget", Name: "ttyS1"},
+				Interface: "serial-port",
+			},
+			{
+				Plug:      client.PlugRef{Snap: "foo", Name: "network"},
+				Slot:      client.SlotRef{Snap: "core", Name: "network"},
+				Interface: "network",
+			},
+		},
+		Plugs: []client.Plug{
+			{Snap: "modem-manager", Name: "serial", Interface: "serial-port"},
+			{Snap: "modem-manager", Name: "serial-aux", Interface: "serial-port"},
+			{Snap: "modem-manager", Name: "network", Interface: "network"},
+			{Snap: "foo", Name: "network", Interface: "network"},
+		},
+	}
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type":   "sync",
+			"result": result,
+		})
+	})
+
+	for _, graph := range []string{"--graph", "--graph=tree"} {
+		s.ResetStdStreams()
+		rest, err := Parser(Client()).ParseArgs([]string{"connections", graph})
+		c.Assert(err, IsNil)
+		c.Assert(rest, DeepEquals, []string{})
+		c.Check(s.Stdout(), Equals, ""+
+			"foo\n"+
+			"  network -> :network (network)\n"+
+			"modem-manager\n"+
+			"  network -> :network (network)\n"+
+			"  serial -> gadget:ttyS0 (serial-port)\n"+
+			"  serial-aux -> gadget:ttyS1 (serial-port)\n")
+		c.Check(s.Stderr(), Equals, "")
+	}
+
+	s.ResetStdStreams()
+	rest, err := Parser(Client()).ParseArgs([]string{"connections", "--graph=dot"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, ""+
+		"digraph connections {\n"+
+		"  \"foo\" -> \"core\" [label=\"network\"];\n"+
+		"  \"modem-manager\" -> \"core\" [label=\"network\"];\n"+
+		"  \"modem-manager\" -> \"gadget\" [label=\"serial-port\"];\n"+
+		"}\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectionsGraphWithFormat(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request")
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--graph", "--format=json"})
+	c.Assert(err, ErrorMatches, "cannot use --graph with --format")
+}
+
+func (s *SnapSuite) TestConnectionsWatch(c *C) {
+	defer MockIsStdoutTTY(false)()
+
+	result := client.Connections{
+		Established: []client.Connection{
+			{
+				Plug:      client.PlugRef{Snap: "keyboard-lights", Name: "capslock-led"},
+				Slot:      client.SlotRef{Snap: "leds-provider", Name: "capslock-led"},
+				Interface: "leds",
+				Manual:    true,
+			},
+		},
+		Plugs: []client.Plug{{
+			Snap: "keyboard-lights", Name: "capslock-led", Interface: "leds",
+			Connections: []client.SlotRef{{Snap: "leds-provider", Name: "capslock-led"}},
+		}},
+		Slots: []client.Slot{{
+			Snap: "leds-provider", Name: "capslock-led", Interface: "leds",
+			Connections: []client.PlugRef{{Snap: "keyboard-lights", Name: "capslock-led"}},
+		}},
+	}
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces/events":
+			c.Check(r.Method, Equals, "GET")
+			c.Check(n, Equals, 0)
+			w.Header().Set("Content-Type", "application/json-seq")
+			fmt.Fprintln(w, "\x1e"+`{"kind":"connected","snap":"keyboard-lights","plug":{"snap":"keyboard-lights","plug":"capslock-led"},"slot":{"snap":"leds-provider","slot":"capslock-led"}}`)
+			fmt.Fprintln(w, "\x1e"+`{"kind":"slot-added","snap":"core","name":"hotplug-dev"}`)
+		case "/v2/connections":
+			c.Check(r.URL.Query(), DeepEquals, url.Values{"select": []string{"all"}})
+			n++
+			if n == 1 {
+				// nothing connected yet
+				EncodeResponseBody(c, w, map[string]interface{}{
+					"type":   "sync",
+					"result": client.Connections{},
+				})
+				return
+			}
+			EncodeResponseBody(c, w, map[string]interface{}{
+				"type":   "sync",
+				"result": result,
+			})
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--all", "--watch"})
+	c.Assert(err, ErrorMatches, "cannot watch connections: event stream ended")
+	expectedStdout := "" +
+		"\n" +
+		"keyboard-lights:capslock-led connected to leds-provider:capslock-led\n" +
+		"Interface  Plug                          Slot                        Notes\n" +
+		"leds       keyboard-lights:capslock-led  leds-provider:capslock-led  manual\n" +
+		"\n" +
+		"slot :hotplug-dev added\n" +
+		"Interface  Plug                          Slot                        Notes\n" +
+		"leds       keyboard-lights:capslock-led  leds-provider:capslock-led  manual\n"
+	c.Check(s.Stdout(), Equals, expectedStdout)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectionsWatchSnapFilter(c *C) {
+	defer MockIsStdoutTTY(true)()
+
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces/events":
+			w.Header().Set("Content-Type", "application/json-seq")
+			fmt.Fprintln(w, "\x1e"+`{"kind":"plug-added","snap":"other","name":"plug"}`)
+			fmt.Fprintln(w, "\x1e"+`{"kind":"disconnected","snap":"other","plug":{"snap":"other","plug":"plug"},"slot":{"snap":"foo","slot":"slot"}}`)
+			fmt.Fprintln(w, "\x1e"+`{"kind":"snap-added","snap":"bar","error":"too many pending events"}`)
+		case "/v2/connections":
+			c.Check(r.URL.Query(), DeepEquals, url.Values{"snap": []string{"foo"}, "select": []string{"all"}})
+			EncodeResponseBody(c, w, map[string]interface{}{
+				"type":   "sync",
+				"result": client.Connections{},
+			})
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--watch", "foo"})
+	c.Assert(err, ErrorMatches, "cannot watch connections: too many pending events")
+	c.Check(s.Stdout(), Equals, "\033[H\033[2J"+"other:plug disconnected from foo:slot\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectionsWatchWithFormat(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request")
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connections", "--watch", "--format=json"})
+	c.Assert(err, ErrorMatches, "cannot use --watch with --format")
+}
+
+func (s *SnapSuite) TestConnectionsSummary(c *C) {
+	result := client.Connections{
+		Established: []client.Connection{{
+			Plug:      client.PlugRef{Snap: "consumer", Name: "home"},
+			Slot:      client.SlotRef{Snap: "core", Name: "home"},
+			Interface: "home",
+		}, {
+			Plug:      client.PlugRef{Snap: "other", Name: "db"},
+			Slot:      client.SlotRef{Snap: "consumer", Name: "db"},
+			Interface: "content",
+		}},
+		Plugs: []client.Plug{
+			{Snap: "consumer", Name: "home", Interface: "home", Connections: []client.SlotRef{{Snap: "core", Name: "home"}}},
+			{Snap: "consumer", Name: "network", Interface: "network"},
+			{Snap: "consumer", Name: "camera", Interface: "camera"},
+			{Snap: "other", Name: "db", Interface: "content", Connections: []client.SlotRef{{Snap: "consumer", Name: "db"}}},
+		},
+		Slots: []client.Slot{
+			{Snap: "core", Name: "home", Interface: "home", Connections: []client.PlugRef{{Snap: "consumer", Name: "home"}}},
+			{Snap: "core", Name: "network", Interface: "network"},
+			{Snap: "consumer", Name: "db", Interface: "content", Connections: []client.PlugRef{{Snap: "other", Name: "db"}}},
+			{Snap: "consumer", Name: "logs", Interface: "content"},
+		},
+	}
+	var queries []url.Values
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/connections":
+			c.Check(r.Method, Equals, "GET")
+			queries = append(queries, r.URL.Query())
+			EncodeResponseBody(c, w, map[string]interface{}{
+				"type":   "sync",
+				"result": result,
+			})
+		case "/v2/snaps":
+			c.Check(r.Method, Equals, "GET")
+			EncodeResponseBody(c, w, map[string]interface{}{
+				"type": "sync",
+				"result": []map[string]interface{}{
+					{"name": "core", "type": "os"},
+					{"name": "consumer", "type": "app"},
+				},
+			})
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	rest, err := Parser(Client()).ParseArgs([]string{"connections", "--summary", "consumer"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(queries, DeepEquals, []url.Values{
+		{"select": []string{"all"}, "snap": []string{"consumer"}},
+		{"select": []string{"all"}},
+	})
+	c.Check(s.Stdout(), Equals, ""+
+		"Snap \"consumer\" uses:\n"+
+		"  camera   camera   not connected\n"+
+		"  home     home     provided by :home\n"+
+		"  network  network  not connected\n"+
+		"Snap \"consumer\" offers:\n"+
+		"  db    content  used by other:db\n"+
+		"  logs  content  not used\n"+
+		"Suggested connections:\n"+
+		"  snap connect consumer:network :network\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectionsSummaryErrors(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request")
+	})
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"connections", "--summary"}, "cannot use --summary without a snap name"},
+		{[]string{"connections", "--summary", "--format=json", "foo"}, "cannot use --summary with --format"},
+		{[]string{"connections", "--summary", "--watch", "foo"}, "cannot use --summary with --graph, --watch or --interface"},
+		{[]string{"connections", "--summary", "--interface=home", "foo"}, "cannot use --summary with --graph, --watch or --interface"},
+	} {
+		_, err := Parser(Client()).ParseArgs(t.args)
+		c.Check(err, ErrorMatches, t.err, Commentf("%v", t.args))
+	}
+}
@@ -0,0 +1,144 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+)
+
+type cmdSandboxExec struct {
+	Positional struct {
+		SnapApp string   `positional-arg-name:"<snap.app>" required:"yes"`
+		Command []string `positional-arg-name:"<command>" required:"1"`
+	} `positional-args:"yes"`
+}
+
+var shortSandboxExecHelp = i18n.G("Run a command under the confinement of a snap and report denials")
+var longSandboxExecHelp = i18n.G(`
+The sandbox-exec command runs the given command confined by the security
+profiles of the given snap application, as they were generated for the
+current connections of the snap, and then reports the denials logged by the
+kernel while the command was running.
+
+It is meant for interface authors checking that their policy permits the
+intended operations. The command is run through the shell of the snap and
+does not get the standard input of the caller. Seccomp denials are reported
+for all processes, as the kernel does not log the profile they come from.
+`)
+
+func init() {
+	addDebugCommand("sandbox-exec", shortSandboxExecHelp, longSandboxExecHelp, func() flags.Commander {
+		return &cmdSandboxExec{}
+	}, nil, []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<snap.app>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("Application whose confinement is used"),
+	}, {
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<command>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("Command to run, with its arguments"),
+	}})
+}
+
+// shellQuote quotes the given words so that the shell passes them through
+// unchanged.
+func shellQuote(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = "'" + strings.Replace(word, "'", `'\''`, -1) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// sandboxDenials picks the apparmor denials for the profiles of the given
+// snap and all the seccomp denials from the kernel log.
+func sandboxDenials(kernelLog []byte, snapName string) []string {
+	var denials []string
+	profiles := []string{
+		fmt.Sprintf(`profile="snap.%s.`, snapName),
+		fmt.Sprintf(`profile="snap-update-ns.%s"`, snapName),
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(kernelLog))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.Contains(line, `apparmor="DENIED"`):
+			for _, profile := range profiles {
+				if strings.Contains(line, profile) {
+					denials = append(denials, line)
+					break
+				}
+			}
+		case strings.Contains(line, "type=1326"):
+			denials = append(denials, line)
+		}
+	}
+	return denials
+}
+
+func (x *cmdSandboxExec) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	snapName, _ := snap.SplitSnapApp(x.Positional.SnapApp)
+
+	// snap run waits for the profiles to be regenerated if needed
+	start := timeNow()
+	cmd := exec.Command("snap", "run", "--shell", x.Positional.SnapApp)
+	cmd.Stdin = strings.NewReader("exec " + shellQuote(x.Positional.Command) + "\n")
+	cmd.Stdout = Stdout
+	cmd.Stderr = Stderr
+	runErr := cmd.Run()
+	exitErr, ok := runErr.(*exec.ExitError)
+	if runErr != nil && !ok {
+		return fmt.Errorf(i18n.G("cannot run command: %v"), runErr)
+	}
+
+	output, err := exec.Command("journalctl", "-k", "-o", "cat", "--no-pager", "--since", fmt.Sprintf("@%d", start.Unix())).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(i18n.G("cannot read kernel log: %v"), osutil.OutputErr(output, err))
+	}
+	denials := sandboxDenials(output, snapName)
+	if len(denials) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("No denials found."))
+	} else {
+		fmt.Fprintln(Stdout, i18n.G("Denials:"))
+		for _, denial := range denials {
+			fmt.Fprintf(Stdout, "  %s\n", denial)
+		}
+	}
+
+	if exitErr != nil {
+		return fmt.Errorf(i18n.G("command exited with status %d"), exitErr.ExitCode())
+	}
+	return nil
+}
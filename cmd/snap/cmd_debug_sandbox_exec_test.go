@@ -0,0 +1,91 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+const sandboxKernelLog = `audit: type=1400 audit(1.1:1): apparmor="DENIED" operation="open" profile="snap.foo.app" name="/etc/shadow" requested_mask="r"
+audit: type=1400 audit(1.1:2): apparmor="DENIED" operation="open" profile="snap.foobar.app" name="/etc/shadow" requested_mask="r"
+audit: type=1400 audit(1.1:3): apparmor="ALLOWED" operation="open" profile="snap.foo.app" name="/etc/passwd"
+audit: type=1400 audit(1.1:4): apparmor="DENIED" operation="mount" profile="snap-update-ns.foo" name="/opt/"
+audit: type=1326 audit(1.1:5): pid=42 comm="mknod" exe="/usr/bin/mknod" sig=0 arch=c000003e syscall=133 code=0x50000
+usb 1-1: new high-speed USB device
+`
+
+func (s *SnapSuite) mockSandboxExec(c *check.C, snapScript, journalScript string) (snapCmd, journalCmd *testutil.MockCmd, stdinPath string) {
+	stdinPath = filepath.Join(c.MkDir(), "stdin")
+	snapCmd = testutil.MockCommand(c, "snap", fmt.Sprintf("cat > %s\n%s", stdinPath, snapScript))
+	s.AddCleanup(snapCmd.Restore)
+	journalCmd = testutil.MockCommand(c, "journalctl", journalScript)
+	s.AddCleanup(journalCmd.Restore)
+	s.AddCleanup(snap.MockTimeNow(func() time.Time { return time.Unix(1600000000, 500) }))
+	return snapCmd, journalCmd, stdinPath
+}
+
+func (s *SnapSuite) TestSandboxExecReportsDenials(c *check.C) {
+	snapCmd, journalCmd, stdinPath := s.mockSandboxExec(c, "echo output", fmt.Sprintf("cat <<'EOF'\n%sEOF", sandboxKernelLog))
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "sandbox-exec", "foo.app", "cat", "/etc/shadow", "it's"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+
+	c.Check(snapCmd.Calls(), check.DeepEquals, [][]string{{"snap", "run", "--shell", "foo.app"}})
+	c.Check(stdinPath, testutil.FileEquals, `exec 'cat' '/etc/shadow' 'it'\''s'`+"\n")
+	c.Check(journalCmd.Calls(), check.DeepEquals, [][]string{
+		{"journalctl", "-k", "-o", "cat", "--no-pager", "--since", "@1600000000"},
+	})
+	c.Check(s.Stdout(), check.Equals, `output
+Denials:
+  audit: type=1400 audit(1.1:1): apparmor="DENIED" operation="open" profile="snap.foo.app" name="/etc/shadow" requested_mask="r"
+  audit: type=1400 audit(1.1:4): apparmor="DENIED" operation="mount" profile="snap-update-ns.foo" name="/opt/"
+  audit: type=1326 audit(1.1:5): pid=42 comm="mknod" exe="/usr/bin/mknod" sig=0 arch=c000003e syscall=133 code=0x50000
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+}
+
+func (s *SnapSuite) TestSandboxExecNoDenialsCommandFailed(c *check.C) {
+	s.mockSandboxExec(c, "echo failed >&2; exit 3", "")
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "sandbox-exec", "foo.app", "false"})
+	c.Assert(err, check.ErrorMatches, "command exited with status 3")
+	c.Check(s.Stdout(), check.Equals, "No denials found.\n")
+	c.Check(s.Stderr(), check.Equals, "failed\n")
+}
+
+func (s *SnapSuite) TestSandboxExecKernelLogError(c *check.C) {
+	s.mockSandboxExec(c, "", "echo no journal >&2; exit 1")
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "sandbox-exec", "foo.app", "true"})
+	c.Assert(err, check.ErrorMatches, "cannot read kernel log: no journal")
+}
+
+func (s *SnapSuite) TestSandboxExecNeedsCommand(c *check.C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "sandbox-exec", "foo.app"})
+	c.Assert(err, check.ErrorMatches, "the required argument `<command> \\(at least 1 argument\\)` was not provided")
+}
@@ -30,7 +30,9 @@ import (
 
 type cmdDisconnect struct {
 	waitMixin
-	Forget      bool `long:"forget"`
+	Forget      bool   `long:"forget"`
+	All         bool   `long:"all"`
+	Format      string `long:"format" default:"pretty" choice:"pretty" choice:"json" choice:"yaml"`
 	Positionals struct {
 		Offer disconnectSlotOrPlugSpec `required:"true"`
 		Use   disconnectSlotSpec
@@ -51,16 +53,32 @@ $ snap disconnect <snap>:<slot or plug>
 Disconnects everything from the provided plug or slot.
 The snap name may be omitted for the core snap.
 
+$ snap disconnect --all <snap>
+
+Disconnects everything from all the plugs and slots of the provided snap
+and lists the connections that were cut.
+
 When an automatic connection is manually disconnected, its disconnected state
 is retained after a snap refresh. The --forget flag can be added to the
 disconnect command to reset this behaviour, and consequently re-enable
 an automatic reconnection after a snap refresh.
+
+Besides the usual exit codes, the command exits with 31 when the plug or
+slot does not exist. With --format the error is also reported in the
+output, under "error", which is also used to tell that there was nothing
+to disconnect.
 `)
 
 func init() {
 	addCommand("disconnect", shortDisconnectHelp, longDisconnectHelp, func() flags.Commander {
 		return &cmdDisconnect{}
-	}, waitDescs.also(map[string]string{"forget": "Forget remembered state about the given connection."}), []argDesc{
+	}, waitDescs.also(map[string]string{
+		"forget": "Forget remembered state about the given connection.",
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"all": i18n.G("Disconnect all plugs and slots of the given snap."),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"format": i18n.G("Use the given output format"),
+	}), []argDesc{
 		// TRANSLATORS: This needs to begin with < and end with >
 		{name: i18n.G("<snap>:<plug>")},
 		// TRANSLATORS: This needs to begin with < and end with >
@@ -73,6 +91,17 @@ func (x *cmdDisconnect) Execute(args []string) error {
 		return ErrExtraArgs
 	}
 
+	return reportInterfacesError(x.Format, x.disconnect())
+}
+
+func (x *cmdDisconnect) disconnect() error {
+	if x.All {
+		return x.disconnectAll()
+	}
+	if err := x.Positionals.Offer.strictErr; err != nil {
+		return err
+	}
+
 	offer := x.Positionals.Offer.SnapAndNameStrict
 	use := x.Positionals.Use.SnapAndNameStrict
 
@@ -85,21 +114,59 @@ func (x *cmdDisconnect) Execute(args []string) error {
 
 	opts := &client.DisconnectOptions{Forget: x.Forget}
 	id, err := x.client.Disconnect(offer.Snap, offer.Name, use.Snap, use.Name, opts)
+	return x.showResult(id, err, false)
+}
+
+func (x *cmdDisconnect) disconnectAll() error {
+	snapName := x.Positionals.Offer.Snap
+	if x.Positionals.Offer.Name != "" || x.Positionals.Use.Snap != "" || x.Positionals.Use.Name != "" {
+		return fmt.Errorf(i18n.G("cannot use --all with a plug or slot, pass only a snap name"))
+	}
+	if x.Forget {
+		return fmt.Errorf(i18n.G("cannot use --all with --forget"))
+	}
+
+	// the plugs and slots of the snap without names select all of them
+	opts := &client.DisconnectOptions{Force: true}
+	id, err := x.client.Disconnect(snapName, "", snapName, "", opts)
+	return x.showResult(id, err, true)
+}
+
+// showResult waits for the disconnect change and reports its outcome. With
+// listCut, the connections that were severed are also listed in the pretty
+// output.
+func (x *cmdDisconnect) showResult(id string, err error, listCut bool) error {
 	if err != nil {
 		if client.IsInterfacesUnchangedError(err) {
-			fmt.Fprintf(Stdout, i18n.G("No connections to disconnect"))
-			fmt.Fprintf(Stdout, "\n")
-			return nil
+			return &client.Error{
+				Kind:    client.ErrorKindInterfacesUnchanged,
+				Message: i18n.G("No connections to disconnect"),
+			}
 		}
 		return err
 	}
 
-	if _, err := x.wait(id); err != nil {
+	chg, err := x.wait(id)
+	if err != nil {
 		if err == noWait {
 			return nil
 		}
 		return err
 	}
 
+	if x.Format == "pretty" && !listCut {
+		return nil
+	}
+	conns, err := changeConnections(chg, "disconnected")
+	if err != nil {
+		return err
+	}
+	if x.Format != "pretty" {
+		return writeFormatted(x.Format, conns)
+	}
+	for _, conn := range conns {
+		// TRANSLATORS: the first %s is the plug, the second %s is the slot
+		fmt.Fprintf(Stdout, i18n.G("%s disconnected from %s\n"), endpoint(conn.Plug.Snap, conn.Plug.Name), endpoint(conn.Slot.Snap, conn.Slot.Name))
+	}
 	return nil
 }
@@ -46,15 +46,30 @@ $ snap disconnect <snap>:<slot or plug>
 Disconnects everything from the provided plug or slot.
 The snap name may be omitted for the core snap.
 
+$ snap disconnect --all <snap>
+
+Disconnects everything from all the plugs and slots of the provided snap
+and lists the connections that were cut.
+
 When an automatic connection is manually disconnected, its disconnected state
 is retained after a snap refresh. The --forget flag can be added to the
 disconnect command to reset this behaviour, and consequently re-enable
 an automatic reconnection after a snap refresh.
 
+Besides the usual exit codes, the command exits with 31 when the plug or
+slot does not exist. With --format the error is also reported in the
+output, under "error", which is also used to tell that there was nothing
+to disconnect.
+
 [disconnect command options]
-      --no-wait          Do not wait for the operation to finish but just print
-                         the change id.
-      --forget           Forget remembered state about the given connection.
+      --no-wait                     Do not wait for the operation to finish but
+                                    just print the change id.
+      --forget                      Forget remembered state about the given
+                                    connection.
+      --all                         Disconnect all plugs and slots of the given
+                                    snap.
+      --format=[pretty|json|yaml]   Use the given output format (default:
+                                    pretty)
 `
 	s.testSubCommandHelp(c, "disconnect", msg)
 }
@@ -208,13 +223,83 @@ func (s *SnapSuite) TestDisconnectEverythingFromSpecificSnap(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		c.Fatalf("expected nothing to reach the server")
 	})
-	rest, err := Parser(Client()).ParseArgs([]string{"disconnect", "consumer"})
+	_, err := Parser(Client()).ParseArgs([]string{"disconnect", "consumer"})
 	c.Assert(err, ErrorMatches, `invalid value: "consumer" \(want snap:name or :name\)`)
-	c.Assert(rest, DeepEquals, []string{"consumer"})
 	c.Assert(s.Stdout(), Equals, "")
 	c.Assert(s.Stderr(), Equals, "")
 }
 
+func (s *SnapSuite) TestDisconnectAllFromSnap(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+				"action": "disconnect",
+				"force":  true,
+				"plugs": []interface{}{
+					map[string]interface{}{
+						"snap": "consumer",
+						"plug": "",
+					},
+				},
+				"slots": []interface{}{
+					map[string]interface{}{
+						"snap": "consumer",
+						"slot": "",
+					},
+				},
+			})
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done", "data": {"disconnected": [
+				{"plug": {"snap": "consumer", "plug": "network"}, "slot": {"snap": "core", "slot": "network"}},
+				{"plug": {"snap": "consumer", "plug": "content"}, "slot": {"snap": "producer", "slot": "content"}}
+			]}}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"disconnect", "--all", "consumer"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Assert(s.Stdout(), Equals, ""+
+		"consumer:network disconnected from :network\n"+
+		"consumer:content disconnected from producer:content\n")
+	c.Assert(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDisconnectAllNothingToDo(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/interfaces")
+		w.WriteHeader(400)
+		fmt.Fprintln(w, `{"type":"error", "status-code": 400, "result": {"message": "nothing to do", "kind": "interfaces-unchanged"}}`)
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"disconnect", "--all", "consumer"})
+	c.Assert(err, IsNil)
+	c.Assert(s.Stdout(), Equals, "No connections to disconnect\n")
+	c.Assert(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDisconnectAllErrors(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("expected nothing to reach the server")
+	})
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"disconnect", "--all", "consumer:plug"}, `cannot use --all with a plug or slot, pass only a snap name`},
+		{[]string{"disconnect", "--all", "consumer", "producer:slot"}, `cannot use --all with a plug or slot, pass only a snap name`},
+		{[]string{"disconnect", "--all", "--forget", "consumer"}, `cannot use --all with --forget`},
+	} {
+		_, err := Parser(Client()).ParseArgs(t.args)
+		c.Check(err, ErrorMatches, t.err, Commentf("%v", t.args))
+	}
+}
+
 func (s *SnapSuite) TestDisconnectCompletion(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -268,3 +353,49 @@ func (s *SnapSuite) TestDisconnectCompletion(c *C) {
 	c.Assert(s.Stdout(), Equals, "")
 	c.Assert(s.Stderr(), Equals, "")
 }
+
+func (s *SnapSuite) TestDisconnectFormatYAML(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			w.WriteHeader(202)
+			fmt.Fprintln(w, `{"type":"async", "status-code": 202, "change": "zzz"}`)
+		case "/v2/changes/zzz":
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done", "data": {"disconnected": [
+				{"plug": {"snap": "consumer", "plug": "plug"}, "slot": {"snap": "producer", "slot": "slot"}}
+			]}}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"disconnect", "--format=yaml", "producer:slot"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `- plug:
+    snap: consumer
+    name: plug
+  slot:
+    snap: producer
+    name: slot
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDisconnectFormatJSONNothingToDo(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		fmt.Fprintln(w, `{"type":"error", "status-code": 400, "result": {"message": "nothing to do", "kind": "interfaces-unchanged"}}`)
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"disconnect", "--format=json", "producer:slot"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `{
+  "error": {
+    "kind": "interfaces-unchanged",
+    "message": "No connections to disconnect",
+    "exit-code": 0
+  }
+}
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
@@ -202,9 +202,10 @@ var helpCategories = []helpCategory{
 		Description: i18n.G("manage services"),
 		Commands:    []string{"services", "start", "stop", "restart", "logs"},
 	}, {
-		Label:       i18n.G("Permissions"),
-		Description: i18n.G("manage permissions"),
-		Commands:    []string{"connections", "interface", "connect", "disconnect"},
+		Label:           i18n.G("Permissions"),
+		Description:     i18n.G("manage permissions"),
+		Commands:        []string{"connections", "interface", "connect", "disconnect"},
+		AllOnlyCommands: []string{"export-connections", "import-connections", "connections-history", "revert-connections"},
 	}, {
 		Label:       i18n.G("Configuration"),
 		Description: i18n.G("system administration and configuration"),
@@ -247,7 +248,7 @@ var helpCategories = []helpCategory{
 		Label:           i18n.G("Development"),
 		Description:     i18n.G("developer-oriented features"),
 		Commands:        []string{"download", "pack", "run", "try"},
-		AllOnlyCommands: []string{"prepare-image"},
+		AllOnlyCommands: []string{"prepare-image", "try-connect"},
 	},
 }
 
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/jessevdk/go-flags"
@@ -116,6 +117,17 @@ func (x *cmdInterface) showOneInterface(iface *client.Interface) {
 	if iface.DocURL != "" {
 		fmt.Fprintf(w, "documentation:\t%s\n", iface.DocURL)
 	}
+	// the system snap provides a slot of the interface on these systems
+	var implicitOn []string
+	if iface.ImplicitOnCore {
+		implicitOn = append(implicitOn, "core")
+	}
+	if iface.ImplicitOnClassic {
+		implicitOn = append(implicitOn, "classic")
+	}
+	if len(implicitOn) > 0 {
+		fmt.Fprintf(w, "implicit-on:\t%s\n", strings.Join(implicitOn, ", "))
+	}
 	if len(iface.Plugs) > 0 {
 		fmt.Fprintf(w, "plugs:\n")
 		for _, plug := range iface.Plugs {
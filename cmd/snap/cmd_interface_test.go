@@ -168,6 +168,10 @@ func (s *SnapSuite) TestInterfaceDetails(c *C) {
 				Name:    "network",
 				Summary: "allows access to the network",
 				DocURL:  "http://example.org/about-the-network-interface",
+
+				ImplicitOnCore:    true,
+				ImplicitOnClassic: true,
+
 				Plugs: []client.Plug{
 					{Snap: "deepin-music", Name: "network"},
 					{Snap: "http", Name: "network"},
@@ -183,6 +187,7 @@ func (s *SnapSuite) TestInterfaceDetails(c *C) {
 		"name:          network\n" +
 		"summary:       allows access to the network\n" +
 		"documentation: http://example.org/about-the-network-interface\n" +
+		"implicit-on:   core, classic\n" +
 		"plugs:\n" +
 		"  - deepin-music\n" +
 		"  - http\n" +
@@ -61,6 +61,10 @@ valid snap metadata and raises an error otherwise. Application commands listed
 in snap metadata file, but appearing with incorrect permission bits result in an
 error. Commands that are missing from snap-dir are listed in diagnostic
 messages.
+
+Plugs and slots that do not match an interface known to snapd, or whose
+attributes are not valid for their interface, are reported by both forms of
+the command, but do not cause an error.
 `)
 
 func init() {
@@ -86,7 +90,7 @@ func init() {
 func (x *packCmd) Execute([]string) error {
 	// plug/slot sanitization is disabled (no-op) by default at the package level for "snap" command,
 	// for "snap pack" however we want real validation.
-	snap.SanitizePlugsSlots = builtin.SanitizePlugsSlots
+	snap.SanitizePlugsSlots = builtin.SanitizePlugsSlotsForPack
 
 	if x.Positional.TargetDir != "" && x.Filename != "" && filepath.IsAbs(x.Filename) {
 		return fmt.Errorf(i18n.G("you can't specify an absolute filename while also specifying target dir."))
@@ -86,6 +86,21 @@ slots:
 	c.Check(s.stderr.String(), check.Equals, "snap \"foo\" has bad plugs or slots: kale (unknown interface \"kale\")\n")
 }
 
+func (s *SnapSuite) TestPackCheckSkeletonReservedNames(c *check.C) {
+	snapYaml := `
+name: foo
+version: 1.0.1
+plugs:
+  network:
+    interface: home
+`
+	snapDir := makeSnapDirForPack(c, snapYaml)
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"pack", "--check-skeleton", snapDir})
+	c.Assert(err, check.IsNil)
+	c.Check(s.stderr.String(), check.Equals, `snap "foo" has bad plugs or slots: network (plug name "network" is reserved for the "network" interface, rename the plug or declare it with "interface: network")`+"\n")
+}
+
 func (s *SnapSuite) TestPackPacksFailsForMissingPaths(c *check.C) {
 	_, r := logger.MockLogger()
 	defer r()
@@ -0,0 +1,73 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdRoutineConnectCandidates struct {
+	clientMixin
+	Positionals struct {
+		PlugSpec SnapAndNameStrict `required:"yes"`
+		Prefix   string
+	} `positional-args:"true"`
+}
+
+var shortRoutineConnectCandidatesHelp = i18n.G("List slots a plug can be connected to")
+var longRoutineConnectCandidatesHelp = i18n.G(`
+The connect-candidates command lists the disconnected slots of the same
+interface as the given plug, one per line, optionally limited to those
+starting with the given prefix.
+
+This command is used by shell completion of the connect command.
+`)
+
+func init() {
+	addRoutineCommand("connect-candidates", shortRoutineConnectCandidatesHelp, longRoutineConnectCandidatesHelp, func() flags.Commander {
+		return &cmdRoutineConnectCandidates{}
+	}, nil, []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<snap>:<plug>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("Plug to be connected"),
+	}, {
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<prefix>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("Beginning of the slot, as typed so far"),
+	}})
+}
+
+func (x *cmdRoutineConnectCandidates) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	spec := connectSlotSpec{plug: &x.Positionals.PlugSpec.SnapAndName}
+	for _, candidate := range spec.Complete(x.Positionals.Prefix) {
+		fmt.Fprintln(Stdout, candidate.Item)
+	}
+	return nil
+}
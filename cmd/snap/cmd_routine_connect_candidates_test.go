@@ -0,0 +1,86 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+func (s *SnapSuite) TestRoutineConnectCandidates(c *C) {
+	var queries []string
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/connections")
+		queries = append(queries, r.URL.RawQuery)
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type":   "sync",
+			"result": fortestingConnectionList,
+		})
+	})
+
+	for _, t := range []struct {
+		args     []string
+		expected string
+	}{
+		// only the disconnected slots of the bool-file interface
+		{[]string{"keyboard-lights:capslock-led"}, "wake-up-alarm:toggle\n"},
+		{[]string{"keyboard-lights:capslock-led", "w"}, "wake-up-alarm:toggle\n"},
+		{[]string{"keyboard-lights:capslock-led", "c"}, ""},
+		// no slots of the network-listening interface
+		{[]string{"paste-daemon:network-listening"}, ""},
+		// unknown plugs are not filtered
+		{[]string{"unknown:plug", ":"}, ":x11\n"},
+	} {
+		s.ResetStdStreams()
+		queries = nil
+
+		_, err := snap.Parser(snap.Client()).ParseArgs(append([]string{"routine", "connect-candidates"}, t.args...))
+		c.Assert(err, IsNil)
+		c.Check(s.Stdout(), Equals, t.expected, Commentf("%v", t.args))
+		c.Check(s.Stderr(), Equals, "")
+	}
+	// the last query looks up the plug first
+	c.Check(queries, DeepEquals, []string{"select=all&snap=unknown", "select=all"})
+}
+
+func (s *SnapSuite) TestRoutineConnectCandidatesInterfaceQuery(c *C) {
+	var queries []string
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		EncodeResponseBody(c, w, map[string]interface{}{
+			"type":   "sync",
+			"result": fortestingConnectionList,
+		})
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "connect-candidates", "keyboard-lights:capslock-led"})
+	c.Assert(err, IsNil)
+	// the listing of slots is filtered by interface
+	c.Check(queries, DeepEquals, []string{"select=all&snap=keyboard-lights", "interface=bool-file&select=all"})
+}
+
+func (s *SnapSuite) TestRoutineConnectCandidatesBadPlug(c *C) {
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"routine", "connect-candidates", "keyboard-lights"})
+	c.Assert(err, ErrorMatches, `invalid value: "keyboard-lights" \(want snap:name or :name\)`)
+}
@@ -0,0 +1,196 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdTryConnect struct {
+	waitMixin
+	Positionals struct {
+		Snap installedSnapName `required:"yes"`
+	} `positional-args:"true"`
+}
+
+var shortTryConnectHelp = i18n.G("Connect the disconnected plugs of a snap")
+var longTryConnectHelp = i18n.G(`
+The try-connect command connects every disconnected plug of the given snap
+to the best candidate slot of the same interface, and reports the plugs for
+which no such slot could be chosen.
+
+Slots of the system snap are preferred, followed by slots of gadget snaps.
+Otherwise, a plug is connected only if there is a single candidate slot.
+
+This command is intended for development, when iterating on snaps that
+were installed without assertions.
+`)
+
+func init() {
+	addCommand("try-connect", shortTryConnectHelp, longTryConnectHelp, func() flags.Commander {
+		return &cmdTryConnect{}
+	}, waitDescs, []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<snap>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("Snap whose plugs are to be connected"),
+	}})
+}
+
+// candidateSlot is a slot a plug can be connected to.
+type candidateSlot struct {
+	*client.Slot
+	// rank tells how preferred the slot is, lower is better
+	rank int
+}
+
+// plugCandidates holds a disconnected plug along with the slots it can be
+// connected to, most preferred first.
+type plugCandidates struct {
+	plug       client.Plug
+	candidates []candidateSlot
+}
+
+// candidateRank orders candidate slots, lower is preferred.
+func candidateRank(slot *client.Slot, gadgets map[string]bool) int {
+	switch {
+	case isSystemSnap(slot.Snap):
+		return 0
+	case gadgets[slot.Snap]:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// findPlugCandidates returns the disconnected plugs of the given snap,
+// sorted by name, each with the slots of the same interface.
+func findPlugCandidates(cli *client.Client, snapName string) ([]plugCandidates, error) {
+	connections, err := cli.Connections(&client.ConnectionOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+	snaps, err := cli.List(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	gadgets := make(map[string]bool)
+	for _, snap := range snaps {
+		if snap.Type == "gadget" {
+			gadgets[snap.Name] = true
+		}
+	}
+
+	var result []plugCandidates
+	for _, plug := range connections.Plugs {
+		if plug.Snap != snapName || len(plug.Connections) != 0 {
+			continue
+		}
+		pc := plugCandidates{plug: plug}
+		for i := range connections.Slots {
+			slot := &connections.Slots[i]
+			if slot.Interface == plug.Interface {
+				pc.candidates = append(pc.candidates, candidateSlot{Slot: slot, rank: candidateRank(slot, gadgets)})
+			}
+		}
+		sort.Slice(pc.candidates, func(i, j int) bool {
+			ci, cj := pc.candidates[i], pc.candidates[j]
+			if ci.rank != cj.rank {
+				return ci.rank < cj.rank
+			}
+			return endpoint(ci.Snap, ci.Name) < endpoint(cj.Snap, cj.Name)
+		})
+		result = append(result, pc)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].plug.Name < result[j].plug.Name })
+	return result, nil
+}
+
+// best picks the slot the plug should be connected to. The reason a slot
+// cannot be chosen is returned otherwise.
+func (pc *plugCandidates) best() (*client.Slot, string) {
+	if len(pc.candidates) == 0 {
+		return nil, i18n.G("no candidate slots")
+	}
+	var preferred []string
+	for _, slot := range pc.candidates {
+		if slot.rank == pc.candidates[0].rank {
+			preferred = append(preferred, endpoint(slot.Snap, slot.Name))
+		}
+	}
+	if len(preferred) > 1 {
+		// TRANSLATORS: %s is a list of slots
+		return nil, fmt.Sprintf(i18n.G("more than one candidate slot: %s"), strings.Join(preferred, ", "))
+	}
+	return pc.candidates[0].Slot, ""
+}
+
+func (x *cmdTryConnect) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	snapName := string(x.Positionals.Snap)
+
+	plugs, err := findPlugCandidates(x.client, snapName)
+	if err != nil {
+		return err
+	}
+	if len(plugs) == 0 {
+		fmt.Fprintf(Stdout, i18n.G("No disconnected plugs in snap %q\n"), snapName)
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+	for _, pc := range plugs {
+		plug := &pc.plug
+		plugName := endpoint(plug.Snap, plug.Name)
+		slot, reason := pc.best()
+		if slot == nil {
+			// TRANSLATORS: the first %s is a plug, the second %s is the reason it was not connected
+			fmt.Fprintf(w, i18n.G("%s\tnot connected: %s\n"), plugName, reason)
+			continue
+		}
+		slotName := endpoint(slot.Snap, slot.Name)
+		id, err := x.client.Connect(plug.Snap, plug.Name, slot.Snap, slot.Name)
+		if err == nil && !x.NoWait {
+			_, err = x.wait(id)
+		}
+		switch {
+		case err != nil:
+			// TRANSLATORS: the first %s is a plug, the second %s is a slot, the third is an error
+			fmt.Fprintf(w, i18n.G("%s\tnot connected to %s: %v\n"), plugName, slotName, err)
+		case x.NoWait:
+			// TRANSLATORS: the first %s is a plug, the second %s is a slot, the third is a change id
+			fmt.Fprintf(w, i18n.G("%s\tconnecting to %s in change %s\n"), plugName, slotName, id)
+		default:
+			// TRANSLATORS: the first %s is a plug, the second %s is a slot
+			fmt.Fprintf(w, i18n.G("%s\tconnected to %s\n"), plugName, slotName)
+		}
+	}
+	return nil
+}
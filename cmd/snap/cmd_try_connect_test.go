@@ -0,0 +1,149 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	. "github.com/snapcore/snapd/cmd/snap"
+)
+
+var tryConnectConnections = client.Connections{
+	Established: []client.Connection{{
+		Plug:      client.PlugRef{Snap: "consumer", Name: "home"},
+		Slot:      client.SlotRef{Snap: "core", Name: "home"},
+		Interface: "home",
+	}},
+	Plugs: []client.Plug{
+		{Snap: "consumer", Name: "home", Interface: "home", Connections: []client.SlotRef{{Snap: "core", Name: "home"}}},
+		{Snap: "consumer", Name: "network", Interface: "network"},
+		{Snap: "consumer", Name: "serial", Interface: "serial-port"},
+		{Snap: "consumer", Name: "content", Interface: "content"},
+		{Snap: "consumer", Name: "camera", Interface: "camera"},
+		{Snap: "consumer", Name: "audio", Interface: "audio-playback"},
+		{Snap: "other", Name: "network", Interface: "network"},
+	},
+	Slots: []client.Slot{
+		{Snap: "core", Name: "home", Interface: "home", Connections: []client.PlugRef{{Snap: "consumer", Name: "home"}}},
+		{Snap: "core", Name: "network", Interface: "network"},
+		{Snap: "router", Name: "network", Interface: "network"},
+		{Snap: "pc", Name: "ttyS0", Interface: "serial-port"},
+		{Snap: "modem", Name: "serial", Interface: "serial-port"},
+		{Snap: "provider-a", Name: "content", Interface: "content"},
+		{Snap: "provider-b", Name: "content", Interface: "content"},
+		{Snap: "pulse", Name: "audio", Interface: "audio-playback"},
+	},
+}
+
+func (s *SnapSuite) mockTryConnectServer(c *C, connects *[]string) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/connections":
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Query(), DeepEquals, url.Values{"select": []string{"all"}})
+			EncodeResponseBody(c, w, map[string]interface{}{
+				"type":   "sync",
+				"result": tryConnectConnections,
+			})
+		case "/v2/snaps":
+			c.Check(r.Method, Equals, "GET")
+			EncodeResponseBody(c, w, map[string]interface{}{
+				"type": "sync",
+				"result": []map[string]interface{}{
+					{"name": "core", "type": "os"},
+					{"name": "pc", "type": "gadget"},
+					{"name": "consumer", "type": "app"},
+				},
+			})
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			body := DecodedRequestBody(c, r)
+			plug := body["plugs"].([]interface{})[0].(map[string]interface{})
+			slot := body["slots"].([]interface{})[0].(map[string]interface{})
+			*connects = append(*connects, fmt.Sprintf("%s:%s %s:%s", plug["snap"], plug["plug"], slot["snap"], slot["slot"]))
+			if slot["snap"] == "pulse" {
+				w.WriteHeader(400)
+				fmt.Fprintln(w, `{"type":"error", "status-code": 400, "result": {"message": "boom"}}`)
+				return
+			}
+			n++
+			w.WriteHeader(202)
+			fmt.Fprintf(w, `{"type":"async", "status-code": 202, "change": "%d"}`+"\n", n)
+		default:
+			if !strings.HasPrefix(r.URL.Path, "/v2/changes/") {
+				c.Fatalf("unexpected path %q", r.URL.Path)
+			}
+			c.Check(r.Method, Equals, "GET")
+			fmt.Fprintln(w, `{"type":"sync", "result":{"ready": true, "status": "Done"}}`)
+		}
+	})
+}
+
+func (s *SnapSuite) TestTryConnect(c *C) {
+	var connects []string
+	s.mockTryConnectServer(c, &connects)
+
+	rest, err := Parser(Client()).ParseArgs([]string{"try-connect", "consumer"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(connects, DeepEquals, []string{
+		"consumer:audio pulse:audio",
+		"consumer:network core:network",
+		"consumer:serial pc:ttyS0",
+	})
+	c.Check(s.Stdout(), Equals, ""+
+		"consumer:audio    not connected to pulse:audio: boom\n"+
+		"consumer:camera   not connected: no candidate slots\n"+
+		"consumer:content  not connected: more than one candidate slot: provider-a:content, provider-b:content\n"+
+		"consumer:network  connected to :network\n"+
+		"consumer:serial   connected to pc:ttyS0\n")
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestTryConnectNoWait(c *C) {
+	var connects []string
+	s.mockTryConnectServer(c, &connects)
+
+	_, err := Parser(Client()).ParseArgs([]string{"try-connect", "--no-wait", "consumer"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, ""+
+		"consumer:audio    not connected to pulse:audio: boom\n"+
+		"consumer:camera   not connected: no candidate slots\n"+
+		"consumer:content  not connected: more than one candidate slot: provider-a:content, provider-b:content\n"+
+		"consumer:network  connecting to :network in change 1\n"+
+		"consumer:serial   connecting to pc:ttyS0 in change 2\n")
+}
+
+func (s *SnapSuite) TestTryConnectNothingToDo(c *C) {
+	var connects []string
+	s.mockTryConnectServer(c, &connects)
+
+	_, err := Parser(Client()).ParseArgs([]string{"try-connect", "router"})
+	c.Assert(err, IsNil)
+	c.Check(connects, HasLen, 0)
+	c.Check(s.Stdout(), Equals, "No disconnected plugs in snap \"router\"\n")
+}
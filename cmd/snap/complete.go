@@ -203,6 +203,20 @@ func (s keyName) Complete(match string) []flags.Completion {
 
 type disconnectSlotOrPlugSpec struct {
 	SnapAndNameStrict
+	// strictErr is set when a bare snap name was given, which is only
+	// accepted when disconnecting everything from the snap
+	strictErr error
+}
+
+func (dps *disconnectSlotOrPlugSpec) UnmarshalFlag(value string) error {
+	dps.strictErr = nil
+	err := dps.SnapAndNameStrict.UnmarshalFlag(value)
+	if err != nil && value != "" && !strings.Contains(value, ":") {
+		dps.Snap = value
+		dps.strictErr = err
+		return nil
+	}
+	return err
 }
 
 func (dps disconnectSlotOrPlugSpec) Complete(match string) []flags.Completion {
@@ -249,9 +263,11 @@ func (cps connectPlugSpec) Complete(match string) []flags.Completion {
 
 type connectSlotSpec struct {
 	SnapAndName
+	// plug is the plug being connected, when set only slots of the
+	// same interface are offered
+	plug *SnapAndName
 }
 
-// TODO: look at what the previous arg is, and filter accordingly
 func (css connectSlotSpec) Complete(match string) []flags.Completion {
 	spec := &interfaceSpec{
 		SnapAndName:  css.SnapAndName,
@@ -260,9 +276,30 @@ func (css connectSlotSpec) Complete(match string) []flags.Completion {
 		connected:    false,
 		disconnected: true,
 	}
+	if css.plug != nil && css.plug.Snap != "" && css.plug.Name != "" {
+		spec.iface = plugInterface(css.plug.Snap, css.plug.Name)
+	}
 	return spec.Complete(match)
 }
 
+// plugInterface returns the interface of the given plug, or an empty string
+// if the plug is not known.
+func plugInterface(snapName, plugName string) string {
+	conns, err := mkClient().Connections(&client.ConnectionOptions{
+		Snap: snapName,
+		All:  true,
+	})
+	if err != nil {
+		return ""
+	}
+	for _, plug := range conns.Plugs {
+		if plug.Snap == snapName && plug.Name == plugName {
+			return plug.Interface
+		}
+	}
+	return ""
+}
+
 type interfacesSlotOrPlugSpec struct {
 	SnapAndName
 }
@@ -284,6 +321,9 @@ type interfaceSpec struct {
 	plugs        bool
 	connected    bool
 	disconnected bool
+	// iface, if set, restricts the plugs and slots to those of the
+	// given interface
+	iface string
 }
 
 func (spec *interfaceSpec) connFilter(numConns int) bool {
@@ -304,12 +344,16 @@ func (spec *interfaceSpec) Complete(match string) []flags.Completion {
 
 	// Ask snapd about available interfaces.
 	opts := client.ConnectionOptions{
-		All: true,
+		All:       true,
+		Interface: spec.iface,
 	}
 	ifaces, err := mkClient().Connections(&opts)
 	if err != nil {
 		return nil
 	}
+	if spec.iface != "" {
+		ifaces = connectionsWithInterface(ifaces, spec.iface)
+	}
 
 	snaps := make(map[string]bool)
 
@@ -394,6 +438,23 @@ func (spec *interfaceSpec) Complete(match string) []flags.Completion {
 	return ret
 }
 
+// connectionsWithInterface drops the plugs and slots of other interfaces,
+// older snapd may not support filtering by interface.
+func connectionsWithInterface(conns client.Connections, iface string) client.Connections {
+	var filtered client.Connections
+	for _, plug := range conns.Plugs {
+		if plug.Interface == iface {
+			filtered.Plugs = append(filtered.Plugs, plug)
+		}
+	}
+	for _, slot := range conns.Slots {
+		if slot.Interface == iface {
+			filtered.Slots = append(filtered.Slots, slot)
+		}
+	}
+	return filtered
+}
+
 type interfaceName string
 
 func (s interfaceName) Complete(match string) []flags.Completion {
@@ -0,0 +1,132 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+// termMakeRaw puts the terminal on stdin into raw mode, so that single key
+// presses can be read, and returns a function restoring its previous state.
+var termMakeRaw = func() (restore func(), err error) {
+	oldState, err := terminal.MakeRaw(0)
+	if err != nil {
+		return nil, err
+	}
+	return func() { terminal.Restore(0, oldState) }, nil
+}
+
+type pickerKey int
+
+const (
+	keyOther pickerKey = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyQuit
+)
+
+// readPickerKey reads a single key press. Besides the arrow keys, the vi
+// movement keys are understood, as arrow keys do not work on every console.
+func readPickerKey(r *bufio.Reader) (pickerKey, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return keyOther, err
+	}
+	switch b {
+	case '\r', '\n':
+		return keyEnter, nil
+	case 'q', 3, 4: // q, ^C, ^D
+		return keyQuit, nil
+	case 'k':
+		return keyUp, nil
+	case 'j':
+		return keyDown, nil
+	case 0x1b:
+		// ESC [ A and ESC [ B are sent for the up and down arrows
+		if next, err := r.ReadByte(); err != nil || next != '[' {
+			return keyOther, err
+		}
+		arrow, err := r.ReadByte()
+		if err != nil {
+			return keyOther, err
+		}
+		switch arrow {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		}
+	}
+	return keyOther, nil
+}
+
+// pickOne shows a menu with the given items below the title, and lets the
+// user move the selection with the arrow keys and choose with enter. The
+// index of the chosen item is returned. Output uses \r\n line endings as the
+// terminal is in raw mode.
+func pickOne(r *bufio.Reader, w io.Writer, title string, items []string) (int, error) {
+	selected := 0
+	draw := func() {
+		for i, item := range items {
+			marker := " "
+			if i == selected {
+				marker = ">"
+			}
+			// go to the start of the line and clear it before drawing
+			fmt.Fprintf(w, "\r\033[K%s %s\r\n", marker, item)
+		}
+	}
+
+	fmt.Fprintf(w, "%s\r\n", title)
+	draw()
+	for {
+		key, err := readPickerKey(r)
+		if err != nil {
+			return -1, err
+		}
+		switch key {
+		case keyEnter:
+			return selected, nil
+		case keyQuit:
+			return -1, errors.New(i18n.G("aborted"))
+		case keyUp:
+			if selected > 0 {
+				selected--
+			}
+		case keyDown:
+			if selected < len(items)-1 {
+				selected++
+			}
+		default:
+			continue
+		}
+		// move back up to the first item and redraw the menu
+		fmt.Fprintf(w, "\033[%dA", len(items))
+		draw()
+	}
+}
@@ -41,6 +41,7 @@ var (
 	Client = mkClient
 
 	FirstNonOptionIsRun = firstNonOptionIsRun
+	ExitCodeFromError   = exitCodeFromError
 
 	CreateUserDataDirs  = createUserDataDirs
 	ResolveApp          = resolveApp
@@ -441,3 +442,11 @@ func MockIsGraphicalSession(graphical bool) (restore func()) {
 		isGraphicalSession = old
 	}
 }
+
+func MockTermMakeRaw(f func() (restore func(), err error)) (restore func()) {
+	old := termMakeRaw
+	termMakeRaw = f
+	return func() {
+		termMakeRaw = old
+	}
+}
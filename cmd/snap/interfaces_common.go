@@ -20,9 +20,13 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
+	"gopkg.in/yaml.v2"
+
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
 )
 
@@ -84,3 +88,109 @@ func (sn *SnapAndNameStrict) UnmarshalFlag(value string) error {
 	sn.Snap, sn.Name = parts[0], parts[1]
 	return nil
 }
+
+// endpointRef is the machine readable form of a plug or slot reference.
+type endpointRef struct {
+	Snap string `json:"snap" yaml:"snap"`
+	Name string `json:"name" yaml:"name"`
+}
+
+// connectionOutput is the machine readable form of a connection, or of an
+// unconnected plug or slot.
+type connectionOutput struct {
+	Interface string       `json:"interface,omitempty" yaml:"interface,omitempty"`
+	Plug      *endpointRef `json:"plug,omitempty" yaml:"plug,omitempty"`
+	Slot      *endpointRef `json:"slot,omitempty" yaml:"slot,omitempty"`
+	Manual    bool         `json:"manual,omitempty" yaml:"manual,omitempty"`
+	Gadget    bool         `json:"gadget,omitempty" yaml:"gadget,omitempty"`
+}
+
+// changeConnections returns the connections recorded under the given key in
+// the data of a connect or disconnect change.
+func changeConnections(chg *client.Change, key string) ([]connectionOutput, error) {
+	var conns []client.Connection
+	if err := chg.Get(key, &conns); err != nil && err != client.ErrNoData {
+		return nil, err
+	}
+	out := make([]connectionOutput, 0, len(conns))
+	for _, conn := range conns {
+		out = append(out, connectionOutput{
+			Plug: &endpointRef{Snap: conn.Plug.Snap, Name: conn.Plug.Name},
+			Slot: &endpointRef{Snap: conn.Slot.Snap, Name: conn.Slot.Name},
+		})
+	}
+	return out, nil
+}
+
+// writeFormatted writes v to stdout in the given machine readable format.
+func writeFormatted(format string, v interface{}) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		enc := yaml.NewEncoder(Stdout)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// errorOutput is the machine readable form of the error of an interface
+// command.
+type errorOutput struct {
+	Error struct {
+		Kind     string `json:"kind,omitempty" yaml:"kind,omitempty"`
+		Message  string `json:"message" yaml:"message"`
+		ExitCode int    `json:"exit-code" yaml:"exit-code"`
+	} `json:"error" yaml:"error"`
+}
+
+// isInterfacesUnchanged returns whether err only reports that an interface
+// command had nothing to do, which is not a failure of the command.
+func isInterfacesUnchanged(err error) bool {
+	e, ok := err.(*client.Error)
+	return ok && e.Kind == client.ErrorKindInterfacesUnchanged
+}
+
+// writeFormattedError writes err to stdout in the given machine readable
+// format. The error is returned as is so that it still sets the exit code of
+// the command, unless it only reports that nothing changed.
+func writeFormattedError(format string, err error) error {
+	var out errorOutput
+	if e, ok := err.(*client.Error); ok {
+		out.Error.Kind = string(e.Kind)
+	}
+	out.Error.Message = err.Error()
+	if !isInterfacesUnchanged(err) {
+		out.Error.ExitCode = exitCodeFromError(err)
+	}
+	if werr := writeFormatted(format, &out); werr != nil {
+		return werr
+	}
+	if isInterfacesUnchanged(err) {
+		return nil
+	}
+	return err
+}
+
+// reportInterfacesError reports the error of an interface command in the
+// given output format. When nothing changed the pretty output only says so
+// and the command succeeds.
+func reportInterfacesError(format string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if format != "pretty" {
+		return writeFormattedError(format, err)
+	}
+	if isInterfacesUnchanged(err) {
+		fmt.Fprintln(Stdout, err.Error())
+		return nil
+	}
+	return err
+}
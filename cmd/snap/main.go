@@ -417,12 +417,20 @@ func resolveApp(snapApp string) (string, error) {
 	return snapApp, nil
 }
 
+// interfacesExitCodes are the documented exit codes of the failures of
+// interface commands that scripts are expected to tell apart.
+var interfacesExitCodes = map[client.ErrorKind]int{
+	client.ErrorKindInterfacesPlugOrSlotNotFound:   31,
+	client.ErrorKindInterfacesConnectionNotAllowed: 32,
+}
+
 // exitCodeFromError takes an error and returns specific exit codes
 // for some errors. Otherwise the generic exit code 1 is returned.
 func exitCodeFromError(err error) int {
 	var mksquashfsError squashfs.MksquashfsError
 	var cmdlineFlagsError *flags.Error
 	var unknownCmdError unknownCommandError
+	var clientError *client.Error
 
 	switch {
 	case err == nil:
@@ -431,6 +439,8 @@ func exitCodeFromError(err error) int {
 		return 10
 	case xerrors.As(err, &mksquashfsError):
 		return 20
+	case xerrors.As(err, &clientError) && interfacesExitCodes[clientError.Kind] != 0:
+		return interfacesExitCodes[clientError.Kind]
 	case xerrors.As(err, &cmdlineFlagsError) || xerrors.As(err, &unknownCmdError):
 		// EX_USAGE, see sysexit.h
 		return 64
@@ -20,6 +20,10 @@
 package daemon
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 
@@ -62,6 +66,19 @@ func checkPolkitActionImpl(r *http.Request, ucred *ucrednet, action string) *api
 	return Unauthorized("access denied")
 }
 
+const (
+	// scopeRead allows only what doesn't need authentication.
+	scopeRead = "read"
+	// scopeManage allows everything authenticated users can do.
+	scopeManage = "manage"
+	// scopeManageInterfaces allows connecting and disconnecting
+	// interfaces.
+	scopeManageInterfaces = "manage-interfaces"
+)
+
+// validScopes are the scopes a user can be restricted to.
+var validScopes = []string{scopeRead, scopeManage, scopeManageInterfaces}
+
 // accessChecker checks whether a particular request is allowed.
 //
 // An access checker will either allow a request, deny it, or return
@@ -97,9 +114,11 @@ func (ac openAccess) CheckAccess(d *Daemon, r *http.Request, ucred *ucrednet, us
 //
 // A user is considered authenticated if they provide a macaroon, are
 // the root user according to peer credentials, or granted access by
-// Polkit.
+// Polkit. Users other than root with a macaroon restricted to scopes
+// must have been granted the manage scope, unless AnyScope is set.
 type authenticatedAccess struct {
-	Polkit string
+	Polkit   string
+	AnyScope bool
 }
 
 func (ac authenticatedAccess) CheckAccess(d *Daemon, r *http.Request, ucred *ucrednet, user *auth.UserState) *apiError {
@@ -107,11 +126,15 @@ func (ac authenticatedAccess) CheckAccess(d *Daemon, r *http.Request, ucred *ucr
 		return rspe
 	}
 
-	if user != nil {
+	// root is not restricted by the scopes of its macaroon
+	if ucred.Uid == 0 {
 		return nil
 	}
 
-	if ucred.Uid == 0 {
+	if user != nil {
+		if !ac.AnyScope && !user.HasScope(scopeManage) {
+			return Forbidden("access denied")
+		}
 		return nil
 	}
 
@@ -125,6 +148,67 @@ func (ac authenticatedAccess) CheckAccess(d *Daemon, r *http.Request, ucred *ucr
 	return Unauthorized("access denied")
 }
 
+// interfacesAuthenticatedAccess behaves like authenticatedAccess, but
+// the polkit action that is checked depends on the interfaces' action
+// requested in the body, so that connecting and disconnecting can be
+// authorized separately.
+type interfacesAuthenticatedAccess struct{}
+
+func (ac interfacesAuthenticatedAccess) CheckAccess(d *Daemon, r *http.Request, ucred *ucrednet, user *auth.UserState) *apiError {
+	if rspe := requireSnapdSocket(ucred); rspe != nil {
+		return rspe
+	}
+
+	if ucred.Uid == 0 {
+		return nil
+	}
+
+	if user != nil {
+		if !user.HasScope(scopeManage) && !user.HasScope(scopeManageInterfaces) {
+			return Forbidden("access denied")
+		}
+		return nil
+	}
+
+	return checkPolkitAction(r, ucred, polkitActionForInterfacesRequest(r))
+}
+
+// maxInterfacesActionBodySize is the maximum size of an interfaces' request
+// body that is inspected to pick the polkit action.
+const maxInterfacesActionBodySize = 1024 * 1024
+
+// polkitActionForInterfacesRequest returns the polkit action matching the
+// interfaces' action of the request. The body of the request is preserved
+// for the handler. Bodies larger than maxInterfacesActionBodySize are not
+// inspected and require the generic action.
+func polkitActionForInterfacesRequest(r *http.Request) string {
+	if r.Body == nil {
+		return polkitActionManageInterfaces
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxInterfacesActionBodySize+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || len(body) > maxInterfacesActionBodySize {
+		return polkitActionManageInterfaces
+	}
+
+	var a struct {
+		Action string `json:"action"`
+	}
+	if err := json.Unmarshal(body, &a); err != nil {
+		return polkitActionManageInterfaces
+	}
+	switch a.Action {
+	case "connect":
+		return polkitActionConnect
+	case "disconnect":
+		return polkitActionDisconnect
+	}
+	return polkitActionManageInterfaces
+}
+
 // rootAccess allows requests from the root uid, provided they
 // were not received on snapd-snap.socket
 type rootAccess struct{}
@@ -227,11 +311,14 @@ func (ac themesAuthenticatedAccess) CheckAccess(d *Daemon, r *http.Request, ucre
 
 	// check as well that we have admin permission to proceed with
 	// the theme operation
-	if user != nil {
+	if ucred.Uid == 0 {
 		return nil
 	}
 
-	if ucred.Uid == 0 {
+	if user != nil {
+		if !user.HasScope(scopeManage) {
+			return Forbidden("access denied")
+		}
 		return nil
 	}
 
@@ -21,8 +21,10 @@ package daemon_test
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	. "gopkg.in/check.v1"
 
@@ -99,6 +101,43 @@ func (s *accessSuite) TestAuthenticatedAccess(c *C) {
 	c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
 }
 
+func (s *accessSuite) TestAuthenticatedAccessScopes(c *C) {
+	restore := daemon.MockCheckPolkitAction(func(r *http.Request, ucred *daemon.Ucrednet, action string) *daemon.APIError {
+		c.Fail()
+		return daemon.Forbidden("access denied")
+	})
+	defer restore()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	ucred := &daemon.Ucrednet{Uid: 42, Pid: 100, Socket: dirs.SnapdSocket}
+
+	// users restricted to scopes need the manage scope
+	var ac daemon.AccessChecker = daemon.AuthenticatedAccess{}
+	c.Check(ac.CheckAccess(nil, req, ucred, &auth.UserState{Scopes: []string{"read"}}), DeepEquals, errForbidden)
+	c.Check(ac.CheckAccess(nil, req, ucred, &auth.UserState{Scopes: []string{"manage-interfaces"}}), DeepEquals, errForbidden)
+	c.Check(ac.CheckAccess(nil, req, ucred, &auth.UserState{Scopes: []string{"read", "manage"}}), IsNil)
+
+	// unless any scope is fine
+	ac = daemon.AuthenticatedAccess{AnyScope: true}
+	c.Check(ac.CheckAccess(nil, req, ucred, &auth.UserState{Scopes: []string{"read"}}), IsNil)
+
+	// the interfaces access also accepts the manage-interfaces scope
+	ac = daemon.InterfacesAuthenticatedAccess{}
+	c.Check(ac.CheckAccess(nil, req, ucred, &auth.UserState{Scopes: []string{"read"}}), DeepEquals, errForbidden)
+	c.Check(ac.CheckAccess(nil, req, ucred, &auth.UserState{Scopes: []string{"manage-interfaces"}}), IsNil)
+	c.Check(ac.CheckAccess(nil, req, ucred, &auth.UserState{Scopes: []string{"manage"}}), IsNil)
+
+	// root is not restricted by the scopes
+	ucred = &daemon.Ucrednet{Uid: 0, Pid: 100, Socket: dirs.SnapdSocket}
+	for _, ac := range []daemon.AccessChecker{
+		daemon.AuthenticatedAccess{},
+		daemon.InterfacesAuthenticatedAccess{},
+		daemon.ThemesAuthenticatedAccess{},
+	} {
+		c.Check(ac.CheckAccess(nil, req, ucred, &auth.UserState{Scopes: []string{"read"}}), IsNil)
+	}
+}
+
 func (s *accessSuite) TestAuthenticatedAccessPolkit(c *C) {
 	var ac daemon.AccessChecker = daemon.AuthenticatedAccess{Polkit: "action-id"}
 
@@ -131,6 +170,58 @@ func (s *accessSuite) TestAuthenticatedAccessPolkit(c *C) {
 	c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
 }
 
+func (s *accessSuite) TestInterfacesAuthenticatedAccess(c *C) {
+	var ac daemon.AccessChecker = daemon.InterfacesAuthenticatedAccess{}
+
+	user := &auth.UserState{}
+	ucred := &daemon.Ucrednet{Uid: 0, Pid: 100, Socket: dirs.SnapdSocket}
+
+	// polkit is not checked if any of:
+	//   * ucred is missing or from snapd-snap.socket
+	//   * macaroon auth is provided
+	//   * user is root
+	restore := daemon.MockCheckPolkitAction(func(r *http.Request, ucred *daemon.Ucrednet, action string) *daemon.APIError {
+		c.Fail()
+		return daemon.Forbidden("access denied")
+	})
+	defer restore()
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"action":"connect"}`))
+	c.Check(ac.CheckAccess(nil, req, nil, nil), DeepEquals, errForbidden)
+	c.Check(ac.CheckAccess(nil, req, &daemon.Ucrednet{Uid: 0, Pid: 100, Socket: dirs.SnapSocket}, nil), DeepEquals, errForbidden)
+	c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
+	ucred = &daemon.Ucrednet{Uid: 42, Pid: 100, Socket: dirs.SnapdSocket}
+	c.Check(ac.CheckAccess(nil, req, ucred, user), IsNil)
+
+	// for regular users the polkit action depends on the requested
+	// action, and the body is preserved for the handler
+	for _, t := range []struct {
+		body   string
+		action string
+	}{
+		{`{"action":"connect","plugs":[{"snap":"a","plug":"b"}]}`, "io.snapcraft.snapd.manage-interfaces.connect"},
+		{`{"action":"disconnect","forget":true}`, "io.snapcraft.snapd.manage-interfaces.disconnect"},
+		{`{"action":"frobnicate"}`, "io.snapcraft.snapd.manage-interfaces"},
+		{`garbage`, "io.snapcraft.snapd.manage-interfaces"},
+		// too large to be inspected
+		{`{"action":"connect","pad":"` + strings.Repeat("x", 1024*1024) + `"}`, "io.snapcraft.snapd.manage-interfaces"},
+	} {
+		var checked string
+		restore = daemon.MockCheckPolkitAction(func(r *http.Request, u *daemon.Ucrednet, action string) *daemon.APIError {
+			c.Check(u, Equals, ucred)
+			checked = action
+			return nil
+		})
+		req := httptest.NewRequest("POST", "/", strings.NewReader(t.body))
+		c.Check(ac.CheckAccess(nil, req, ucred, nil), IsNil)
+		restore()
+		c.Check(checked, Equals, t.action, Commentf("%.40s", t.body))
+
+		body, err := ioutil.ReadAll(req.Body)
+		c.Assert(err, IsNil)
+		c.Check(string(body), Equals, t.body)
+	}
+}
+
 func (s *accessSuite) TestCheckPolkitActionImpl(c *C) {
 	logbuf, restore := logger.MockLogger()
 	defer restore()
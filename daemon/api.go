@@ -29,6 +29,7 @@ import (
 
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/strutil"
@@ -47,6 +48,7 @@ var api = []*Command{
 	snapDownloadCmd,
 	snapConfCmd,
 	interfacesCmd,
+	interfaceEventsCmd,
 	assertsCmd,
 	assertsFindManyCmd,
 	stateChangeCmd,
@@ -66,6 +68,9 @@ var api = []*Command{
 	snapshotCmd,
 	snapshotExportCmd,
 	connectionsCmd,
+	connectionsProfileCmd,
+	connectionsHistoryCmd,
+	snapConnectionsCmd,
 	modelCmd,
 	cohortsCmd,
 	serialModelCmd,
@@ -84,6 +89,8 @@ const (
 	polkitActionLogin            = "io.snapcraft.snapd.login"
 	polkitActionManage           = "io.snapcraft.snapd.manage"
 	polkitActionManageInterfaces = "io.snapcraft.snapd.manage-interfaces"
+	polkitActionConnect          = "io.snapcraft.snapd.manage-interfaces.connect"
+	polkitActionDisconnect       = "io.snapcraft.snapd.manage-interfaces.disconnect"
 )
 
 // userFromRequest extracts user information from request and return the respective user in state, if valid
@@ -145,6 +152,8 @@ var (
 
 	assertstateRefreshSnapAssertions         = assertstate.RefreshSnapAssertions
 	assertstateRestoreValidationSetsTracking = assertstate.RestoreValidationSetsTracking
+
+	ifacestateCheckConnectPolicy = ifacestate.CheckConnectPolicy
 )
 
 func ensureStateSoonImpl(st *state.State) {
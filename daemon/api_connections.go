@@ -30,16 +30,26 @@ import (
 	"github.com/snapcore/snapd/overlord/state"
 )
 
-var connectionsCmd = &Command{
-	Path:       "/v2/connections",
-	GET:        getConnections,
-	ReadAccess: openAccess{},
-}
+var (
+	connectionsCmd = &Command{
+		Path:       "/v2/connections",
+		GET:        getConnections,
+		ReadAccess: openAccess{},
+	}
+
+	snapConnectionsCmd = &Command{
+		Path:       "/v2/snaps/{name}/connections",
+		GET:        getSnapConnections,
+		ReadAccess: openAccess{},
+	}
+)
 
 type collectFilter struct {
 	snapName  string
 	ifaceName string
 	connected bool
+	// unconnected selects only plugs and slots without connections
+	unconnected bool
 }
 
 func (c *collectFilter) plugOrConnectedSlotMatches(plug *interfaces.PlugRef, connectedSlots []interfaces.SlotRef) bool {
@@ -167,6 +177,9 @@ func collectConnections(ifaceMgr *ifacestate.InterfaceManager, filter collectFil
 			SlotAttrs: mergeAttrs(cstate.StaticSlotAttrs, cstate.DynamicSlotAttrs),
 		}
 		if cstate.Undesired {
+			if filter.unconnected {
+				continue
+			}
 			// explicitly disconnected are always manual
 			cj.Manual = true
 			connsjson.Undesired = append(connsjson.Undesired, cj)
@@ -174,6 +187,9 @@ func collectConnections(ifaceMgr *ifacestate.InterfaceManager, filter collectFil
 			plugConns[plugID] = append(plugConns[plugID], slotRef)
 			slotConns[slotID] = append(slotConns[slotID], plugRef)
 
+			if filter.unconnected {
+				continue
+			}
 			connsjson.Established = append(connsjson.Established, cj)
 		}
 	}
@@ -184,6 +200,9 @@ func collectConnections(ifaceMgr *ifacestate.InterfaceManager, filter collectFil
 		if !connected && filter.connected {
 			continue
 		}
+		if connected && filter.unconnected {
+			continue
+		}
 		if !filter.ifaceMatches(plug.Interface) || !filter.plugOrConnectedSlotMatches(&plugRef, connectedSlots) {
 			continue
 		}
@@ -209,6 +228,9 @@ func collectConnections(ifaceMgr *ifacestate.InterfaceManager, filter collectFil
 		if !connected && filter.connected {
 			continue
 		}
+		if connected && filter.unconnected {
+			continue
+		}
 		if !filter.ifaceMatches(slot.Interface) || !filter.slotOrConnectedPlugMatches(&slotRef, connectedPlugs) {
 			continue
 		}
@@ -253,14 +275,24 @@ func checkSnapInstalled(st *state.State, name string) error {
 }
 
 func getConnections(c *Command, r *http.Request, user *auth.UserState) Response {
+	return connectionsResponse(c, r, r.URL.Query().Get("snap"))
+}
+
+// getSnapConnections returns the plugs and slots of a single snap, along with
+// the connections they are part of.
+func getSnapConnections(c *Command, r *http.Request, user *auth.UserState) Response {
+	return connectionsResponse(c, r, muxVars(r)["name"])
+}
+
+func connectionsResponse(c *Command, r *http.Request, snapName string) Response {
 	query := r.URL.Query()
-	snapName := query.Get("snap")
 	ifaceName := query.Get("interface")
 	qselect := query.Get("select")
-	if qselect != "all" && qselect != "" {
+	if qselect != "all" && qselect != "unconnected" && qselect != "" {
 		return BadRequest("unsupported select qualifier")
 	}
 	onlyConnected := qselect == ""
+	onlyUnconnected := qselect == "unconnected"
 
 	snapName = ifacestate.RemapSnapFromRequest(snapName)
 	if snapName != "" {
@@ -273,9 +305,10 @@ func getConnections(c *Command, r *http.Request, user *auth.UserState) Response
 	}
 
 	connsjson, err := collectConnections(c.d.overlord.InterfaceManager(), collectFilter{
-		snapName:  snapName,
-		ifaceName: ifaceName,
-		connected: onlyConnected,
+		snapName:    snapName,
+		ifaceName:   ifaceName,
+		connected:   onlyConnected,
+		unconnected: onlyUnconnected,
 	})
 	if err != nil {
 		return InternalError("collecting connection information failed: %v", err)
@@ -0,0 +1,90 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var connectionsHistoryCmd = &Command{
+	Path:        "/v2/connections/history",
+	GET:         getConnectionsHistory,
+	POST:        postConnectionsHistory,
+	ReadAccess:  openAccess{},
+	WriteAccess: authenticatedAccess{},
+}
+
+func getConnectionsHistory(c *Command, r *http.Request, user *auth.UserState) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	history, err := ifacestate.ConnectionsHistory(st)
+	if err != nil {
+		return InternalError("%v", err)
+	}
+	if history == nil {
+		history = []ifacestate.ConnectionsSnapshot{}
+	}
+	return SyncResponse(history)
+}
+
+type connectionsHistoryAction struct {
+	Action string `json:"action"`
+	// Time selects the connections as they were at that time, by default
+	// the connections are reverted to what they were before the last
+	// change that modified them.
+	Time time.Time `json:"time,omitempty"`
+}
+
+func postConnectionsHistory(c *Command, r *http.Request, user *auth.UserState) Response {
+	var a connectionsHistoryAction
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&a); err != nil {
+		return BadRequest("cannot decode request body into a connections history action: %v", err)
+	}
+	if a.Action != "revert" {
+		return BadRequest("unsupported connections history action: %q", a.Action)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	ts, err := ifacestate.RevertConnections(st, c.d.overlord.InterfaceManager().Repository(), a.Time)
+	if err == ifacestate.ErrNothingToRevert {
+		return InterfacesUnchanged("%v", err)
+	}
+	if err != nil {
+		return errToResponse(err, nil, BadRequest, "cannot revert connections: %v")
+	}
+
+	change := newChange(st, "revert-connections", i18n.G("Revert connections"), []*state.TaskSet{ts}, nil)
+	st.EnsureBefore(0)
+
+	return AsyncResponse(nil, change.ID())
+}
@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+)
+
+var _ = Suite(&connectionsHistorySuite{})
+
+type connectionsHistorySuite struct {
+	apiBaseSuite
+}
+
+var connsHistory = []interface{}{
+	map[string]interface{}{
+		"time":      "2021-06-01T10:00:00Z",
+		"change-id": "1",
+		"conns": map[string]interface{}{
+			"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+		},
+	},
+	map[string]interface{}{
+		"time":      "2021-06-02T10:00:00Z",
+		"change-id": "2",
+		"conns":     map[string]interface{}{},
+	},
+}
+
+func (s *connectionsHistorySuite) TestHistory(c *C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("conns-history", connsHistory)
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/connections/history", nil)
+	c.Assert(err, IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, DeepEquals, []ifacestate.ConnectionsSnapshot{{
+		Time:        time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+		ChangeID:    "1",
+		Connections: []string{"consumer:plug producer:slot"},
+	}, {
+		Time:        time.Date(2021, 6, 2, 10, 0, 0, 0, time.UTC),
+		ChangeID:    "2",
+		Connections: []string{},
+	}})
+}
+
+func (s *connectionsHistorySuite) TestHistoryEmpty(c *C) {
+	s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/connections/history", nil)
+	c.Assert(err, IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, DeepEquals, []ifacestate.ConnectionsSnapshot{})
+}
+
+func (s *connectionsHistorySuite) postAction(c *C, action map[string]interface{}) *http.Request {
+	text, err := json.Marshal(action)
+	c.Assert(err, IsNil)
+	req, err := http.NewRequest("POST", "/v2/connections/history", bytes.NewBuffer(text))
+	c.Assert(err, IsNil)
+	return req
+}
+
+func (s *connectionsHistorySuite) TestRevert(c *C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("conns-history", connsHistory)
+	st.Unlock()
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	rsp := s.asyncReq(c, s.postAction(c, map[string]interface{}{
+		"action": "revert",
+		"time":   "2021-05-31T12:00:00Z",
+	}), nil)
+
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, NotNil)
+	c.Check(chg.Kind(), Equals, "revert-connections")
+	c.Check(chg.Summary(), Equals, "Revert connections")
+	var kinds []string
+	for _, t := range chg.Tasks() {
+		kinds = append(kinds, t.Kind())
+	}
+	c.Check(kinds, DeepEquals, []string{"connect"})
+}
+
+func (s *connectionsHistorySuite) TestRevertErrors(c *C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("conns-history", connsHistory)
+	st.Unlock()
+
+	for _, t := range []struct {
+		action map[string]interface{}
+		kind   client.ErrorKind
+		err    string
+	}{
+		{map[string]interface{}{"action": "forget"}, "", `unsupported connections history action: "forget"`},
+		{map[string]interface{}{"action": "revert", "time": "yesterday"}, "", `cannot decode request body into a connections history action: .*`},
+		// the connections are already as they were before the last change
+		{map[string]interface{}{"action": "revert"}, client.ErrorKindInterfacesUnchanged, `connections are already in the requested state`},
+	} {
+		rspe := s.errorReq(c, s.postAction(c, t.action), nil)
+		c.Check(rspe.Status, Equals, 400)
+		c.Check(rspe.Kind, Equals, t.kind)
+		c.Check(rspe.Message, Matches, t.err)
+	}
+}
@@ -0,0 +1,171 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var connectionsProfileCmd = &Command{
+	Path:        "/v2/connections/profile",
+	GET:         getConnectionsProfile,
+	POST:        postConnectionsProfile,
+	ReadAccess:  openAccess{},
+	WriteAccess: authenticatedAccess{},
+}
+
+func getConnectionsProfile(c *Command, r *http.Request, user *auth.UserState) Response {
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	profile, err := ifacestate.ExportConnections(st)
+	if err != nil {
+		return InternalError("cannot export connections: %v", err)
+	}
+	return SyncResponse(profile)
+}
+
+type connectionsProfileAction struct {
+	Action  string                         `json:"action"`
+	Profile *ifacestate.ConnectionsProfile `json:"profile"`
+	// Replace makes the connections match the profile, otherwise the
+	// connections of the profile are merged with the existing ones.
+	Replace bool `json:"replace,omitempty"`
+	// Assertion is the encoded connection-profile assertion to apply.
+	Assertion string `json:"assertion,omitempty"`
+}
+
+func postConnectionsProfile(c *Command, r *http.Request, user *auth.UserState) Response {
+	var a connectionsProfileAction
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&a); err != nil {
+		return BadRequest("cannot decode request body into a connections profile action: %v", err)
+	}
+	switch a.Action {
+	case "import":
+		return importConnectionsProfile(c, &a)
+	case "apply":
+		return applyConnectionProfile(c, &a)
+	default:
+		return BadRequest("unsupported connections profile action: %q", a.Action)
+	}
+}
+
+func importConnectionsProfile(c *Command, a *connectionsProfileAction) Response {
+	if a.Profile == nil {
+		return BadRequest("connections profile not specified")
+	}
+	strategy := ifacestate.ImportMerge
+	if a.Replace {
+		strategy = ifacestate.ImportReplace
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	ts, err := ifacestate.ImportConnections(st, c.d.overlord.InterfaceManager().Repository(), a.Profile, strategy)
+	if err != nil {
+		return errToResponse(err, nil, BadRequest, "%v")
+	}
+
+	return connectionsProfileChange(st, "import-connections", i18n.G("Import connections profile"), ts)
+}
+
+// addConnectionProfile verifies the given connection-profile assertion
+// and adds it to the assertion database, the verified assertion is
+// returned.
+func addConnectionProfile(st *state.State, prof *asserts.ConnectionProfile) (*asserts.ConnectionProfile, error) {
+	err := assertstate.Add(st, prof)
+	if revErr, ok := err.(*asserts.RevisionError); ok {
+		if revErr.Current != prof.Revision() {
+			return nil, fmt.Errorf("cannot apply connection profile %q: revision %d is not the current one", prof.Name(), prof.Revision())
+		}
+		// already known, use the verified one
+		a, err := assertstate.DB(st).Find(asserts.ConnectionProfileType, map[string]string{
+			"brand-id": prof.BrandID(),
+			"model":    prof.Model(),
+			"name":     prof.Name(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return a.(*asserts.ConnectionProfile), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify connection profile %q: %v", prof.Name(), err)
+	}
+	return prof, nil
+}
+
+func applyConnectionProfile(c *Command, a *connectionsProfileAction) Response {
+	if a.Assertion == "" {
+		return BadRequest("connection profile assertion not specified")
+	}
+	as, err := asserts.Decode([]byte(a.Assertion))
+	if err != nil {
+		return BadRequest("cannot decode connection profile assertion: %v", err)
+	}
+	prof, ok := as.(*asserts.ConnectionProfile)
+	if !ok {
+		return BadRequest("cannot apply %s assertion as a connection profile", as.Type().Name)
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	deviceCtx, err := snapstate.DeviceCtxFromState(st, nil)
+	if err != nil {
+		return errToResponse(err, nil, InternalError, "%v")
+	}
+	prof, err = addConnectionProfile(st, prof)
+	if err != nil {
+		return BadRequest("%v", err)
+	}
+	ts, err := ifacestate.ApplyConnectionProfile(st, c.d.overlord.InterfaceManager().Repository(), prof, deviceCtx)
+	if err != nil {
+		return errToResponse(err, nil, BadRequest, "%v")
+	}
+
+	return connectionsProfileChange(st, "apply-connection-profile", fmt.Sprintf(i18n.G("Apply connection profile %q"), prof.Name()), ts)
+}
+
+func connectionsProfileChange(st *state.State, kind, summary string, ts *state.TaskSet) Response {
+	change := newChange(st, kind, summary, []*state.TaskSet{ts}, nil)
+	if len(ts.Tasks()) == 0 {
+		change.SetStatus(state.DoneStatus)
+	} else {
+		st.EnsureBefore(0)
+	}
+
+	return AsyncResponse(nil, change.ID())
+}
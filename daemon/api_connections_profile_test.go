@@ -0,0 +1,244 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/assertstate/assertstatetest"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var _ = Suite(&connectionsProfileSuite{})
+
+type connectionsProfileSuite struct {
+	apiBaseSuite
+}
+
+func (s *connectionsProfileSuite) exportProfile(c *C, st *state.State, conns map[string]interface{}) *ifacestate.ConnectionsProfile {
+	st.Lock()
+	defer st.Unlock()
+	st.Set("conns", conns)
+	profile, err := ifacestate.ExportConnections(st)
+	c.Assert(err, IsNil)
+	return profile
+}
+
+func (s *connectionsProfileSuite) TestExport(c *C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	expected := s.exportProfile(c, st, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test", "auto": true, "undesired": true},
+	})
+
+	req, err := http.NewRequest("GET", "/v2/connections/profile", nil)
+	c.Assert(err, IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, DeepEquals, expected)
+	c.Check(expected.Connections, DeepEquals, map[string]*ifacestate.ProfileConnection{
+		"consumer:plug producer:slot": {Interface: "test", Auto: true, Undesired: true},
+	})
+}
+
+func (s *connectionsProfileSuite) postAction(c *C, action map[string]interface{}) *http.Request {
+	text, err := json.Marshal(action)
+	c.Assert(err, IsNil)
+	req, err := http.NewRequest("POST", "/v2/connections/profile", bytes.NewBuffer(text))
+	c.Assert(err, IsNil)
+	return req
+}
+
+func (s *connectionsProfileSuite) TestImport(c *C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	st := d.Overlord().State()
+	profile := s.exportProfile(c, st, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	st.Lock()
+	st.Set("conns", nil)
+	st.Unlock()
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	rsp := s.asyncReq(c, s.postAction(c, map[string]interface{}{
+		"action":  "import",
+		"profile": profile,
+	}), nil)
+
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, NotNil)
+	c.Check(chg.Kind(), Equals, "import-connections")
+	var kinds []string
+	for _, t := range chg.Tasks() {
+		kinds = append(kinds, t.Kind())
+	}
+	c.Check(kinds, DeepEquals, []string{"connect"})
+}
+
+func (s *connectionsProfileSuite) TestImportNothingToDo(c *C) {
+	d := s.daemon(c)
+	profile := s.exportProfile(c, d.Overlord().State(), nil)
+
+	rsp := s.asyncReq(c, s.postAction(c, map[string]interface{}{
+		"action":  "import",
+		"profile": profile,
+		"replace": true,
+	}), nil)
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, NotNil)
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+}
+
+func (s *connectionsProfileSuite) TestImportErrors(c *C) {
+	d := s.daemon(c)
+	profile := s.exportProfile(c, d.Overlord().State(), nil)
+	profile.Checksum = "1234"
+
+	for _, t := range []struct {
+		action map[string]interface{}
+		err    string
+	}{
+		{map[string]interface{}{"action": "export"}, `unsupported connections profile action: "export"`},
+		{map[string]interface{}{"action": "import"}, `connections profile not specified`},
+		{map[string]interface{}{"action": "import", "profile": profile}, `cannot import connections profile: checksum mismatch`},
+	} {
+		rspe := s.errorReq(c, s.postAction(c, t.action), nil)
+		c.Check(rspe.Status, Equals, 400)
+		c.Check(rspe.Message, Matches, t.err)
+	}
+}
+
+func (s *connectionsProfileSuite) signConnectionProfile(c *C, revision string) asserts.Assertion {
+	prof, err := s.StoreSigning.Sign(asserts.ConnectionProfileType, map[string]interface{}{
+		"brand-id": "can0nical",
+		"model":    "pc",
+		"name":     "default",
+		"revision": revision,
+		"connect": []interface{}{
+			map[string]interface{}{"plug": "consumer:plug", "slot": "producer:slot", "interface": "test"},
+		},
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	return prof
+}
+
+func (s *connectionsProfileSuite) TestApply(c *C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	st := d.Overlord().State()
+	st.Lock()
+	assertstatetest.AddMany(st, s.StoreSigning.StoreAccountKey(""))
+	st.Unlock()
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	prof := s.signConnectionProfile(c, "1")
+	// applying a known profile again is fine
+	for i := 0; i < 2; i++ {
+		rsp := s.asyncReq(c, s.postAction(c, map[string]interface{}{
+			"action":    "apply",
+			"assertion": string(asserts.Encode(prof)),
+		}), nil)
+
+		st.Lock()
+		chg := st.Change(rsp.Change)
+		c.Assert(chg, NotNil)
+		c.Check(chg.Kind(), Equals, "apply-connection-profile")
+		c.Check(chg.Summary(), Equals, `Apply connection profile "default"`)
+		c.Check(chg.Tasks(), Not(HasLen), 0)
+		chg.Abort()
+		st.Unlock()
+	}
+}
+
+func (s *connectionsProfileSuite) TestApplyErrors(c *C) {
+	d := s.daemon(c)
+	st := d.Overlord().State()
+	st.Lock()
+	assertstatetest.AddMany(st, s.StoreSigning.StoreAccountKey(""))
+	st.Unlock()
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	prof := s.signConnectionProfile(c, "2")
+	rsp := s.asyncReq(c, s.postAction(c, map[string]interface{}{
+		"action":    "apply",
+		"assertion": string(asserts.Encode(prof)),
+	}), nil)
+	c.Check(rsp.Change, Not(Equals), "")
+
+	// the key of the brand is not known
+	unverified, err := s.Brands.Signing("my-brand").Sign(asserts.ConnectionProfileType, map[string]interface{}{
+		"brand-id":  "my-brand",
+		"model":     "pc",
+		"name":      "other",
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	model := s.Brands.Model("my-brand", "my-model", modelDefaults)
+
+	for _, t := range []struct {
+		assertion string
+		err       string
+	}{
+		{"", `connection profile assertion not specified`},
+		{"garbage", `cannot decode connection profile assertion: .*`},
+		{string(asserts.Encode(model)), `cannot apply model assertion as a connection profile`},
+		{string(asserts.Encode(unverified)), `cannot verify connection profile "other": .*`},
+		{string(asserts.Encode(s.signConnectionProfile(c, "1"))), `cannot apply connection profile "default": revision 1 is not the current one`},
+	} {
+		rspe := s.errorReq(c, s.postAction(c, map[string]interface{}{
+			"action":    "apply",
+			"assertion": t.assertion,
+		}), nil)
+		c.Check(rspe.Status, Equals, 400)
+		c.Check(rspe.Message, Matches, t.err)
+	}
+}
@@ -307,6 +307,8 @@ func getDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		return getChangeTimings(st, chgID, ensureTag, startupTag, all == "true")
 	case "seeding":
 		return getSeedingInfo(st)
+	case "interfaces":
+		return getInterfacesHealth(st, c.d.overlord.InterfaceManager())
 	default:
 		return BadRequest("unknown debug aspect %q", aspect)
 	}
@@ -0,0 +1,121 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// securityTaskKinds are the kinds of tasks that change the connections or
+// the security profiles of snaps.
+var securityTaskKinds = map[string]bool{
+	"connect":             true,
+	"disconnect":          true,
+	"setup-profiles":      true,
+	"remove-profiles":     true,
+	"auto-connect":        true,
+	"auto-disconnect":     true,
+	"hotplug-connect":     true,
+	"hotplug-disconnect":  true,
+	"hotplug-add-slot":    true,
+	"hotplug-update-slot": true,
+	"hotplug-remove-slot": true,
+}
+
+// maxInterfacesHealthFailures is the number of most recent failures reported.
+const maxInterfacesHealthFailures = 5
+
+type interfacesHealthFailure struct {
+	Change string    `json:"change"`
+	Kind   string    `json:"kind"`
+	Error  string    `json:"error,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+type interfacesHealth struct {
+	// RepositoryLoaded is true when the interface manager has set up
+	// the interface repository.
+	RepositoryLoaded bool `json:"repository-loaded"`
+
+	// PendingTasks is the number of tasks affecting connections or
+	// security profiles that are not done yet.
+	PendingTasks int `json:"pending-tasks"`
+
+	// Failures are the most recent failures of tasks affecting
+	// connections or security profiles, most recent first.
+	Failures []interfacesHealthFailure `json:"failures,omitempty"`
+
+	// HotplugMonitor is the status of the udev monitor used for
+	// hotplug support.
+	HotplugMonitor string `json:"hotplug-monitor"`
+}
+
+func taskError(t *state.Task) string {
+	log := t.Log()
+	for i := len(log) - 1; i >= 0; i-- {
+		if idx := strings.Index(log[i], " "+state.LogError+" "); idx >= 0 {
+			return log[i][idx+len(state.LogError)+2:]
+		}
+	}
+	return ""
+}
+
+func getInterfacesHealth(st *state.State, ifaceMgr *ifacestate.InterfaceManager) Response {
+	data := &interfacesHealth{
+		RepositoryLoaded: ifacerepo.Available(st),
+		HotplugMonitor:   ifaceMgr.HotplugMonitorStatus(),
+	}
+
+	for _, chg := range st.Changes() {
+		for _, t := range chg.Tasks() {
+			if !securityTaskKinds[t.Kind()] {
+				continue
+			}
+			status := t.Status()
+			if !status.Ready() {
+				data.PendingTasks++
+				continue
+			}
+			if status != state.ErrorStatus {
+				continue
+			}
+			data.Failures = append(data.Failures, interfacesHealthFailure{
+				Change: chg.ID(),
+				Kind:   t.Kind(),
+				Error:  taskError(t),
+				Time:   t.ReadyTime(),
+			})
+		}
+	}
+	sort.SliceStable(data.Failures, func(i, j int) bool {
+		return data.Failures[i].Time.After(data.Failures[j].Time)
+	})
+	if len(data.Failures) > maxInterfacesHealthFailures {
+		data.Failures = data.Failures[:maxInterfacesHealthFailures]
+	}
+
+	return SyncResponse(data)
+}
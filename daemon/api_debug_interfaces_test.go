@@ -0,0 +1,94 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var _ = Suite(&interfacesDebugSuite{})
+
+type interfacesDebugSuite struct {
+	apiBaseSuite
+}
+
+func (s *interfacesDebugSuite) getInterfacesDebug(c *C) *daemon.InterfacesHealth {
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=interfaces", nil)
+	c.Assert(err, IsNil)
+
+	rsp := s.syncReq(c, req, nil)
+	c.Assert(rsp.Type, Equals, daemon.ResponseTypeSync)
+	return rsp.Result.(*daemon.InterfacesHealth)
+}
+
+func (s *interfacesDebugSuite) TestNoChanges(c *C) {
+	s.daemon(c)
+
+	c.Check(s.getInterfacesDebug(c), DeepEquals, &daemon.InterfacesHealth{
+		RepositoryLoaded: true,
+		HotplugMonitor:   "not-running",
+	})
+}
+
+func (s *interfacesDebugSuite) TestPendingAndFailures(c *C) {
+	d := s.daemon(c)
+
+	t0 := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	st := d.Overlord().State()
+	st.Lock()
+	chg := st.NewChange("connect-snap", "...")
+	t1 := st.NewTask("connect", "...")
+	chg.AddTask(t1)
+	// tasks unrelated to interfaces are ignored
+	chg.AddTask(st.NewTask("link-snap", "..."))
+
+	var changeIDs []string
+	for i := 0; i < 7; i++ {
+		restore := state.MockTime(t0.Add(time.Duration(i) * time.Minute))
+		chg := st.NewChange("install-snap", "...")
+		t := st.NewTask("setup-profiles", "...")
+		t.Logf("some info")
+		t.Errorf("cannot setup profiles %d", i)
+		t.Logf("more info")
+		t.SetStatus(state.ErrorStatus)
+		chg.AddTask(t)
+		restore()
+		changeIDs = append(changeIDs, chg.ID())
+	}
+	st.Unlock()
+
+	data := s.getInterfacesDebug(c)
+	c.Check(data.RepositoryLoaded, Equals, true)
+	c.Check(data.PendingTasks, Equals, 1)
+	// only the most recent failures are reported
+	c.Check(data.Failures, DeepEquals, []daemon.InterfacesHealthFailure{
+		{Change: changeIDs[6], Kind: "setup-profiles", Error: "cannot setup profiles 6", Time: t0.Add(6 * time.Minute)},
+		{Change: changeIDs[5], Kind: "setup-profiles", Error: "cannot setup profiles 5", Time: t0.Add(5 * time.Minute)},
+		{Change: changeIDs[4], Kind: "setup-profiles", Error: "cannot setup profiles 4", Time: t0.Add(4 * time.Minute)},
+		{Change: changeIDs[3], Kind: "setup-profiles", Error: "cannot setup profiles 3", Time: t0.Add(3 * time.Minute)},
+		{Change: changeIDs[2], Kind: "setup-profiles", Error: "cannot setup profiles 2", Time: t0.Add(2 * time.Minute)},
+	})
+}
@@ -24,13 +24,17 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/auth"
 	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
 )
 
 var (
@@ -39,7 +43,13 @@ var (
 		GET:         interfacesConnectionsMultiplexer,
 		POST:        changeInterfaces,
 		ReadAccess:  openAccess{},
-		WriteAccess: authenticatedAccess{Polkit: polkitActionManageInterfaces},
+		WriteAccess: interfacesAuthenticatedAccess{},
+	}
+
+	interfaceEventsCmd = &Command{
+		Path:       "/v2/interfaces/events",
+		GET:        getInterfaceEvents,
+		ReadAccess: openAccess{},
 	}
 )
 
@@ -66,36 +76,68 @@ func getInterfaces(c *Command, r *http.Request, user *auth.UserState) Response {
 	if namesStr != "" {
 		names = strings.Split(namesStr, ",")
 	}
+	var limit int
+	if limitStr := q.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			return BadRequest("invalid limit: %q", limitStr)
+		}
+	}
 	opts := &interfaces.InfoOptions{
 		Names:     names,
 		Doc:       q.Get("doc") == "true",
 		Plugs:     q.Get("plugs") == "true",
 		Slots:     q.Get("slots") == "true",
 		Connected: pselect == "connected",
+		After:     q.Get("after"),
+		Limit:     limit,
 	}
+	withConnections := q.Get("connections") == "true"
 	// Query the interface repository (this returns []*interface.Info).
-	infos := c.d.overlord.InterfaceManager().Repository().Info(opts)
+	repo := c.d.overlord.InterfaceManager().Repository()
+	infos := repo.Info(opts)
 	infoJSONs := make([]*interfaceJSON, 0, len(infos))
 
 	for _, info := range infos {
 		// Convert interfaces.Info into interfaceJSON
 		plugs := make([]*plugJSON, 0, len(info.Plugs))
 		for _, plug := range info.Plugs {
-			plugs = append(plugs, &plugJSON{
+			pj := &plugJSON{
 				Snap:  plug.Snap.InstanceName(),
 				Name:  plug.Name,
 				Attrs: plug.Attrs,
 				Label: plug.Label,
-			})
+			}
+			if withConnections {
+				connRefs, err := repo.Connected(pj.Snap, pj.Name)
+				if err != nil {
+					return InternalError("cannot list connections of plug %s:%s: %v", pj.Snap, pj.Name, err)
+				}
+				for _, connRef := range connRefs {
+					pj.Connections = append(pj.Connections, connRef.SlotRef)
+				}
+			}
+			plugs = append(plugs, pj)
 		}
 		slots := make([]*slotJSON, 0, len(info.Slots))
 		for _, slot := range info.Slots {
-			slots = append(slots, &slotJSON{
+			sj := &slotJSON{
 				Snap:  slot.Snap.InstanceName(),
 				Name:  slot.Name,
 				Attrs: slot.Attrs,
 				Label: slot.Label,
-			})
+			}
+			if withConnections {
+				connRefs, err := repo.Connected(sj.Snap, sj.Name)
+				if err != nil {
+					return InternalError("cannot list connections of slot %s:%s: %v", sj.Snap, sj.Name, err)
+				}
+				for _, connRef := range connRefs {
+					sj.Connections = append(sj.Connections, connRef.PlugRef)
+				}
+			}
+			slots = append(slots, sj)
 		}
 		infoJSONs = append(infoJSONs, &interfaceJSON{
 			Name:    info.Name,
@@ -103,11 +145,92 @@ func getInterfaces(c *Command, r *http.Request, user *auth.UserState) Response {
 			DocURL:  info.DocURL,
 			Plugs:   plugs,
 			Slots:   slots,
+
+			ImplicitOnCore:    info.ImplicitOnCore,
+			ImplicitOnClassic: info.ImplicitOnClassic,
 		})
 	}
 	return SyncResponse(infoJSONs)
 }
 
+// interfaceEventsBufferSize is the number of repository events that can be
+// queued for a single client before the stream is terminated.
+var interfaceEventsBufferSize = 64
+
+func getInterfaceEvents(c *Command, r *http.Request, user *auth.UserState) Response {
+	rsp := &interfaceEventSeqResponse{
+		events:   make(chan *interfaces.RepositoryEvent, interfaceEventsBufferSize),
+		overflow: make(chan struct{}),
+	}
+	// the observer is registered right away so that no events are missed
+	// between the response being created and served
+	repo := c.d.overlord.InterfaceManager().Repository()
+	rsp.remove = repo.AddObserver(rsp)
+	return rsp
+}
+
+// An interfaceEventSeqResponse's ServeHTTP method streams changes of plugs,
+// slots and connections as they are made to the interfaces repository, as a
+// json-seq response. The stream ends when the client goes away, or with an
+// error entry when the client cannot keep up with the events.
+type interfaceEventSeqResponse struct {
+	events       chan *interfaces.RepositoryEvent
+	overflow     chan struct{}
+	overflowOnce sync.Once
+	remove       func()
+}
+
+// RepositoryChanged is called by the repository with its lock held, so it
+// must never block.
+func (rsp *interfaceEventSeqResponse) RepositoryChanged(ev *interfaces.RepositoryEvent) {
+	select {
+	case rsp.events <- ev:
+	default:
+		rsp.overflowOnce.Do(func() { close(rsp.overflow) })
+	}
+}
+
+func (rsp *interfaceEventSeqResponse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer rsp.remove()
+
+	w.Header().Set("Content-Type", "application/json-seq")
+	flusher, hasFlusher := w.(http.Flusher)
+	if hasFlusher {
+		flusher.Flush()
+	}
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case ev := <-rsp.events:
+			evj := interfaceEventJSON{
+				Kind: string(ev.Kind),
+				Snap: ev.Snap,
+				Name: ev.Name,
+			}
+			if ev.Conn != nil {
+				evj.Plug = &ev.Conn.PlugRef
+				evj.Slot = &ev.Conn.SlotRef
+			}
+			if _, err := w.Write([]byte{0x1E}); err != nil { // RS -- see ascii(7), and RFC7464
+				return
+			}
+			if err := enc.Encode(evj); err != nil {
+				logger.Noticef("cannot stream interface events: %v", err)
+				return
+			}
+			if hasFlusher {
+				flusher.Flush()
+			}
+		case <-rsp.overflow:
+			fmt.Fprintf(w, "\x1E{\"error\": %q}\n", "too many pending events")
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
 func getLegacyConnections(c *Command, r *http.Request, user *auth.UserState) Response {
 	connsjson, err := collectConnections(c.d.overlord.InterfaceManager(), collectFilter{})
 	if err != nil {
@@ -131,14 +254,35 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 	if a.Action == "" {
 		return BadRequest("interface action not specified")
 	}
-	if len(a.Plugs) > 1 || len(a.Slots) > 1 {
-		return NotImplemented("many-to-many operations are not implemented")
+	if a.DryRun && a.Action != "connect" {
+		return BadRequest("cannot preview interface action: %q", a.Action)
 	}
-	if a.Action != "connect" && a.Action != "disconnect" {
-		return BadRequest("unsupported interface action: %q", a.Action)
+	if a.Force {
+		// a forced disconnect removes all connections of the given plugs,
+		// slots or snaps, they are not matched against each other
+		if a.Action != "disconnect" {
+			return BadRequest("cannot force interface action: %q", a.Action)
+		}
+		if a.Forget {
+			return BadRequest("cannot forget connections when forcing a disconnect")
+		}
+		if len(a.Plugs) == 0 && len(a.Slots) == 0 {
+			return BadRequest("at least one plug or slot is required")
+		}
+	} else {
+		// many plugs and slots are only supported pairwise, as a batch
+		if (len(a.Plugs) > 1 || len(a.Slots) > 1) && len(a.Plugs) != len(a.Slots) {
+			return NotImplemented("many-to-many operations are not implemented")
+		}
+		if a.Action != "connect" && a.Action != "disconnect" && a.Action != "connect-pending" {
+			return BadRequest("unsupported interface action: %q", a.Action)
+		}
+		if len(a.Plugs) == 0 || len(a.Slots) == 0 {
+			return BadRequest("at least one plug and slot is required")
+		}
 	}
-	if len(a.Plugs) == 0 || len(a.Slots) == 0 {
-		return BadRequest("at least one plug and slot is required")
+	if a.Action == "connect-pending" {
+		return connectPending(c, &a)
 	}
 
 	var summary string
@@ -146,6 +290,7 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 
 	var tasksets []*state.TaskSet
 	var affected []string
+	var connected, alreadyConnected, disconnected []connRefJSON
 
 	st := c.d.overlord.State()
 	st.Lock()
@@ -180,32 +325,85 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 		}
 	}
 
+	// a batch of changes is applied with all-or-nothing semantics: all
+	// the plugs and slots are resolved before any task is created, and
+	// the tasks share a lane, so that a failure undoes the whole change
+	batch := len(a.Plugs) > 1 && !a.Force
+
 	switch a.Action {
 	case "connect":
-		var connRef *interfaces.ConnRef
+		var connRefs []*interfaces.ConnRef
+		connRefs, err = resolveConnectMany(c.d.overlord.InterfaceManager().Repository(), a.Plugs, a.Slots)
+		if err != nil {
+			break
+		}
+		if a.DryRun {
+			return previewConnections(c.d.overlord.InterfaceManager().Repository(), connRefs)
+		}
+		affected = snapNamesFromConns(connRefs)
+		if batch {
+			summary = fmt.Sprintf("Connect %d plugs to slots", len(connRefs))
+		} else {
+			connRef := connRefs[0]
+			summary = fmt.Sprintf("Connect %s:%s to %s:%s", connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
+		}
 		repo := c.d.overlord.InterfaceManager().Repository()
-		connRef, err = repo.ResolveConnect(a.Plugs[0].Snap, a.Plugs[0].Name, a.Slots[0].Snap, a.Slots[0].Name)
-		if err == nil {
+		var newConns, alreadyConns []*interfaces.ConnRef
+		for _, connRef := range connRefs {
 			var ts *state.TaskSet
-			affected = snapNamesFromConns([]*interfaces.ConnRef{connRef})
-			summary = fmt.Sprintf("Connect %s:%s to %s:%s", connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
 			ts, err = ifacestate.Connect(st, connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name)
 			if _, ok := err.(*ifacestate.ErrAlreadyConnected); ok {
-				change := newChange(st, a.Action+"-snap", summary, nil, affected)
-				change.SetStatus(state.DoneStatus)
-				return AsyncResponse(nil, change.ID())
+				err = nil
+				alreadyConns = append(alreadyConns, connRef)
+				continue
+			}
+			if err != nil {
+				break
+			}
+			// report a denial by policy of a new connection now
+			// rather than as the error of the change
+			if err = ifacestateCheckConnectPolicy(st, repo, connRef); err != nil {
+				break
 			}
+			newConns = append(newConns, connRef)
 			tasksets = append(tasksets, ts)
 		}
+		connected = connRefsToJSON(newConns)
+		if len(alreadyConns) > 0 {
+			alreadyConnected = connRefsToJSON(alreadyConns)
+		}
+		if err == nil && len(tasksets) == 0 {
+			change := newChange(st, a.Action+"-snap", summary, nil, affected)
+			change.Set("api-data", map[string]interface{}{
+				"snap-names":        affected,
+				"connected":         connected,
+				"already-connected": alreadyConnected,
+			})
+			change.SetStatus(state.DoneStatus)
+			return AsyncResponse(nil, change.ID())
+		}
 	case "disconnect":
 		var conns []*interfaces.ConnRef
-		summary = fmt.Sprintf("Disconnect %s:%s from %s:%s", a.Plugs[0].Snap, a.Plugs[0].Name, a.Slots[0].Snap, a.Slots[0].Name)
-		conns, err = c.d.overlord.InterfaceManager().ResolveDisconnect(a.Plugs[0].Snap, a.Plugs[0].Name, a.Slots[0].Snap, a.Slots[0].Name, a.Forget)
+		switch {
+		case a.Force:
+			summary = fmt.Sprintf("Forcibly disconnect %s", strings.Join(forcedDisconnectTargets(a.Plugs, a.Slots), ", "))
+			conns, err = resolveForcedDisconnect(c.d.overlord.InterfaceManager().Repository(), a.Plugs, a.Slots)
+		case batch:
+			summary = fmt.Sprintf("Disconnect %d plugs from slots", len(a.Plugs))
+			conns, err = resolveDisconnectMany(c.d.overlord.InterfaceManager(), a.Plugs, a.Slots, a.Forget)
+		default:
+			summary = fmt.Sprintf("Disconnect %s:%s from %s:%s", a.Plugs[0].Snap, a.Plugs[0].Name, a.Slots[0].Snap, a.Slots[0].Name)
+			conns, err = resolveDisconnectMany(c.d.overlord.InterfaceManager(), a.Plugs, a.Slots, a.Forget)
+		}
 		if err == nil {
 			if len(conns) == 0 {
 				return InterfacesUnchanged("nothing to do")
 			}
 			repo := c.d.overlord.InterfaceManager().Repository()
+			var lane int
+			if batch {
+				lane = st.NewLane()
+			}
 			for _, connRef := range conns {
 				var ts *state.TaskSet
 				var conn *interfaces.Connection
@@ -224,10 +422,14 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 				if err != nil {
 					break
 				}
-				ts.JoinLane(st.NewLane())
+				if !batch {
+					lane = st.NewLane()
+				}
+				ts.JoinLane(lane)
 				tasksets = append(tasksets, ts)
 			}
 			affected = snapNamesFromConns(conns)
+			disconnected = connRefsToJSON(conns)
 		}
 	}
 	if err != nil {
@@ -235,11 +437,199 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 	}
 
 	change := newChange(st, a.Action+"-snap", summary, tasksets, affected)
+	apiData := map[string]interface{}{"snap-names": affected}
+	if connected != nil {
+		apiData["connected"] = connected
+	}
+	if alreadyConnected != nil {
+		apiData["already-connected"] = alreadyConnected
+	}
+	if disconnected != nil {
+		apiData["disconnected"] = disconnected
+	}
+	change.Set("api-data", apiData)
 	st.EnsureBefore(0)
 
 	return AsyncResponse(nil, change.ID())
 }
 
+// connectPending records the intent to connect a plug and a slot once both
+// of them are available, the snaps involved need not be installed.
+func connectPending(c *Command, a *interfaceAction) Response {
+	if len(a.Plugs) != 1 || len(a.Slots) != 1 {
+		return BadRequest("exactly one plug and slot are required")
+	}
+	plug, slot := a.Plugs[0], a.Slots[0]
+	if plug.Snap == "" || plug.Name == "" || slot.Snap == "" || slot.Name == "" {
+		return BadRequest("pending connection requires fully qualified plug and slot")
+	}
+	if a.Interface == "" {
+		return BadRequest("pending connection requires an interface")
+	}
+	for _, err := range []error{
+		snap.ValidateInstanceName(plug.Snap),
+		snap.ValidatePlugName(plug.Name),
+		snap.ValidateInstanceName(slot.Snap),
+		snap.ValidateSlotName(slot.Name),
+	} {
+		if err != nil {
+			return BadRequest("%v", err)
+		}
+	}
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	connRef := &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: ifacestate.RemapSnapFromRequest(plug.Snap), Name: plug.Name},
+		SlotRef: interfaces.SlotRef{Snap: ifacestate.RemapSnapFromRequest(slot.Snap), Name: slot.Name},
+	}
+	if err := ifacestate.ConnectPending(st, c.d.overlord.InterfaceManager().Repository(), connRef, a.Interface); err != nil {
+		return BadRequest("%v", err)
+	}
+	return SyncResponse(nil)
+}
+
+// resolveConnectMany resolves the connection references for pairs of plugs
+// and slots, dropping duplicates.
+func resolveConnectMany(repo interfaces.ConnectionRepository, plugs []plugJSON, slots []slotJSON) ([]*interfaces.ConnRef, error) {
+	seen := make(map[string]bool, len(plugs))
+	connRefs := make([]*interfaces.ConnRef, 0, len(plugs))
+	for i := range plugs {
+		connRef, err := repo.ResolveConnect(plugs[i].Snap, plugs[i].Name, slots[i].Snap, slots[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[connRef.ID()] {
+			continue
+		}
+		seen[connRef.ID()] = true
+		connRefs = append(connRefs, connRef)
+	}
+	return connRefs, nil
+}
+
+// previewConnections reports the security snippets that each of the given
+// connections would add, without changing anything.
+func previewConnections(repo interfaces.ConnectionRepository, connRefs []*interfaces.ConnRef) Response {
+	previews := make([]connectionPreviewJSON, 0, len(connRefs))
+	for _, connRef := range connRefs {
+		snippets, err := repo.PreviewConnection(connRef)
+		if err != nil {
+			return errToResponse(err, nil, BadRequest, "%v")
+		}
+		previews = append(previews, connectionPreviewJSON{
+			Plug:     connRef.PlugRef,
+			Slot:     connRef.SlotRef,
+			Snippets: snippets,
+		})
+	}
+	return SyncResponse(previews)
+}
+
+// resolveDisconnectMany resolves the connections to be disconnected for pairs
+// of plugs and slots, dropping duplicates.
+func resolveDisconnectMany(ifaceMgr *ifacestate.InterfaceManager, plugs []plugJSON, slots []slotJSON, forget bool) ([]*interfaces.ConnRef, error) {
+	seen := make(map[string]bool, len(plugs))
+	var conns []*interfaces.ConnRef
+	for i := range plugs {
+		resolved, err := ifaceMgr.ResolveDisconnect(plugs[i].Snap, plugs[i].Name, slots[i].Snap, slots[i].Name, forget)
+		if err != nil {
+			return nil, err
+		}
+		for _, connRef := range resolved {
+			if seen[connRef.ID()] {
+				continue
+			}
+			seen[connRef.ID()] = true
+			conns = append(conns, connRef)
+		}
+	}
+	return conns, nil
+}
+
+// resolveForcedDisconnect resolves all the connections of the given plugs and
+// slots, dropping duplicates. A plug or slot without a name stands for all the
+// plugs or slots of its snap.
+func resolveForcedDisconnect(repo interfaces.ConnectionRepository, plugs []plugJSON, slots []slotJSON) ([]*interfaces.ConnRef, error) {
+	seen := make(map[string]bool)
+	var conns []*interfaces.ConnRef
+	add := func(resolved []*interfaces.ConnRef) {
+		for _, connRef := range resolved {
+			if seen[connRef.ID()] {
+				continue
+			}
+			seen[connRef.ID()] = true
+			conns = append(conns, connRef)
+		}
+	}
+	resolve := func(snapName, name string, isPlug bool) error {
+		if name != "" {
+			resolved, err := repo.Connected(snapName, name)
+			if err != nil {
+				return err
+			}
+			add(resolved)
+			return nil
+		}
+		resolved, err := repo.Connections(snapName)
+		if err != nil {
+			return err
+		}
+		for _, connRef := range resolved {
+			if isPlug && connRef.PlugRef.Snap == snapName || !isPlug && connRef.SlotRef.Snap == snapName {
+				add([]*interfaces.ConnRef{connRef})
+			}
+		}
+		return nil
+	}
+	for _, plug := range plugs {
+		if plug.Snap == "" && plug.Name == "" {
+			continue
+		}
+		if err := resolve(plug.Snap, plug.Name, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, slot := range slots {
+		if slot.Snap == "" && slot.Name == "" {
+			continue
+		}
+		if err := resolve(slot.Snap, slot.Name, false); err != nil {
+			return nil, err
+		}
+	}
+	return conns, nil
+}
+
+func forcedDisconnectTargets(plugs []plugJSON, slots []slotJSON) []string {
+	var targets []string
+	for _, plug := range plugs {
+		if plug.Name == "" {
+			targets = append(targets, fmt.Sprintf("plugs of %s", plug.Snap))
+		} else {
+			targets = append(targets, fmt.Sprintf("%s:%s", plug.Snap, plug.Name))
+		}
+	}
+	for _, slot := range slots {
+		if slot.Name == "" {
+			targets = append(targets, fmt.Sprintf("slots of %s", slot.Snap))
+		} else {
+			targets = append(targets, fmt.Sprintf("%s:%s", slot.Snap, slot.Name))
+		}
+	}
+	return targets
+}
+
+func connRefsToJSON(conns []*interfaces.ConnRef) []connRefJSON {
+	l := make([]connRefJSON, 0, len(conns))
+	for _, connRef := range conns {
+		l = append(l, connRefJSON{Plug: connRef.PlugRef, Slot: connRef.SlotRef})
+	}
+	return l
+}
+
 func snapNamesFromConns(conns []*interfaces.ConnRef) []string {
 	m := make(map[string]bool)
 	for _, conn := range conns {
@@ -20,9 +20,12 @@
 package daemon_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -32,6 +35,7 @@ import (
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
 	"github.com/snapcore/snapd/interfaces/ifacetest"
 	"github.com/snapcore/snapd/overlord/ifacestate"
@@ -47,7 +51,7 @@ type interfacesSuite struct {
 func (s *interfacesSuite) SetUpTest(c *check.C) {
 	s.apiBaseSuite.SetUpTest(c)
 
-	s.expectWriteAccess(daemon.AuthenticatedAccess{Polkit: "io.snapcraft.snapd.manage-interfaces"})
+	s.expectWriteAccess(daemon.InterfacesAuthenticatedAccess{})
 }
 
 func mockIface(c *check.C, d *daemon.Daemon, iface interfaces.Interface) {
@@ -165,8 +169,19 @@ func (s *interfacesSuite) TestConnectPlugSuccess(c *check.C) {
 
 	st.Lock()
 	err = chg.Err()
+	var apiData map[string]interface{}
+	c.Check(chg.Get("api-data", &apiData), check.IsNil)
 	st.Unlock()
 	c.Assert(err, check.IsNil)
+	c.Check(apiData, check.DeepEquals, map[string]interface{}{
+		"snap-names": []interface{}{"consumer", "producer"},
+		"connected": []interface{}{
+			map[string]interface{}{
+				"plug": map[string]interface{}{"snap": "consumer", "plug": "plug"},
+				"slot": map[string]interface{}{"snap": "producer", "slot": "slot"},
+			},
+		},
+	})
 
 	repo := d.Overlord().InterfaceManager().Repository()
 	ifaces := repo.Interfaces()
@@ -177,6 +192,379 @@ func (s *interfacesSuite) TestConnectPlugSuccess(c *check.C) {
 	}})
 }
 
+const otherConsumerYaml = `
+name: other-consumer
+version: 1
+apps:
+ app:
+plugs:
+ plug:
+  interface: test
+`
+
+func (s *interfacesSuite) TestConnectBatchSuccess(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, otherConsumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	action := &client.InterfaceAction{
+		Action: "connect",
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}, {Snap: "other-consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}, {Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rsp := s.asyncReq(c, req, nil)
+
+	st := d.Overlord().State()
+	st.Lock()
+	chg := st.Change(rsp.Change)
+	st.Unlock()
+	c.Assert(chg, check.NotNil)
+
+	<-chg.Ready()
+
+	st.Lock()
+	err = chg.Err()
+	summary := chg.Summary()
+	var apiData map[string]interface{}
+	c.Check(chg.Get("api-data", &apiData), check.IsNil)
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+	c.Check(summary, check.Equals, "Connect 2 plugs to slots")
+	c.Check(apiData["snap-names"], check.DeepEquals, []interface{}{"consumer", "other-consumer", "producer"})
+	c.Check(apiData["connected"], check.HasLen, 2)
+
+	repo := d.Overlord().InterfaceManager().Repository()
+	c.Check(repo.Interfaces().Connections, check.DeepEquals, []*interfaces.ConnRef{{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}, {
+		PlugRef: interfaces.PlugRef{Snap: "other-consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}})
+}
+
+func (s *interfacesSuite) TestConnectBatchFailureNothingConnected(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, otherConsumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	action := &client.InterfaceAction{
+		Action: "connect",
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}, {Snap: "other-consumer", Name: "missing"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}, {Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 404)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindInterfacesPlugOrSlotNotFound)
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Changes(), check.HasLen, 0)
+}
+
+func (s *interfacesSuite) TestConnectManyToManyMismatch(c *check.C) {
+	s.daemon(c)
+
+	action := &client.InterfaceAction{
+		Action: "connect",
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}, {Snap: "other-consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 501)
+	c.Check(rspe.Message, check.Equals, "many-to-many operations are not implemented")
+}
+
+func (s *interfacesSuite) TestDisconnectBatchSuccess(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, otherConsumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	repo := d.Overlord().InterfaceManager().Repository()
+	for _, plugSnap := range []string{"consumer", "other-consumer"} {
+		_, err := repo.Connect(&interfaces.ConnRef{
+			PlugRef: interfaces.PlugRef{Snap: plugSnap, Name: "plug"},
+			SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+		}, nil, nil, nil, nil, nil)
+		c.Assert(err, check.IsNil)
+	}
+
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{
+			"interface": "test",
+		},
+		"other-consumer:plug producer:slot": map[string]interface{}{
+			"interface": "test",
+		},
+	})
+	st.Unlock()
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	action := &client.InterfaceAction{
+		Action: "disconnect",
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}, {Snap: "other-consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}, {Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rsp := s.asyncReq(c, req, nil)
+
+	st.Lock()
+	chg := st.Change(rsp.Change)
+	st.Unlock()
+	c.Assert(chg, check.NotNil)
+
+	<-chg.Ready()
+
+	st.Lock()
+	err = chg.Err()
+	summary := chg.Summary()
+	// all the tasks of a batch share a lane
+	lanes := make(map[int]bool)
+	for _, t := range chg.Tasks() {
+		for _, lane := range t.Lanes() {
+			lanes[lane] = true
+		}
+	}
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+	c.Check(summary, check.Equals, "Disconnect 2 plugs from slots")
+	c.Check(lanes, check.HasLen, 1)
+	c.Check(repo.Interfaces().Connections, check.HasLen, 0)
+}
+
+func (s *interfacesSuite) TestDisconnectForceSnapSlots(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, otherConsumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	repo := d.Overlord().InterfaceManager().Repository()
+	for _, plugSnap := range []string{"consumer", "other-consumer"} {
+		_, err := repo.Connect(&interfaces.ConnRef{
+			PlugRef: interfaces.PlugRef{Snap: plugSnap, Name: "plug"},
+			SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+		}, nil, nil, nil, nil, nil)
+		c.Assert(err, check.IsNil)
+	}
+
+	st := d.Overlord().State()
+	st.Lock()
+	st.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{
+			"interface": "test",
+		},
+		"other-consumer:plug producer:slot": map[string]interface{}{
+			"interface": "test",
+		},
+	})
+	st.Unlock()
+
+	d.Overlord().Loop()
+	defer d.Overlord().Stop()
+
+	// all the connections of the slots of producer are removed, the
+	// explicitly listed plug is already covered by those
+	action := &client.InterfaceAction{
+		Action: "disconnect",
+		Force:  true,
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rsp := s.asyncReq(c, req, nil)
+
+	st.Lock()
+	chg := st.Change(rsp.Change)
+	st.Unlock()
+	c.Assert(chg, check.NotNil)
+
+	<-chg.Ready()
+
+	st.Lock()
+	err = chg.Err()
+	summary := chg.Summary()
+	var apiData map[string]interface{}
+	c.Check(chg.Get("api-data", &apiData), check.IsNil)
+	// forced disconnects do not undo each other
+	lanes := make(map[int]bool)
+	for _, t := range chg.Tasks() {
+		for _, lane := range t.Lanes() {
+			lanes[lane] = true
+		}
+	}
+	st.Unlock()
+	c.Assert(err, check.IsNil)
+	c.Check(summary, check.Equals, "Forcibly disconnect consumer:plug, slots of producer")
+	c.Check(lanes, check.HasLen, 2)
+	c.Check(apiData["snap-names"], check.DeepEquals, []interface{}{"consumer", "other-consumer", "producer"})
+	c.Check(apiData["disconnected"], check.HasLen, 2)
+	c.Check(repo.Interfaces().Connections, check.HasLen, 0)
+}
+
+func (s *interfacesSuite) TestDisconnectForceNothingToDo(c *check.C) {
+	revert := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer revert()
+	s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	action := &client.InterfaceAction{
+		Action: "disconnect",
+		Force:  true,
+		Plugs:  []client.Plug{{Snap: "consumer"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindInterfacesUnchanged)
+	c.Check(rspe.Message, check.Equals, "nothing to do")
+}
+
+func (s *interfacesSuite) TestDisconnectForceErrors(c *check.C) {
+	s.daemon(c)
+
+	for _, t := range []struct {
+		action *client.InterfaceAction
+		err    string
+	}{
+		{&client.InterfaceAction{Action: "connect", Force: true, Plugs: []client.Plug{{Snap: "consumer"}}}, `cannot force interface action: "connect"`},
+		{&client.InterfaceAction{Action: "disconnect", Force: true, Forget: true, Plugs: []client.Plug{{Snap: "consumer"}}}, "cannot forget connections when forcing a disconnect"},
+		{&client.InterfaceAction{Action: "disconnect", Force: true}, "at least one plug or slot is required"},
+	} {
+		text, err := json.Marshal(t.action)
+		c.Assert(err, check.IsNil)
+		req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+		c.Assert(err, check.IsNil)
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Equals, t.err)
+	}
+}
+
+func (s *interfacesSuite) TestConnectDryRun(c *check.C) {
+	d := s.daemon(c)
+
+	mockIface(c, d, &ifacetest.TestInterface{
+		InterfaceName: "test",
+		AppArmorConnectedPlugCallback: func(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddSnippet("/dev/foo rw,")
+			return nil
+		},
+	})
+	repo := d.Overlord().InterfaceManager().Repository()
+	c.Assert(repo.AddBackend(&apparmor.Backend{}), check.IsNil)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	action := &client.InterfaceAction{
+		Action: "connect",
+		DryRun: true,
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Status, check.Equals, 200)
+
+	// the result marshals to the documented form
+	out, err := json.Marshal(rsp.Result)
+	c.Assert(err, check.IsNil)
+	var result []map[string]interface{}
+	c.Assert(json.Unmarshal(out, &result), check.IsNil)
+	c.Check(result, check.DeepEquals, []map[string]interface{}{{
+		"plug": map[string]interface{}{"snap": "consumer", "plug": "plug"},
+		"slot": map[string]interface{}{"snap": "producer", "slot": "slot"},
+		"snippets": map[string]interface{}{
+			"apparmor": map[string]interface{}{
+				"snap.consumer.app": []interface{}{"/dev/foo rw,"},
+			},
+		},
+	}})
+
+	// nothing was connected and no change was made
+	c.Check(repo.Interfaces().Connections, check.HasLen, 0)
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Changes(), check.HasLen, 0)
+}
+
+func (s *interfacesSuite) TestDryRunErrors(c *check.C) {
+	d := s.daemon(c)
+
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerYaml)
+
+	for _, t := range []struct {
+		action *client.InterfaceAction
+		status int
+		err    string
+	}{
+		{&client.InterfaceAction{Action: "disconnect", DryRun: true, Plugs: []client.Plug{{Snap: "consumer", Name: "plug"}}, Slots: []client.Slot{{Snap: "producer", Name: "slot"}}}, 400, `cannot preview interface action: "disconnect"`},
+		{&client.InterfaceAction{Action: "connect", DryRun: true, Plugs: []client.Plug{{Snap: "consumer", Name: "plug"}}, Slots: []client.Slot{{Snap: "consumer", Name: "slot"}}}, 404, `snap "consumer" has no slot named "slot"`},
+	} {
+		text, err := json.Marshal(t.action)
+		c.Assert(err, check.IsNil)
+		req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+		c.Assert(err, check.IsNil)
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, check.Equals, t.status)
+		c.Check(rspe.Message, check.Equals, t.err)
+	}
+}
+
 func (s *interfacesSuite) TestConnectPlugFailureInterfaceMismatch(c *check.C) {
 	d := s.daemon(c)
 
@@ -234,7 +622,7 @@ func (s *interfacesSuite) TestConnectPlugFailureNoSuchPlug(c *check.C) {
 	c.Assert(err, check.IsNil)
 	rec := httptest.NewRecorder()
 	s.req(c, req, nil).ServeHTTP(rec, req)
-	c.Check(rec.Code, check.Equals, 400)
+	c.Check(rec.Code, check.Equals, 404)
 
 	var body map[string]interface{}
 	err = json.Unmarshal(rec.Body.Bytes(), &body)
@@ -242,9 +630,10 @@ func (s *interfacesSuite) TestConnectPlugFailureNoSuchPlug(c *check.C) {
 	c.Check(body, check.DeepEquals, map[string]interface{}{
 		"result": map[string]interface{}{
 			"message": "snap \"consumer\" has no plug named \"missingplug\"",
+			"kind":    "interfaces-plug-or-slot-not-found",
 		},
-		"status":      "Bad Request",
-		"status-code": 400.0,
+		"status":      "Not Found",
+		"status-code": 404.0,
 		"type":        "error",
 	})
 
@@ -253,6 +642,17 @@ func (s *interfacesSuite) TestConnectPlugFailureNoSuchPlug(c *check.C) {
 	c.Assert(ifaces.Connections, check.HasLen, 0)
 }
 
+func (s *interfacesSuite) TestConnectAlreadyConnectedNotAllowed(c *check.C) {
+	// a connection that is already in place is reported as such even if
+	// the policy would not allow it anymore
+	restore := daemon.MockIfacestateCheckConnectPolicy(func(st *state.State, repo *interfaces.Repository, connRef *interfaces.ConnRef) error {
+		return &ifacestate.ErrConnectNotAllowed{Connection: *connRef, Err: fmt.Errorf("connection not allowed by slot rule of interface \"test\"")}
+	})
+	defer restore()
+
+	s.TestConnectAlreadyConnected(c)
+}
+
 func (s *interfacesSuite) TestConnectAlreadyConnected(c *check.C) {
 	d := s.daemon(c)
 
@@ -304,7 +704,51 @@ func (s *interfacesSuite) TestConnectAlreadyConnected(c *check.C) {
 	chg := st.Change(id)
 	c.Assert(chg.Tasks(), check.HasLen, 0)
 	c.Assert(chg.Status(), check.Equals, state.DoneStatus)
+	var apiData map[string]interface{}
+	c.Check(chg.Get("api-data", &apiData), check.IsNil)
 	st.Unlock()
+	c.Check(apiData, check.DeepEquals, map[string]interface{}{
+		"snap-names": []interface{}{"consumer", "producer"},
+		"connected":  []interface{}{},
+		"already-connected": []interface{}{
+			map[string]interface{}{
+				"plug": map[string]interface{}{"snap": "consumer", "plug": "plug"},
+				"slot": map[string]interface{}{"snap": "producer", "slot": "slot"},
+			},
+		},
+	})
+}
+
+func (s *interfacesSuite) TestConnectNotAllowed(c *check.C) {
+	d := s.daemon(c)
+
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	restore := daemon.MockIfacestateCheckConnectPolicy(func(st *state.State, repo *interfaces.Repository, connRef *interfaces.ConnRef) error {
+		return &ifacestate.ErrConnectNotAllowed{Connection: *connRef, Err: fmt.Errorf("connection not allowed by slot rule of interface \"test\"")}
+	})
+	defer restore()
+
+	action := &client.InterfaceAction{
+		Action: "connect",
+		Plugs:  []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:  []client.Slot{{Snap: "producer", Name: "slot"}},
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 403)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindInterfacesConnectionNotAllowed)
+	c.Check(rspe.Message, check.Equals, `cannot connect consumer:plug producer:slot: connection not allowed by slot rule of interface "test"`)
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Changes(), check.HasLen, 0)
 }
 
 func (s *interfacesSuite) TestConnectPlugFailureNoSuchSlot(c *check.C) {
@@ -327,7 +771,7 @@ func (s *interfacesSuite) TestConnectPlugFailureNoSuchSlot(c *check.C) {
 	c.Assert(err, check.IsNil)
 	rec := httptest.NewRecorder()
 	s.req(c, req, nil).ServeHTTP(rec, req)
-	c.Check(rec.Code, check.Equals, 400)
+	c.Check(rec.Code, check.Equals, 404)
 
 	var body map[string]interface{}
 	err = json.Unmarshal(rec.Body.Bytes(), &body)
@@ -335,9 +779,10 @@ func (s *interfacesSuite) TestConnectPlugFailureNoSuchSlot(c *check.C) {
 	c.Check(body, check.DeepEquals, map[string]interface{}{
 		"result": map[string]interface{}{
 			"message": "snap \"producer\" has no slot named \"missingslot\"",
+			"kind":    "interfaces-plug-or-slot-not-found",
 		},
-		"status":      "Bad Request",
-		"status-code": 400.0,
+		"status":      "Not Found",
+		"status-code": 404.0,
 		"type":        "error",
 	})
 
@@ -558,8 +1003,19 @@ func (s *interfacesSuite) testDisconnect(c *check.C, plugSnap, plugName, slotSna
 
 	st.Lock()
 	err = chg.Err()
+	var apiData map[string]interface{}
+	c.Check(chg.Get("api-data", &apiData), check.IsNil)
 	st.Unlock()
 	c.Assert(err, check.IsNil)
+	c.Check(apiData, check.DeepEquals, map[string]interface{}{
+		"snap-names": []interface{}{"consumer", "producer"},
+		"disconnected": []interface{}{
+			map[string]interface{}{
+				"plug": map[string]interface{}{"snap": "consumer", "plug": "plug"},
+				"slot": map[string]interface{}{"snap": "producer", "slot": "slot"},
+			},
+		},
+	})
 
 	ifaces := repo.Interfaces()
 	c.Assert(ifaces.Connections, check.HasLen, 0)
@@ -597,16 +1053,17 @@ func (s *interfacesSuite) TestDisconnectPlugFailureNoSuchPlug(c *check.C) {
 	c.Assert(err, check.IsNil)
 	rec := httptest.NewRecorder()
 	s.req(c, req, nil).ServeHTTP(rec, req)
-	c.Check(rec.Code, check.Equals, 400)
+	c.Check(rec.Code, check.Equals, 404)
 	var body map[string]interface{}
 	err = json.Unmarshal(rec.Body.Bytes(), &body)
 	c.Check(err, check.IsNil)
 	c.Check(body, check.DeepEquals, map[string]interface{}{
 		"result": map[string]interface{}{
 			"message": "snap \"consumer\" has no plug named \"missingplug\"",
+			"kind":    "interfaces-plug-or-slot-not-found",
 		},
-		"status":      "Bad Request",
-		"status-code": 400.0,
+		"status":      "Not Found",
+		"status-code": 404.0,
 		"type":        "error",
 	})
 }
@@ -730,16 +1187,17 @@ func (s *interfacesSuite) TestDisconnectPlugFailureNoSuchSlot(c *check.C) {
 	rec := httptest.NewRecorder()
 	s.req(c, req, nil).ServeHTTP(rec, req)
 
-	c.Check(rec.Code, check.Equals, 400)
+	c.Check(rec.Code, check.Equals, 404)
 	var body map[string]interface{}
 	err = json.Unmarshal(rec.Body.Bytes(), &body)
 	c.Check(err, check.IsNil)
 	c.Check(body, check.DeepEquals, map[string]interface{}{
 		"result": map[string]interface{}{
 			"message": "snap \"producer\" has no slot named \"missingslot\"",
+			"kind":    "interfaces-plug-or-slot-not-found",
 		},
-		"status":      "Bad Request",
-		"status-code": 400.0,
+		"status":      "Not Found",
+		"status-code": 404.0,
 		"type":        "error",
 	})
 }
@@ -957,6 +1415,114 @@ func (s *interfacesSuite) TestUnsupportedInterfaceRequest(c *check.C) {
 	})
 }
 
+func (s *interfacesSuite) TestConnectPending(c *check.C) {
+	d := s.daemon(c)
+
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "test"})
+	// the producer is not installed
+	s.mockSnap(c, consumerYaml)
+
+	action := &client.InterfaceAction{
+		Action:    "connect-pending",
+		Plugs:     []client.Plug{{Snap: "consumer", Name: "plug"}},
+		Slots:     []client.Slot{{Snap: "producer", Name: "slot"}},
+		Interface: "test",
+	}
+	text, err := json.Marshal(action)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	var pending map[string]interface{}
+	c.Assert(st.Get("pending-conns", &pending), check.IsNil)
+	c.Check(pending, check.DeepEquals, map[string]interface{}{
+		"consumer:plug producer:slot": "test",
+	})
+	c.Check(st.Changes(), check.HasLen, 0)
+}
+
+func (s *interfacesSuite) TestConnectPendingErrors(c *check.C) {
+	d := s.daemon(c)
+
+	mockIface(c, d, &ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	for _, t := range []struct {
+		plugs []client.Plug
+		slots []client.Slot
+		iface string
+		err   string
+	}{{
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}},
+		slots: []client.Slot{{Snap: "other", Name: "slot"}},
+		err:   "pending connection requires an interface",
+	}, {
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}},
+		slots: []client.Slot{{Snap: "other"}},
+		iface: "test",
+		err:   "pending connection requires fully qualified plug and slot",
+	}, {
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}, {Snap: "consumer", Name: "plug"}},
+		slots: []client.Slot{{Snap: "other", Name: "slot"}, {Snap: "another", Name: "slot"}},
+		iface: "test",
+		err:   "exactly one plug and slot are required",
+	}, {
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}},
+		slots: []client.Slot{{Snap: "other", Name: "slot"}},
+		iface: "unknown",
+		err:   `unknown interface "unknown"`,
+	}, {
+		plugs: []client.Plug{{Snap: "consumer:plug", Name: "plug"}},
+		slots: []client.Slot{{Snap: "other", Name: "slot"}},
+		iface: "test",
+		err:   `invalid snap name: "consumer:plug"`,
+	}, {
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug name"}},
+		slots: []client.Slot{{Snap: "other", Name: "slot"}},
+		iface: "test",
+		err:   `invalid plug name: "plug name"`,
+	}, {
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}},
+		slots: []client.Slot{{Snap: "other other", Name: "slot"}},
+		iface: "test",
+		err:   `invalid snap name: "other other"`,
+	}, {
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}},
+		slots: []client.Slot{{Snap: "other", Name: "a:slot"}},
+		iface: "test",
+		err:   `invalid slot name: "a:slot"`,
+	}, {
+		plugs: []client.Plug{{Snap: "consumer", Name: "plug"}},
+		slots: []client.Slot{{Snap: "producer", Name: "slot"}},
+		iface: "test",
+		err:   `cannot record pending connection consumer:plug producer:slot: plug and slot are already available`,
+	}} {
+		action := &client.InterfaceAction{
+			Action:    "connect-pending",
+			Plugs:     t.plugs,
+			Slots:     t.slots,
+			Interface: t.iface,
+		}
+		text, err := json.Marshal(action)
+		c.Assert(err, check.IsNil)
+		req, err := http.NewRequest("POST", "/v2/interfaces", bytes.NewBuffer(text))
+		c.Assert(err, check.IsNil)
+		rec := httptest.NewRecorder()
+		s.req(c, req, nil).ServeHTTP(rec, req)
+		c.Check(rec.Code, check.Equals, 400)
+		var body map[string]interface{}
+		c.Assert(json.Unmarshal(rec.Body.Bytes(), &body), check.IsNil)
+		c.Check(body["result"], check.DeepEquals, map[string]interface{}{"message": t.err})
+	}
+}
+
 func (s *interfacesSuite) TestMissingInterfaceAction(c *check.C) {
 	s.daemon(c)
 	action := &client.InterfaceAction{}
@@ -1185,3 +1751,264 @@ func (s *interfacesSuite) TestInterfacesModern(c *check.C) {
 		"type":        "sync",
 	})
 }
+
+func (s *interfacesSuite) TestInterfacesModernDocImplicit(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{
+		InterfaceName: "test",
+		InterfaceStaticInfo: interfaces.StaticInfo{
+			Summary:        "test summary",
+			DocURL:         "http://example.com/test",
+			ImplicitOnCore: true,
+		},
+	})
+	defer restore()
+
+	s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/interfaces?select=all&names=test&doc=true", nil)
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+	var body map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &body)
+	c.Check(err, check.IsNil)
+	c.Check(body["result"], check.DeepEquals, []interface{}{
+		map[string]interface{}{
+			"name":             "test",
+			"summary":          "test summary",
+			"doc-url":          "http://example.com/test",
+			"implicit-on-core": true,
+		},
+	})
+}
+
+func (s *interfacesSuite) TestInterfacesModernPaging(c *check.C) {
+	s.daemon(c)
+
+	// Interfaces are sorted by name, the name of the last interface of a
+	// page is the cursor for the next one.
+	var names []string
+	after := ""
+	for {
+		req, err := http.NewRequest("GET", "/v2/interfaces?select=all&limit=50&after="+after, nil)
+		c.Assert(err, check.IsNil)
+		rsp := s.syncReq(c, req, nil)
+		page := rsp.Result.([]*daemon.InterfaceJSON)
+		c.Assert(len(page) <= 50, check.Equals, true)
+		if len(page) == 0 {
+			break
+		}
+		for _, ifaceJSON := range page {
+			names = append(names, ifaceJSON.Name)
+		}
+		after = page[len(page)-1].Name
+	}
+
+	req, err := http.NewRequest("GET", "/v2/interfaces?select=all", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	all := rsp.Result.([]*daemon.InterfaceJSON)
+	c.Assert(len(all) > 50, check.Equals, true)
+	c.Assert(names, check.HasLen, len(all))
+	for i := range all {
+		c.Check(names[i], check.Equals, all[i].Name)
+	}
+}
+
+func (s *interfacesSuite) TestInterfacesModernBadLimit(c *check.C) {
+	s.daemon(c)
+
+	for _, limit := range []string{"-1", "foo"} {
+		req, err := http.NewRequest("GET", "/v2/interfaces?select=all&limit="+limit, nil)
+		c.Assert(err, check.IsNil)
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Equals, fmt.Sprintf("invalid limit: %q", limit))
+	}
+}
+
+func (s *interfacesSuite) TestInterfacesModernWithConnections(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	repo := d.Overlord().InterfaceManager().Repository()
+	connRef := &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}
+	_, err := repo.Connect(connRef, nil, nil, nil, nil, nil)
+	c.Assert(err, check.IsNil)
+
+	req, err := http.NewRequest("GET", "/v2/interfaces?select=all&names=test&plugs=true&slots=true&connections=true", nil)
+	c.Assert(err, check.IsNil)
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil).ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+	var body map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &body)
+	c.Check(err, check.IsNil)
+	c.Check(body["result"], check.DeepEquals, []interface{}{
+		map[string]interface{}{
+			"name": "test",
+			"plugs": []interface{}{
+				map[string]interface{}{
+					"snap":  "consumer",
+					"plug":  "plug",
+					"label": "label",
+					"attrs": map[string]interface{}{
+						"key": "value",
+					},
+					"connections": []interface{}{
+						map[string]interface{}{"snap": "producer", "slot": "slot"},
+					},
+				}},
+			"slots": []interface{}{
+				map[string]interface{}{
+					"snap":  "producer",
+					"slot":  "slot",
+					"label": "label",
+					"attrs": map[string]interface{}{
+						"key": "value",
+					},
+					"connections": []interface{}{
+						map[string]interface{}{"snap": "consumer", "plug": "plug"},
+					},
+				},
+			},
+		},
+	})
+}
+
+type pipeResponseWriter struct {
+	*io.PipeWriter
+	header http.Header
+}
+
+func (w *pipeResponseWriter) Header() http.Header { return w.header }
+func (w *pipeResponseWriter) WriteHeader(int)     {}
+
+func (s *interfacesSuite) TestInterfaceEvents(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequest("GET", "/v2/interfaces/events", nil)
+	c.Assert(err, check.IsNil)
+	req = req.WithContext(ctx)
+	rsp := s.req(c, req, nil)
+
+	// changes made after the request was handled are streamed
+	repo := d.Overlord().InterfaceManager().Repository()
+	connRef := &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}
+	_, err = repo.Connect(connRef, nil, nil, nil, nil, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(repo.RemoveSnap("other"), check.IsNil)
+	c.Assert(repo.Disconnect("consumer", "plug", "producer", "slot"), check.IsNil)
+	c.Assert(repo.RemoveSnap("consumer"), check.IsNil)
+
+	pr, pw := io.Pipe()
+	w := &pipeResponseWriter{PipeWriter: pw, header: http.Header{}}
+	done := make(chan struct{})
+	go func() {
+		rsp.ServeHTTP(w, req)
+		pw.Close()
+		close(done)
+	}()
+
+	r := bufio.NewReader(pr)
+	var events []map[string]interface{}
+	for len(events) < 3 {
+		line, err := r.ReadBytes('\n')
+		c.Assert(err, check.IsNil)
+		c.Assert(line[0], check.Equals, byte(0x1E))
+		var ev map[string]interface{}
+		c.Assert(json.Unmarshal(line[1:], &ev), check.IsNil)
+		events = append(events, ev)
+	}
+	cancel()
+	<-done
+
+	c.Check(w.header.Get("Content-Type"), check.Equals, "application/json-seq")
+	c.Check(events, check.DeepEquals, []map[string]interface{}{{
+		"kind": "connected",
+		"snap": "consumer",
+		"plug": map[string]interface{}{"snap": "consumer", "plug": "plug"},
+		"slot": map[string]interface{}{"snap": "producer", "slot": "slot"},
+	}, {
+		"kind": "disconnected",
+		"snap": "consumer",
+		"plug": map[string]interface{}{"snap": "consumer", "plug": "plug"},
+		"slot": map[string]interface{}{"snap": "producer", "slot": "slot"},
+	}, {
+		"kind": "snap-removed",
+		"snap": "consumer",
+	}})
+}
+
+func (s *interfacesSuite) TestInterfaceEventsOverflow(c *check.C) {
+	restore := builtin.MockInterface(&ifacetest.TestInterface{InterfaceName: "test"})
+	defer restore()
+	restore = daemon.MockInterfaceEventsBufferSize(1)
+	defer restore()
+
+	d := s.daemon(c)
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	req, err := http.NewRequest("GET", "/v2/interfaces/events", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.req(c, req, nil)
+
+	repo := d.Overlord().InterfaceManager().Repository()
+	c.Assert(repo.RemoveSnap("consumer"), check.IsNil)
+	c.Assert(repo.RemoveSnap("producer"), check.IsNil)
+
+	rec := httptest.NewRecorder()
+	rsp.ServeHTTP(rec, req)
+	c.Check(rec.Code, check.Equals, 200)
+	c.Check(strings.HasSuffix(rec.Body.String(), "\x1E{\"error\": \"too many pending events\"}\n"), check.Equals, true,
+		check.Commentf("%q", rec.Body.String()))
+}
+
+func (s *interfacesSuite) TestResolveForcedDisconnectQueries(c *check.C) {
+	connRef := &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}
+	repo := ifacetest.MockRepository(c, ifacetest.RepositoryFixture{
+		Interfaces:  []interfaces.Interface{&ifacetest.TestInterface{InterfaceName: "test"}},
+		Snaps:       []string{consumerYaml, producerYaml},
+		Connections: []string{connRef.ID()},
+	})
+	rec := &ifacetest.RecordingRepository{Repo: repo}
+
+	conns, err := daemon.ResolveForcedDisconnect(rec,
+		[]daemon.PlugJSON{{Snap: "consumer", Name: "plug"}},
+		[]daemon.SlotJSON{{Snap: "producer"}})
+	c.Assert(err, check.IsNil)
+	c.Check(conns, check.DeepEquals, []*interfaces.ConnRef{connRef})
+	c.Check(rec.Calls(), check.DeepEquals, []ifacetest.RepositoryCall{
+		{Method: "Connected", Args: []interface{}{"consumer", "plug"}},
+		{Method: "Connections", Args: []interface{}{"producer"}},
+	})
+
+	rec.Errors = map[string]error{"Connections": fmt.Errorf("boom")}
+	_, err = daemon.ResolveForcedDisconnect(rec, nil, []daemon.SlotJSON{{Snap: "producer"}})
+	c.Check(err, check.ErrorMatches, "boom")
+}
@@ -54,14 +54,36 @@ type interfaceJSON struct {
 	DocURL  string      `json:"doc-url,omitempty"`
 	Plugs   []*plugJSON `json:"plugs,omitempty"`
 	Slots   []*slotJSON `json:"slots,omitempty"`
+
+	ImplicitOnCore    bool `json:"implicit-on-core,omitempty"`
+	ImplicitOnClassic bool `json:"implicit-on-classic,omitempty"`
 }
 
 // interfaceAction is an action performed on the interface system.
 type interfaceAction struct {
 	Action string     `json:"action"`
 	Forget bool       `json:"forget,omitempty"`
+	Force  bool       `json:"force,omitempty"`
+	DryRun bool       `json:"dry-run,omitempty"`
 	Plugs  []plugJSON `json:"plugs,omitempty"`
 	Slots  []slotJSON `json:"slots,omitempty"`
+	// Interface is the interface of a pending connection, whose plug or
+	// slot may not be available yet.
+	Interface string `json:"interface,omitempty"`
+}
+
+// connRefJSON identifies a connection between a plug and a slot.
+type connRefJSON struct {
+	Plug interfaces.PlugRef `json:"plug"`
+	Slot interfaces.SlotRef `json:"slot"`
+}
+
+// connectionPreviewJSON describes the snippets a connection would add to
+// the security artefacts of the snaps involved, per security system.
+type connectionPreviewJSON struct {
+	Plug     interfaces.PlugRef                                `json:"plug"`
+	Slot     interfaces.SlotRef                                `json:"slot"`
+	Snippets map[interfaces.SecuritySystem]map[string][]string `json:"snippets,omitempty"`
 }
 
 // connectionsJSON aids in marshalling information about a single connection
@@ -89,3 +111,12 @@ type connectionsJSON struct {
 	Plugs       []*plugJSON      `json:"plugs"`
 	Slots       []*slotJSON      `json:"slots"`
 }
+
+// interfaceEventJSON aids in marshaling interfaces.RepositoryEvent into JSON.
+type interfaceEventJSON struct {
+	Kind string              `json:"kind"`
+	Snap string              `json:"snap"`
+	Name string              `json:"name,omitempty"`
+	Plug *interfaces.PlugRef `json:"plug,omitempty"`
+	Slot *interfaces.SlotRef `json:"slot,omitempty"`
+}
@@ -52,7 +52,7 @@ var (
 	logoutCmd = &Command{
 		Path:        "/v2/logout",
 		POST:        logoutUser,
-		WriteAccess: authenticatedAccess{Polkit: polkitActionLogin},
+		WriteAccess: authenticatedAccess{Polkit: polkitActionLogin, AnyScope: true},
 	}
 
 	// backwards compat; to-be-deprecated
@@ -85,6 +85,7 @@ type userResponseData struct {
 
 	Macaroon   string   `json:"macaroon,omitempty"`
 	Discharges []string `json:"discharges,omitempty"`
+	Scopes     []string `json:"scopes,omitempty"`
 }
 
 var isEmailish = regexp.MustCompile(`.@.*\..`).MatchString
@@ -95,6 +96,8 @@ func loginUser(c *Command, r *http.Request, user *auth.UserState) Response {
 		Email    string `json:"email"`
 		Password string `json:"password"`
 		Otp      string `json:"otp"`
+		// Scopes optionally restricts what the new user is allowed to do
+		Scopes []string `json:"scopes"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -102,6 +105,15 @@ func loginUser(c *Command, r *http.Request, user *auth.UserState) Response {
 		return BadRequest("cannot decode login data from request body: %v", err)
 	}
 
+	for _, scope := range loginData.Scopes {
+		if !strutil.ListContains(validScopes, scope) {
+			return BadRequest("unknown scope %q", scope)
+		}
+	}
+	if user != nil && len(loginData.Scopes) > 0 {
+		return BadRequest("cannot change the scopes of a logged in user")
+	}
+
 	if loginData.Email == "" && isEmailish(loginData.Username) {
 		// for backwards compatibility, if no email is provided assume username is the email
 		loginData.Email = loginData.Username
@@ -170,6 +182,10 @@ func loginUser(c *Command, r *http.Request, user *auth.UserState) Response {
 		err = auth.UpdateUser(st, user)
 	} else {
 		user, err = auth.NewUser(st, loginData.Username, loginData.Email, macaroon, []string{discharge})
+		if err == nil && len(loginData.Scopes) > 0 {
+			user.Scopes = loginData.Scopes
+			err = auth.UpdateUser(st, user)
+		}
 	}
 	st.Unlock()
 	if err != nil {
@@ -182,6 +198,7 @@ func loginUser(c *Command, r *http.Request, user *auth.UserState) Response {
 		Email:      user.Email,
 		Macaroon:   user.Macaroon,
 		Discharges: user.Discharges,
+		Scopes:     user.Scopes,
 	}
 	return SyncResponse(result)
 }
@@ -161,6 +161,60 @@ func (s *userSuite) TestLoginUser(c *check.C) {
 	c.Check(snapdMacaroon.Location(), check.Equals, "snapd")
 }
 
+func (s *userSuite) TestLoginUserWithScopes(c *check.C) {
+	state := s.d.Overlord().State()
+
+	s.expectLoginAccess()
+
+	s.loginUserStoreMacaroon = "user-macaroon"
+	s.loginUserDischarge = "the-discharge-macaroon-serialized-data"
+	buf := bytes.NewBufferString(`{"username": "email@.com", "password": "password", "scopes": ["read"]}`)
+	req, err := http.NewRequest("POST", "/v2/login", buf)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil)
+
+	state.Lock()
+	user, err := auth.User(state, 1)
+	state.Unlock()
+	c.Assert(err, check.IsNil)
+	c.Check(user.Scopes, check.DeepEquals, []string{"read"})
+
+	c.Check(rsp.Result, check.DeepEquals, daemon.UserResponseData{
+		ID:    1,
+		Email: "email@.com",
+
+		Macaroon:   user.Macaroon,
+		Discharges: user.Discharges,
+		Scopes:     []string{"read"},
+	})
+}
+
+func (s *userSuite) TestLoginUserWithScopesErrors(c *check.C) {
+	state := s.d.Overlord().State()
+
+	s.expectLoginAccess()
+
+	buf := bytes.NewBufferString(`{"username": "email@.com", "password": "password", "scopes": ["read", "everything"]}`)
+	req, err := http.NewRequest("POST", "/v2/login", buf)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `unknown scope "everything"`)
+
+	state.Lock()
+	localUser, err := auth.NewUser(state, "username", "email@test.com", "", nil)
+	state.Unlock()
+	c.Assert(err, check.IsNil)
+
+	buf = bytes.NewBufferString(`{"username": "email@.com", "password": "password", "scopes": ["read"]}`)
+	req, err = http.NewRequest("POST", "/v2/login", buf)
+	c.Assert(err, check.IsNil)
+	rspe = s.errorReq(c, req, localUser)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, "cannot change the scopes of a logged in user")
+}
+
 func (s *userSuite) TestLoginUserWithUsername(c *check.C) {
 	state := s.d.Overlord().State()
 
@@ -336,7 +390,7 @@ func (s *userSuite) TestLoginUserNewEmailWithExistentLocalUser(c *check.C) {
 func (s *userSuite) TestLogoutUser(c *check.C) {
 	state := s.d.Overlord().State()
 
-	s.expectLoginAccess()
+	s.expectWriteAccess(daemon.AuthenticatedAccess{Polkit: "io.snapcraft.snapd.login", AnyScope: true})
 
 	state.Lock()
 	user, err := auth.NewUser(state, "username", "email@test.com", "macaroon", []string{"discharge"})
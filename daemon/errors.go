@@ -26,6 +26,8 @@ import (
 
 	"github.com/snapcore/snapd/arch"
 	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/snap"
@@ -264,6 +266,26 @@ func AuthCancelled(format string, v ...interface{}) *apiError {
 	}
 }
 
+// InterfacesPlugOrSlotNotFound is an error responder used when an
+// interfaces' operation refers to a plug or slot that doesn't exist.
+func InterfacesPlugOrSlotNotFound(err *interfaces.NoPlugOrSlotError) *apiError {
+	return &apiError{
+		Status:  404,
+		Message: err.Error(),
+		Kind:    client.ErrorKindInterfacesPlugOrSlotNotFound,
+	}
+}
+
+// InterfacesConnectionNotAllowed is an error responder used when the
+// policy rules do not allow a requested connection.
+func InterfacesConnectionNotAllowed(err *ifacestate.ErrConnectNotAllowed) *apiError {
+	return &apiError{
+		Status:  403,
+		Message: err.Error(),
+		Kind:    client.ErrorKindInterfacesConnectionNotAllowed,
+	}
+}
+
 // InterfacesUnchanged is an error responder used when an operation
 // that would normally change interfaces finds it has nothing to do
 func InterfacesUnchanged(format string, v ...interface{}) *apiError {
@@ -332,6 +354,10 @@ func errToResponse(err error, snaps []string, fallback errorResponder, format st
 			snapName = err.Snap
 		case *snapstate.InsufficientSpaceError:
 			return InsufficientSpace(err)
+		case *interfaces.NoPlugOrSlotError:
+			return InterfacesPlugOrSlotNotFound(err)
+		case *ifacestate.ErrConnectNotAllowed:
+			return InterfacesConnectionNotAllowed(err)
 		case net.Error:
 			if err.Timeout() {
 				kind = client.ErrorKindNetworkTimeout
@@ -29,6 +29,8 @@ import (
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/store"
@@ -234,6 +236,33 @@ func (s *errorsSuite) TestErrToResponseInsufficentSpace(c *C) {
 	})
 }
 
+func (s *errorsSuite) TestErrToResponseNoPlugOrSlot(c *C) {
+	_, err := interfaces.NewRepository().ResolveConnect("foo", "plug", "bar", "slot")
+	c.Assert(err, FitsTypeOf, &interfaces.NoPlugOrSlotError{})
+	rspe := daemon.ErrToResponse(err, nil, daemon.BadRequest, "%s: %v", "ERR")
+	c.Check(rspe, DeepEquals, &daemon.APIError{
+		Status:  404,
+		Message: err.Error(),
+		Kind:    client.ErrorKindInterfacesPlugOrSlotNotFound,
+	})
+}
+
+func (s *errorsSuite) TestErrToResponseConnectNotAllowed(c *C) {
+	err := &ifacestate.ErrConnectNotAllowed{
+		Connection: interfaces.ConnRef{
+			PlugRef: interfaces.PlugRef{Snap: "foo", Name: "plug"},
+			SlotRef: interfaces.SlotRef{Snap: "bar", Name: "slot"},
+		},
+		Err: errors.New("connection denied by plug rule of interface \"iface\""),
+	}
+	rspe := daemon.ErrToResponse(err, nil, daemon.BadRequest, "%s: %v", "ERR")
+	c.Check(rspe, DeepEquals, &daemon.APIError{
+		Status:  403,
+		Message: `cannot connect foo:plug bar:slot: connection denied by plug rule of interface "iface"`,
+		Kind:    client.ErrorKindInterfacesConnectionNotAllowed,
+	})
+}
+
 func (s *errorsSuite) TestAuthCancelled(c *C) {
 	c.Check(daemon.AuthCancelled("auth cancelled"), DeepEquals, &daemon.APIError{
 		Status:  403,
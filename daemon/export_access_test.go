@@ -28,12 +28,13 @@ import (
 type (
 	AccessChecker = accessChecker
 
-	OpenAccess                = openAccess
-	AuthenticatedAccess       = authenticatedAccess
-	RootAccess                = rootAccess
-	SnapAccess                = snapAccess
-	ThemesOpenAccess          = themesOpenAccess
-	ThemesAuthenticatedAccess = themesAuthenticatedAccess
+	OpenAccess                    = openAccess
+	AuthenticatedAccess           = authenticatedAccess
+	InterfacesAuthenticatedAccess = interfacesAuthenticatedAccess
+	RootAccess                    = rootAccess
+	SnapAccess                    = snapAccess
+	ThemesOpenAccess              = themesOpenAccess
+	ThemesAuthenticatedAccess     = themesAuthenticatedAccess
 )
 
 var CheckPolkitActionImpl = checkPolkitActionImpl
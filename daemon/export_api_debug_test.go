@@ -26,3 +26,8 @@ type (
 var (
 	MinLane = minLane
 )
+
+type (
+	InterfacesHealth        = interfacesHealth
+	InterfacesHealthFailure = interfacesHealthFailure
+)
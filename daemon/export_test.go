@@ -26,6 +26,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/restart"
@@ -115,6 +116,14 @@ func MockAssertstateRefreshSnapAssertions(mock func(*state.State, int, *assertst
 	}
 }
 
+func MockIfacestateCheckConnectPolicy(mock func(*state.State, *interfaces.Repository, *interfaces.ConnRef) error) (restore func()) {
+	oldIfacestateCheckConnectPolicy := ifacestateCheckConnectPolicy
+	ifacestateCheckConnectPolicy = mock
+	return func() {
+		ifacestateCheckConnectPolicy = oldIfacestateCheckConnectPolicy
+	}
+}
+
 func MockSnapstateInstall(mock func(context.Context, *state.State, string, *snapstate.RevisionOptions, int, snapstate.Flags) (*state.TaskSet, error)) (restore func()) {
 	oldSnapstateInstall := snapstateInstall
 	snapstateInstall = mock
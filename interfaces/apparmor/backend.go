@@ -595,6 +595,20 @@ func (b *Backend) Remove(snapName string) error {
 	return errUnload
 }
 
+// ProfileGlobs returns the globs matching apparmor profiles of a given snap.
+func (b *Backend) ProfileGlobs(snapName string) []string {
+	globs := profileGlobs(snapName)
+	for i := range globs {
+		globs[i] = filepath.Join(dirs.SnapAppArmorDir, globs[i])
+	}
+	return globs
+}
+
+// SpecificationSnippets returns the apparmor snippets of a given specification.
+func (b *Backend) SpecificationSnippets(spec interfaces.Specification) map[string][]string {
+	return spec.(*Specification).Snippets()
+}
+
 func (b *Backend) RemoveLate(snapName string, rev snap.Revision, typ snap.Type) error {
 	logger.Debugf("remove late for snap %v (%s) type %v", snapName, rev, typ)
 	if typ != snap.TypeSnapd {
@@ -2073,6 +2073,15 @@ func (s *backendSuite) TestProfileGlobs(c *C) {
 	c.Assert(globs, DeepEquals, []string{"snap.foo.*", "snap-update-ns.foo"})
 }
 
+func (s *backendSuite) TestBackendProfileGlobs(c *C) {
+	globber, ok := s.Backend.(interfaces.SecurityBackendProfileGlobs)
+	c.Assert(ok, Equals, true)
+	c.Check(globber.ProfileGlobs("samba"), DeepEquals, []string{
+		filepath.Join(dirs.SnapAppArmorDir, "snap.samba.*"),
+		filepath.Join(dirs.SnapAppArmorDir, "snap-update-ns.samba"),
+	})
+}
+
 func (s *backendSuite) TestNsProfile(c *C) {
 	c.Assert(apparmor.NsProfile("foo"), Equals, "snap-update-ns.foo")
 }
@@ -2435,3 +2444,19 @@ func (s *backendSuite) TestSetupManyInPreseedMode(c *C) {
 		s.RemoveSnap(c, snapInfo2)
 	}
 }
+
+func (s *backendSuite) TestSpecificationSnippets(c *C) {
+	s.Iface.AppArmorPermanentSlotCallback = func(spec *apparmor.Specification, slot *snap.SlotInfo) error {
+		spec.AddSnippet("/dev/foo rw,")
+		return nil
+	}
+	snapInfo := snaptest.MockInfo(c, ifacetest.SambaYamlV1, nil)
+	spec := s.Backend.NewSpecification()
+	c.Assert(spec.AddPermanentSlot(s.Iface, snapInfo.Slots["slot"]), IsNil)
+
+	snippeter, ok := s.Backend.(interfaces.SecurityBackendSnippets)
+	c.Assert(ok, Equals, true)
+	c.Check(snippeter.SpecificationSnippets(spec), DeepEquals, map[string][]string{
+		"snap.samba.smbd": {"/dev/foo rw,"},
+	})
+}
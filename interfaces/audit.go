@@ -0,0 +1,142 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/snapcore/snapd/dirs"
+)
+
+// auditConnection describes a single connection in the audit bundle.
+type auditConnection struct {
+	Interface   string                 `json:"interface"`
+	Plug        PlugRef                `json:"plug"`
+	Slot        SlotRef                `json:"slot"`
+	PlugStatic  map[string]interface{} `json:"plug-static,omitempty"`
+	PlugDynamic map[string]interface{} `json:"plug-dynamic,omitempty"`
+	SlotStatic  map[string]interface{} `json:"slot-static,omitempty"`
+	SlotDynamic map[string]interface{} `json:"slot-dynamic,omitempty"`
+}
+
+// ExportSecurityState writes a tar archive describing the confinement of the
+// given snap to w.
+//
+// The archive contains a connections.json file with the connections of the
+// snap, together with their attributes, and a copy of every file generated
+// for the snap by backends implementing SecurityBackendProfileGlobs, stored
+// under a directory named after the backend at their path relative to the
+// root directory, as backends may use several directories with files of the
+// same name.
+func (r *Repository) ExportSecurityState(snapName string, w io.Writer) error {
+	connRefs, err := r.Connections(snapName)
+	if err != nil {
+		return err
+	}
+	conns := make([]auditConnection, 0, len(connRefs))
+	for _, connRef := range connRefs {
+		conn, err := r.Connection(connRef)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, auditConnection{
+			Interface:   conn.Interface(),
+			Plug:        connRef.PlugRef,
+			Slot:        connRef.SlotRef,
+			PlugStatic:  conn.Plug.StaticAttrs(),
+			PlugDynamic: conn.Plug.DynamicAttrs(),
+			SlotStatic:  conn.Slot.StaticAttrs(),
+			SlotDynamic: conn.Slot.DynamicAttrs(),
+		})
+	}
+	connsJSON, err := json.MarshalIndent(conns, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	hdr := &tar.Header{
+		Name: "connections.json",
+		Mode: 0644,
+		Size: int64(len(connsJSON)),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := tw.Write(connsJSON); err != nil {
+		return err
+	}
+
+	for _, backend := range r.Backends() {
+		globber, ok := backend.(SecurityBackendProfileGlobs)
+		if !ok {
+			continue
+		}
+		for _, glob := range globber.ProfileGlobs(snapName) {
+			matches, err := filepath.Glob(glob)
+			if err != nil {
+				return err
+			}
+			sort.Strings(matches)
+			for _, path := range matches {
+				rel, err := filepath.Rel(dirs.GlobalRootDir, path)
+				if err != nil {
+					return err
+				}
+				name := filepath.Join(string(backend.Name()), rel)
+				if err := addFileToTar(tw, name, path); err != nil {
+					return fmt.Errorf("cannot export %s security state of snap %q: %v", backend.Name(), snapName, err)
+				}
+			}
+		}
+	}
+	return tw.Close()
+}
+
+func addFileToTar(tw *tar.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil
+	}
+	hdr, err := tar.FileInfoHeader(fi, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
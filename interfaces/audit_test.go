@@ -0,0 +1,151 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	. "github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/kmod"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type auditSuite struct {
+	testutil.BaseTest
+	dir  string
+	repo *Repository
+}
+
+var _ = Suite(&auditSuite{})
+
+type globbingBackend struct {
+	ifacetest.TestSecurityBackend
+	dir string
+}
+
+func (b *globbingBackend) ProfileGlobs(snapName string) []string {
+	return []string{filepath.Join(b.dir, "snap."+snapName+".*")}
+}
+
+func (s *auditSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.BaseTest.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() { dirs.SetRootDir("") })
+	s.dir = filepath.Join(dirs.GlobalRootDir, "profiles")
+	c.Assert(os.MkdirAll(s.dir, 0755), IsNil)
+
+	s.repo = NewRepository()
+	c.Assert(s.repo.AddInterface(&ifacetest.TestInterface{InterfaceName: "interface"}), IsNil)
+	c.Assert(s.repo.AddBackend(&globbingBackend{
+		TestSecurityBackend: ifacetest.TestSecurityBackend{BackendName: "globbing"},
+		dir:                 s.dir,
+	}), IsNil)
+	// a backend that doesn't know about its files is skipped
+	c.Assert(s.repo.AddBackend(&ifacetest.TestSecurityBackend{BackendName: "opaque"}), IsNil)
+
+	consumer := snaptest.MockInfo(c, consumerYaml, nil)
+	producer := snaptest.MockInfo(c, producerYaml, nil)
+	c.Assert(s.repo.AddSnap(consumer), IsNil)
+	c.Assert(s.repo.AddSnap(producer), IsNil)
+	connRef := NewConnRef(consumer.Plugs["plug"], producer.Slots["slot"])
+	_, err := s.repo.Connect(connRef, nil, map[string]interface{}{"dyn": "value"}, nil, nil, nil)
+	c.Assert(err, IsNil)
+}
+
+func readTar(c *C, r io.Reader) map[string]string {
+	files := make(map[string]string)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		data, err := ioutil.ReadAll(tr)
+		c.Assert(err, IsNil)
+		files[hdr.Name] = string(data)
+	}
+	return files
+}
+
+func (s *auditSuite) TestExportSecurityState(c *C) {
+	c.Assert(ioutil.WriteFile(filepath.Join(s.dir, "snap.consumer.app"), []byte("app profile"), 0644), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.dir, "snap.consumer.hook.configure"), []byte("hook profile"), 0644), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.dir, "snap.producer.app"), []byte("other profile"), 0644), IsNil)
+	// directories are not exported
+	c.Assert(os.Mkdir(filepath.Join(s.dir, "snap.consumer.dir"), 0755), IsNil)
+
+	var buf bytes.Buffer
+	c.Assert(s.repo.ExportSecurityState("consumer", &buf), IsNil)
+
+	files := readTar(c, &buf)
+	c.Check(files, HasLen, 3)
+	c.Check(files["globbing/profiles/snap.consumer.app"], Equals, "app profile")
+	c.Check(files["globbing/profiles/snap.consumer.hook.configure"], Equals, "hook profile")
+
+	var conns []map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(files["connections.json"]), &conns), IsNil)
+	c.Check(conns, DeepEquals, []map[string]interface{}{{
+		"interface":    "interface",
+		"plug":         map[string]interface{}{"snap": "consumer", "plug": "plug"},
+		"slot":         map[string]interface{}{"snap": "producer", "slot": "slot"},
+		"plug-static":  map[string]interface{}{"attr": "value"},
+		"plug-dynamic": map[string]interface{}{"dyn": "value"},
+		"slot-static":  map[string]interface{}{"attr": "value"},
+	}})
+}
+
+func (s *auditSuite) TestExportSecurityStateKMod(c *C) {
+	// the kmod backend uses files of the same name in two directories
+	c.Assert(s.repo.AddBackend(&kmod.Backend{}), IsNil)
+	for _, dir := range []string{dirs.SnapKModModulesDir, dirs.SnapKModModprobeDir} {
+		c.Assert(os.MkdirAll(dir, 0755), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, "snap.consumer.conf"), []byte(dir), 0644), IsNil)
+	}
+
+	var buf bytes.Buffer
+	c.Assert(s.repo.ExportSecurityState("consumer", &buf), IsNil)
+
+	files := readTar(c, &buf)
+	c.Check(files, HasLen, 3)
+	c.Check(files["kmod/etc/modules-load.d/snap.consumer.conf"], Equals, dirs.SnapKModModulesDir)
+	c.Check(files["kmod/etc/modprobe.d/snap.consumer.conf"], Equals, dirs.SnapKModModprobeDir)
+}
+
+func (s *auditSuite) TestExportSecurityStateUnknownSnap(c *C) {
+	var buf bytes.Buffer
+	c.Assert(s.repo.ExportSecurityState("unknown", &buf), IsNil)
+
+	files := readTar(c, &buf)
+	c.Check(files, DeepEquals, map[string]string{"connections.json": "[]"})
+}
@@ -121,3 +121,19 @@ type SecurityBackendDiscardingLate interface {
 	// step of the remove change.
 	RemoveLate(snapName string, rev snap.Revision, typ snap.Type) error
 }
+
+// SecurityBackendProfileGlobs interface may be implemented by backends that
+// can tell where the security artefacts of a given snap are stored.
+type SecurityBackendProfileGlobs interface {
+	// ProfileGlobs returns glob patterns, in the form of absolute paths,
+	// matching the files generated by the backend for the given snap.
+	ProfileGlobs(snapName string) []string
+}
+
+// SecurityBackendSnippets interface may be implemented by backends whose
+// specifications hold snippets of text that end up in security artefacts.
+type SecurityBackendSnippets interface {
+	// SpecificationSnippets returns the snippets held by a specification
+	// created by the backend, keyed by security tag.
+	SpecificationSnippets(spec Specification) map[string][]string
+}
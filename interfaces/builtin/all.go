@@ -20,6 +20,7 @@
 package builtin
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 
@@ -65,6 +66,23 @@ func registerIface(iface interfaces.Interface) {
 	allInterfaces[iface.Name()] = iface
 }
 
+// ValidateSnapYaml checks the plugs and slots declared in the given
+// snap.yaml against the built-in interfaces and the rules they have for
+// their attributes, as is done when the snap is installed. It does not need
+// snapd to be running and is meant for tools that build snaps.
+func ValidateSnapYaml(yamlData []byte) error {
+	// snap.SanitizePlugsSlots may be a no-op in the calling program,
+	// sanitize explicitly
+	snapInfo, err := snap.InfoFromSnapYamlWithSanitizer(yamlData, SanitizePlugsSlotsForPack)
+	if err != nil {
+		return err
+	}
+	if len(snapInfo.BadInterfaces) > 0 {
+		return errors.New(snap.BadInterfacesSummary(snapInfo))
+	}
+	return nil
+}
+
 func SanitizePlugsSlots(snapInfo *snap.Info) {
 	var badPlugs []string
 	var badSlots []string
@@ -110,6 +128,12 @@ func SanitizePlugsSlots(snapInfo *snap.Info) {
 	}
 
 	// remove any bad plugs and slots
+	removePlugsSlots(snapInfo, badPlugs, badSlots)
+}
+
+// removePlugsSlots removes the given plugs and slots from the snap and from
+// its apps and hooks.
+func removePlugsSlots(snapInfo *snap.Info, badPlugs, badSlots []string) {
 	for _, plugName := range badPlugs {
 		delete(snapInfo.Plugs, plugName)
 		for _, app := range snapInfo.Apps {
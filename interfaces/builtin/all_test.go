@@ -373,6 +373,64 @@ func (s *AllSuite) TestSanitizeErrorsOnInvalidPlugInterface(c *C) {
 	c.Assert(snapInfo.Slots, HasLen, 0)
 }
 
+func (s *AllSuite) TestValidateSnapYaml(c *C) {
+	calls := 0
+	restore := builtin.MockInterfaces(map[string]interfaces.Interface{
+		"iface": &ifacetest.TestInterface{
+			InterfaceName: "iface",
+			BeforePreparePlugCallback: func(plug *snap.PlugInfo) error {
+				calls++
+				if _, ok := plug.Attrs["path"]; !ok {
+					return fmt.Errorf("iface plug requires a path")
+				}
+				return nil
+			},
+		},
+	})
+	defer restore()
+	restore = snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {})
+	defer restore()
+
+	goodYaml := []byte(`name: producer
+version: 0
+plugs:
+  good:
+    interface: iface
+    path: /dev/foo
+slots:
+  iface:
+`)
+	c.Check(builtin.ValidateSnapYaml(goodYaml), IsNil)
+	c.Check(calls, Equals, 1)
+
+	// the plugs and slots are sanitized once, even when the program
+	// sanitizes them on its own
+	restore = snap.MockSanitizePlugsSlots(builtin.SanitizePlugsSlots)
+	c.Check(builtin.ValidateSnapYaml(goodYaml), IsNil)
+	c.Check(calls, Equals, 2)
+	restore()
+
+	err := builtin.ValidateSnapYaml([]byte(`name: producer
+version: 0
+plugs:
+  no-path:
+    interface: iface
+  unknown:
+    interface: potato
+slots:
+  ttyS5:
+    interface: iface
+`))
+	c.Check(err, ErrorMatches, `snap "producer" has bad plugs or slots: no-path \(iface plug requires a path\); ttyS5 \(invalid slot name: "ttyS5"\); unknown \(unknown interface "potato"\)`)
+
+	err = builtin.ValidateSnapYaml([]byte(`name: producer
+version: 0
+plugs:
+  broken: 5
+`))
+	c.Check(err, ErrorMatches, `line 4: plug "broken" has malformed definition \(found int\)`)
+}
+
 func (s *AllSuite) TestUnexpectedSpecSignatures(c *C) {
 	type funcSig struct {
 		name string
@@ -37,6 +37,7 @@ const boolFileBaseDeclarationSlots = `
       slot-snap-type:
         - core
         - gadget
+        - kernel
     deny-auto-connection: true
 `
 
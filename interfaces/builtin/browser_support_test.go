@@ -73,6 +73,8 @@ func (s *BrowserSupportInterfaceSuite) TestSanitizeSlot(c *C) {
 
 func (s *BrowserSupportInterfaceSuite) TestSanitizePlugNoAttrib(c *C) {
 	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+	// the default of allow-sandbox is not recorded as an attribute
+	c.Check(s.plugInfo.Attrs, HasLen, 0)
 }
 
 func (s *BrowserSupportInterfaceSuite) TestSanitizePlugWithAttrib(c *C) {
@@ -33,6 +33,7 @@ const dspBaseDeclarationSlots = `
       slot-snap-type:
         - gadget
         - core
+        - kernel
     deny-auto-connection: true
 `
 
@@ -25,8 +25,8 @@ import (
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
 	"github.com/snapcore/snapd/snap"
-	"github.com/snapcore/snapd/snap/snaptest"
 )
 
 var (
@@ -66,35 +66,19 @@ func MustInterface(name string) interfaces.Interface {
 }
 
 func MockPlug(c *C, yaml string, si *snap.SideInfo, plugName string) *snap.PlugInfo {
-	info := snaptest.MockInfo(c, yaml, si)
-	if plugInfo, ok := info.Plugs[plugName]; ok {
-		return plugInfo
-	}
-	panic(fmt.Sprintf("cannot find plug %q in snap %q", plugName, info.InstanceName()))
+	return ifacetest.MockPlug(c, yaml, si, plugName)
 }
 
 func MockSlot(c *C, yaml string, si *snap.SideInfo, slotName string) *snap.SlotInfo {
-	info := snaptest.MockInfo(c, yaml, si)
-	if slotInfo, ok := info.Slots[slotName]; ok {
-		return slotInfo
-	}
-	panic(fmt.Sprintf("cannot find slot %q in snap %q", slotName, info.InstanceName()))
+	return ifacetest.MockSlot(c, yaml, si, slotName)
 }
 
 func MockConnectedPlug(c *C, yaml string, si *snap.SideInfo, plugName string) (*interfaces.ConnectedPlug, *snap.PlugInfo) {
-	info := snaptest.MockInfo(c, yaml, si)
-	if plugInfo, ok := info.Plugs[plugName]; ok {
-		return interfaces.NewConnectedPlug(plugInfo, nil, nil), plugInfo
-	}
-	panic(fmt.Sprintf("cannot find plug %q in snap %q", plugName, info.InstanceName()))
+	return ifacetest.MockConnectedPlug(c, yaml, si, plugName)
 }
 
 func MockConnectedSlot(c *C, yaml string, si *snap.SideInfo, slotName string) (*interfaces.ConnectedSlot, *snap.SlotInfo) {
-	info := snaptest.MockInfo(c, yaml, si)
-	if slotInfo, ok := info.Slots[slotName]; ok {
-		return interfaces.NewConnectedSlot(slotInfo, nil, nil), slotInfo
-	}
-	panic(fmt.Sprintf("cannot find slot %q in snap %q", slotName, info.InstanceName()))
+	return ifacetest.MockConnectedSlot(c, yaml, si, slotName)
 }
 
 func MockOsGetenv(mock func(string) string) (restore func()) {
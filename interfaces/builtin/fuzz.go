@@ -0,0 +1,36 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build gofuzz
+// +build gofuzz
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+// FuzzValidateSnapYaml is an entry point for go-fuzz. It parses the input as
+// snap.yaml and runs the sanitizers of the built-in interfaces over the
+// declared plugs and slots. It is only built with the gofuzz tag, the seed
+// corpus lives in testdata/fuzz/snap-yaml:
+//
+//	go-fuzz-build -func FuzzValidateSnapYaml github.com/snapcore/snapd/interfaces/builtin
+//	go-fuzz -bin builtin-fuzz.zip -workdir testdata/fuzz/snap-yaml
+func FuzzValidateSnapYaml(data []byte) int {
+	if err := ValidateSnapYaml(data); err != nil {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,49 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build gofuzz
+// +build gofuzz
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces/builtin"
+)
+
+type fuzzSuite struct{}
+
+var _ = Suite(&fuzzSuite{})
+
+func (s *fuzzSuite) TestSnapYamlCorpus(c *C) {
+	seeds, err := filepath.Glob("testdata/fuzz/snap-yaml/corpus/*")
+	c.Assert(err, IsNil)
+	c.Assert(seeds, Not(HasLen), 0)
+	accepted := 0
+	for _, seed := range seeds {
+		data, err := ioutil.ReadFile(seed)
+		c.Assert(err, IsNil)
+		accepted += builtin.FuzzValidateSnapYaml(data)
+	}
+	// the last seed uses an unknown interface
+	c.Check(accepted, Equals, len(seeds)-1)
+}
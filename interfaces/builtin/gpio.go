@@ -38,6 +38,7 @@ const gpioBaseDeclarationSlots = `
       slot-snap-type:
         - core
         - gadget
+        - kernel
     deny-auto-connection: true
 `
 
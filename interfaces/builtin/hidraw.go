@@ -39,6 +39,7 @@ const hidrawBaseDeclarationSlots = `
       slot-snap-type:
         - core
         - gadget
+        - kernel
     deny-auto-connection: true
 `
 
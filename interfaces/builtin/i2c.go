@@ -39,6 +39,7 @@ const i2cBaseDeclarationSlots = `
       slot-snap-type:
         - gadget
         - core
+        - kernel
     deny-auto-connection: true
 `
 
@@ -39,6 +39,7 @@ const iioBaseDeclarationSlots = `
       slot-snap-type:
         - gadget
         - core
+        - kernel
     deny-auto-connection: true
 `
 
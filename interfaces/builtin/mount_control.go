@@ -429,22 +429,8 @@ func (iface *mountControlInterface) AppArmorConnectedPlug(spec *apparmor.Specifi
 	enumerateMounts(plug, func(mountInfo *MountInfo) error {
 
 		source := mountInfo.what
-		target := mountInfo.where
-		if target[0] == '$' {
-			matches := whereRegexp.FindStringSubmatchIndex(target)
-			if matches == nil || len(matches) < 4 {
-				// This cannot really happen, as the string wouldn't pass the validation
-				return fmt.Errorf(`internal error: "where" fails to match regexp: %q`, mountInfo.where)
-			}
-			// the first two elements in "matches" are the boundaries of the whole
-			// string; the next two are the boundaries of the first match, which is
-			// what we care about as it contains the environment variable we want
-			// to expand:
-			variableStart, variableEnd := matches[2], matches[3]
-			variable := target[variableStart:variableEnd]
-			expanded := snapInfo.ExpandSnapVariables(variable)
-			target = expanded + target[variableEnd:]
-		}
+		// the declared "where" is kept as is and only expanded here
+		target := interfaces.ExpandAttrVariables(snapInfo, mountInfo.where)
 
 		var typeRule string
 		if optionIncompatibleWithFsType(mountInfo.options) != "" {
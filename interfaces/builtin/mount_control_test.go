@@ -29,6 +29,7 @@ import (
 	"github.com/snapcore/snapd/interfaces/builtin"
 	"github.com/snapcore/snapd/interfaces/seccomp"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -100,6 +101,17 @@ func (s *MountControlInterfaceSuite) TestSanitizePlug(c *C) {
 	c.Check(interfaces.BeforeConnectPlug(s.iface, s.plug), IsNil)
 }
 
+func (s *MountControlInterfaceSuite) TestSanitizePlugKeepsDeclaredWhere(c *C) {
+	snapInfo := snaptest.MockInfo(c, mountControlConsumerYaml, nil)
+	builtin.SanitizePlugsSlots(snapInfo)
+	c.Assert(snapInfo.BadInterfaces, HasLen, 0)
+	mounts := snapInfo.Plugs["mntctl"].Attrs["mount"].([]interface{})
+	c.Assert(mounts, HasLen, 4)
+	c.Check(mounts[0].(map[string]interface{})["where"], Equals, "/media/**")
+	c.Check(mounts[1].(map[string]interface{})["where"], Equals, "$SNAP_COMMON/**")
+	c.Check(mounts[3].(map[string]interface{})["where"], Equals, "$SNAP_COMMON/{foo,other,**}")
+}
+
 func (s *MountControlInterfaceSuite) TestSanitizePlugOldSystemd(c *C) {
 	restore := systemd.MockSystemdVersion(208, nil)
 	defer restore()
@@ -36,6 +36,7 @@ const netlinkDriverBaseDeclarationSlots = `
       slot-snap-type:
         - core
         - gadget
+        - kernel
     deny-auto-connection: true
 `
 
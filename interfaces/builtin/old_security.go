@@ -0,0 +1,160 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package builtin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+)
+
+const oldSecuritySummary = `allows the raw security policy of legacy snaps`
+
+const oldSecurityBaseDeclarationPlugs = `
+  old-security:
+    allow-installation: false
+    deny-auto-connection: true
+`
+
+const oldSecurityBaseDeclarationSlots = `
+  old-security:
+    allow-installation:
+      slot-snap-type:
+        - core
+    deny-auto-connection: true
+`
+
+const oldSecurityConnectedPlugAppArmor = `
+# Description: Rules shipped by a legacy snap in %q.
+# This is restricted because the rules are not reviewed by snapd.
+%s
+`
+
+const oldSecurityConnectedPlugSecComp = `
+# Description: Rules shipped by a legacy snap in %q.
+%s
+`
+
+// oldSecurityInterface wraps the raw apparmor and seccomp policy files that
+// legacy snaps declared under "security-policy", so that the policy is only
+// used while the plug holding it is connected. The plugs are created when
+// the snap.yaml is read and name the files through the "apparmor" and
+// "seccomp" attributes, as paths relative to the root of the snap.
+type oldSecurityInterface struct {
+	commonInterface
+}
+
+// policyPath checks that the value of the given attribute names a clean path
+// relative to the root of the snap.
+func (iface *oldSecurityInterface) policyPath(attr string, value interface{}) (string, error) {
+	path, ok := value.(string)
+	if !ok || path == "" {
+		return "", fmt.Errorf("%s plug requires %q to be a path", iface.name, attr)
+	}
+	if filepath.IsAbs(path) || filepath.Clean(path) != path || strings.HasPrefix(path, "../") || path == ".." {
+		return "", fmt.Errorf("%s plug requires %q to be a clean path relative to the snap, got %q", iface.name, attr, path)
+	}
+	return path, nil
+}
+
+func (iface *oldSecurityInterface) BeforePreparePlug(plug *snap.PlugInfo) error {
+	found := false
+	for _, attr := range []string{"apparmor", "seccomp"} {
+		value, ok := plug.Attrs[attr]
+		if !ok {
+			continue
+		}
+		if _, err := iface.policyPath(attr, value); err != nil {
+			return err
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf(`%s plug requires "apparmor" or "seccomp" to be set`, iface.name)
+	}
+	return nil
+}
+
+// readPolicy returns the content of the policy file named by the given
+// attribute of the plug, or an empty string if the attribute is not set.
+// Only the static attributes from the snap.yaml are considered and the path
+// is checked again, as is the location of the file after resolving symbolic
+// links, so that the policy can only come from inside the snap.
+func (iface *oldSecurityInterface) readPolicy(plug *interfaces.ConnectedPlug, attr string) (path, policy string, err error) {
+	value, ok := plug.StaticAttrs()[attr]
+	if !ok {
+		return "", "", nil
+	}
+	if path, err = iface.policyPath(attr, value); err != nil {
+		return "", "", err
+	}
+	mountDir, err := filepath.EvalSymlinks(plug.Snap().MountDir())
+	if err != nil {
+		return "", "", fmt.Errorf("cannot read %s policy of plug %q: %v", attr, plug.Name(), err)
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(mountDir, path))
+	if err != nil {
+		return "", "", fmt.Errorf("cannot read %s policy of plug %q: %v", attr, plug.Name(), err)
+	}
+	if !strings.HasPrefix(resolved, mountDir+"/") {
+		return "", "", fmt.Errorf("cannot read %s policy of plug %q: %q points outside of the snap", attr, plug.Name(), path)
+	}
+	content, err := ioutil.ReadFile(resolved)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot read %s policy of plug %q: %v", attr, plug.Name(), err)
+	}
+	return path, string(content), nil
+}
+
+func (iface *oldSecurityInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	path, policy, err := iface.readPolicy(plug, "apparmor")
+	if err != nil || path == "" {
+		return err
+	}
+	spec.AddSnippet(fmt.Sprintf(oldSecurityConnectedPlugAppArmor, path, policy))
+	return nil
+}
+
+func (iface *oldSecurityInterface) SecCompConnectedPlug(spec *seccomp.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	path, policy, err := iface.readPolicy(plug, "seccomp")
+	if err != nil || path == "" {
+		return err
+	}
+	spec.AddSnippet(fmt.Sprintf(oldSecurityConnectedPlugSecComp, path, policy))
+	return nil
+}
+
+func init() {
+	registerIface(&oldSecurityInterface{
+		commonInterface{
+			name:                 "old-security",
+			summary:              oldSecuritySummary,
+			implicitOnCore:       true,
+			implicitOnClassic:    true,
+			baseDeclarationPlugs: oldSecurityBaseDeclarationPlugs,
+			baseDeclarationSlots: oldSecurityBaseDeclarationSlots,
+		},
+	})
+}
@@ -0,0 +1,227 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package builtin_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type oldSecurityInterfaceSuite struct {
+	testutil.BaseTest
+
+	iface    interfaces.Interface
+	slot     *interfaces.ConnectedSlot
+	slotInfo *snap.SlotInfo
+	plug     *interfaces.ConnectedPlug
+	plugInfo *snap.PlugInfo
+}
+
+var _ = Suite(&oldSecurityInterfaceSuite{
+	iface: builtin.MustInterface("old-security"),
+})
+
+func (s *oldSecurityInterfaceSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(func() {
+		dirs.SetRootDir("/")
+	})
+
+	const mockPlugSnapInfo = `name: other
+version: 1.0
+apps:
+ app:
+  command: foo
+  security-policy:
+   apparmor: meta/app.apparmor
+   seccomp: meta/app.seccomp
+`
+	s.slotInfo = &snap.SlotInfo{
+		Snap:      &snap.Info{SuggestedName: "core", SnapType: snap.TypeOS},
+		Name:      "old-security",
+		Interface: "old-security",
+	}
+	s.slot = interfaces.NewConnectedSlot(s.slotInfo, nil, nil)
+	plugSnap := snaptest.MockSnap(c, mockPlugSnapInfo, &snap.SideInfo{
+		RealName: "other",
+		Revision: snap.R(1),
+	})
+	s.plugInfo = plugSnap.Plugs["old-security-app"]
+	s.plug = interfaces.NewConnectedPlug(s.plugInfo, nil, nil)
+}
+
+func (s *oldSecurityInterfaceSuite) mockPolicy(c *C, name, content string) {
+	path := filepath.Join(s.plugInfo.Snap.MountDir(), name)
+	c.Assert(os.MkdirAll(filepath.Dir(path), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(path, []byte(content), 0644), IsNil)
+}
+
+func (s *oldSecurityInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "old-security")
+}
+
+func (s *oldSecurityInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.slotInfo), IsNil)
+}
+
+func (s *oldSecurityInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+}
+
+func (s *oldSecurityInterfaceSuite) TestSanitizePlugUnhappy(c *C) {
+	for _, t := range []struct {
+		attrs  map[string]interface{}
+		errStr string
+	}{
+		{nil, `old-security plug requires "apparmor" or "seccomp" to be set`},
+		{map[string]interface{}{"apparmor": 42}, `old-security plug requires "apparmor" to be a path`},
+		{map[string]interface{}{"seccomp": ""}, `old-security plug requires "seccomp" to be a path`},
+		{map[string]interface{}{"apparmor": "/etc/foo"}, `old-security plug requires "apparmor" to be a clean path relative to the snap, got "/etc/foo"`},
+		{map[string]interface{}{"apparmor": "../foo"}, `old-security plug requires "apparmor" to be a clean path relative to the snap, got "../foo"`},
+		{map[string]interface{}{"seccomp": "meta/./foo"}, `old-security plug requires "seccomp" to be a clean path relative to the snap, got "meta/./foo"`},
+	} {
+		plug := &snap.PlugInfo{
+			Snap:      s.plugInfo.Snap,
+			Name:      "old-security-app",
+			Interface: "old-security",
+			Attrs:     t.attrs,
+		}
+		c.Check(interfaces.BeforePreparePlug(s.iface, plug), ErrorMatches, t.errStr, Commentf("%v", t.attrs))
+	}
+}
+
+func (s *oldSecurityInterfaceSuite) TestConnectedPlugAppArmor(c *C) {
+	s.mockPolicy(c, "meta/app.apparmor", "/var/lib/foo/** rw,")
+
+	apparmorSpec := &apparmor.Specification{}
+	err := apparmorSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(apparmorSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
+	c.Check(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, `# Description: Rules shipped by a legacy snap in "meta/app.apparmor".`)
+	c.Check(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, "/var/lib/foo/** rw,")
+}
+
+func (s *oldSecurityInterfaceSuite) TestConnectedPlugSecComp(c *C) {
+	s.mockPolicy(c, "meta/app.seccomp", "mknod\n")
+
+	seccompSpec := &seccomp.Specification{}
+	err := seccompSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Assert(seccompSpec.SecurityTags(), DeepEquals, []string{"snap.other.app"})
+	c.Check(seccompSpec.SnippetForTag("snap.other.app"), testutil.Contains, `# Description: Rules shipped by a legacy snap in "meta/app.seccomp".`)
+	c.Check(seccompSpec.SnippetForTag("snap.other.app"), testutil.Contains, "mknod\n")
+}
+
+func (s *oldSecurityInterfaceSuite) TestConnectedPlugMissingPolicy(c *C) {
+	apparmorSpec := &apparmor.Specification{}
+	err := apparmorSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, ErrorMatches, `cannot read apparmor policy of plug "old-security-app": lstat .*/meta/app.apparmor: no such file or directory`)
+}
+
+func (s *oldSecurityInterfaceSuite) TestConnectedPlugIgnoresDynamicAttrs(c *C) {
+	s.mockPolicy(c, "meta/app.apparmor", "/var/lib/foo/** rw,")
+	s.mockPolicy(c, "meta/evil.apparmor", "/** rwklix,")
+	plug := interfaces.NewConnectedPlug(s.plugInfo, nil, map[string]interface{}{
+		"apparmor": "meta/evil.apparmor",
+	})
+
+	apparmorSpec := &apparmor.Specification{}
+	err := apparmorSpec.AddConnectedPlug(s.iface, plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Check(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, "/var/lib/foo/** rw,")
+	c.Check(apparmorSpec.SnippetForTag("snap.other.app"), Not(testutil.Contains), "rwklix")
+}
+
+func (s *oldSecurityInterfaceSuite) TestConnectedPlugRevalidatesPath(c *C) {
+	plugInfo := &snap.PlugInfo{
+		Snap:      s.plugInfo.Snap,
+		Name:      "old-security-app",
+		Interface: "old-security",
+		Attrs:     map[string]interface{}{"apparmor": "../../../etc/passwd"},
+		Apps:      s.plugInfo.Apps,
+	}
+	plug := interfaces.NewConnectedPlug(plugInfo, nil, nil)
+
+	apparmorSpec := &apparmor.Specification{}
+	err := apparmorSpec.AddConnectedPlug(s.iface, plug, s.slot)
+	c.Assert(err, ErrorMatches, `old-security plug requires "apparmor" to be a clean path relative to the snap, got "../../../etc/passwd"`)
+}
+
+func (s *oldSecurityInterfaceSuite) TestConnectedPlugSymlinkOutsideSnap(c *C) {
+	outside := filepath.Join(dirs.GlobalRootDir, "outside")
+	c.Assert(ioutil.WriteFile(outside, []byte("/** rwklix,"), 0644), IsNil)
+	s.mockPolicy(c, "meta/app.seccomp", "mknod\n")
+	c.Assert(os.Symlink(outside, filepath.Join(s.plugInfo.Snap.MountDir(), "meta/app.apparmor")), IsNil)
+
+	apparmorSpec := &apparmor.Specification{}
+	err := apparmorSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, ErrorMatches, `cannot read apparmor policy of plug "old-security-app": "meta/app.apparmor" points outside of the snap`)
+}
+
+func (s *oldSecurityInterfaceSuite) TestConnectedPlugSymlinkInsideSnap(c *C) {
+	s.mockPolicy(c, "meta/common.apparmor", "/var/lib/foo/** rw,")
+	c.Assert(os.Symlink("common.apparmor", filepath.Join(s.plugInfo.Snap.MountDir(), "meta/app.apparmor")), IsNil)
+
+	apparmorSpec := &apparmor.Specification{}
+	err := apparmorSpec.AddConnectedPlug(s.iface, s.plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Check(apparmorSpec.SnippetForTag("snap.other.app"), testutil.Contains, "/var/lib/foo/** rw,")
+}
+
+func (s *oldSecurityInterfaceSuite) TestConnectedPlugOnlyDeclaredPolicy(c *C) {
+	plugSnap := snaptest.MockSnap(c, `name: other
+version: 1.0
+apps:
+ app:
+  command: foo
+  security-policy:
+   seccomp: meta/app.seccomp
+`, &snap.SideInfo{Revision: snap.R(2)})
+	plug := interfaces.NewConnectedPlug(plugSnap.Plugs["old-security-app"], nil, nil)
+
+	apparmorSpec := &apparmor.Specification{}
+	err := apparmorSpec.AddConnectedPlug(s.iface, plug, s.slot)
+	c.Assert(err, IsNil)
+	c.Check(apparmorSpec.SecurityTags(), HasLen, 0)
+}
+
+func (s *oldSecurityInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Check(si.ImplicitOnCore, Equals, true)
+	c.Check(si.ImplicitOnClassic, Equals, true)
+	c.Check(si.Summary, Equals, `allows the raw security policy of legacy snaps`)
+	c.Check(si.BaseDeclarationSlots, testutil.Contains, "old-security")
+}
+
+func (s *oldSecurityInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
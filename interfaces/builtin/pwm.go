@@ -39,6 +39,7 @@ const pwmBaseDeclarationSlots = `
       slot-snap-type:
         - core
         - gadget
+        - kernel
     deny-auto-connection: true
 `
 
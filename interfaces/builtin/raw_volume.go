@@ -43,6 +43,7 @@ const rawVolumeBaseDeclarationSlots = `
       slot-snap-type:
         - core
         - gadget
+        - kernel
     deny-connection: true
     deny-auto-connection: true
 `
@@ -0,0 +1,119 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package builtin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	allowedReservedNamesMu sync.Mutex
+	allowedReservedNames   map[string]bool
+)
+
+// AllowReservedNames sets the names of built-in interfaces that plugs and
+// slots of other interfaces may use nonetheless. Brands configure it through
+// the interfaces.allow-reserved-names system option, typically set by the
+// defaults of their gadget snap.
+func AllowReservedNames(names []string) {
+	allowedReservedNamesMu.Lock()
+	defer allowedReservedNamesMu.Unlock()
+
+	allowedReservedNames = make(map[string]bool, len(names))
+	for _, name := range names {
+		allowedReservedNames[name] = true
+	}
+}
+
+// checkReservedName returns an error if a plug or slot uses the name of a
+// built-in interface without being of that interface, which would make it
+// easy to mistake for a plug or slot of that interface.
+func checkReservedName(plugOrSlot, name, ifaceName string) error {
+	if name == ifaceName {
+		return nil
+	}
+	if _, ok := allInterfaces[name]; !ok {
+		return nil
+	}
+	allowedReservedNamesMu.Lock()
+	allowed := allowedReservedNames[name]
+	allowedReservedNamesMu.Unlock()
+	if allowed {
+		return nil
+	}
+	return fmt.Errorf("%s name %q is reserved for the %q interface, rename the %s or declare it with \"interface: %s\"",
+		plugOrSlot, name, name, plugOrSlot, name)
+}
+
+// reservedPlugsSlots returns the plugs and slots of the snap that use a
+// reserved name, along with the reason for each of them.
+func reservedPlugsSlots(snapInfo *snap.Info) (plugs, slots []string, reasons map[string]string) {
+	reasons = make(map[string]string)
+	for name, plug := range snapInfo.Plugs {
+		if err := checkReservedName("plug", name, plug.Interface); err != nil {
+			plugs = append(plugs, name)
+			reasons[name] = err.Error()
+		}
+	}
+	for name, slot := range snapInfo.Slots {
+		if err := checkReservedName("slot", name, slot.Interface); err != nil {
+			slots = append(slots, name)
+			reasons[name] = err.Error()
+		}
+	}
+	return plugs, slots, reasons
+}
+
+// CheckReservedNames returns an error if plugs or slots of the snap use the
+// name of a built-in interface without being of that interface. It is only
+// meant for snaps that are being installed, snaps that are already
+// installed keep their plugs, slots and connections.
+func CheckReservedNames(snapInfo *snap.Info) error {
+	_, _, reasons := reservedPlugsSlots(snapInfo)
+	if len(reasons) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(reasons))
+	for name := range reasons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	problems := make([]string, 0, len(names))
+	for _, name := range names {
+		problems = append(problems, fmt.Sprintf("%s (%s)", name, reasons[name]))
+	}
+	return fmt.Errorf("snap %q has bad plugs or slots: %s", snapInfo.InstanceName(), strings.Join(problems, "; "))
+}
+
+// SanitizePlugsSlotsForPack is like SanitizePlugsSlots but also reports the
+// plugs and slots using reserved names as bad. It is used when building
+// snaps, where the declarations can still be fixed.
+func SanitizePlugsSlotsForPack(snapInfo *snap.Info) {
+	SanitizePlugsSlots(snapInfo)
+	plugs, slots, reasons := reservedPlugsSlots(snapInfo)
+	for name, reason := range reasons {
+		snapInfo.BadInterfaces[name] = reason
+	}
+	removePlugsSlots(snapInfo, plugs, slots)
+}
@@ -0,0 +1,101 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+type ReservedNamesSuite struct{}
+
+var _ = Suite(&ReservedNamesSuite{})
+
+func (s *ReservedNamesSuite) TearDownTest(c *C) {
+	builtin.AllowReservedNames(nil)
+}
+
+const reservedNamesYaml = `name: consumer
+version: 0
+plugs:
+  network:
+    interface: home
+  home:
+    interface: home
+slots:
+  x11:
+    interface: wayland
+  wayland:
+    interface: wayland
+`
+
+func (s *ReservedNamesSuite) TestSanitizeKeepsReservedNames(c *C) {
+	snapInfo := snaptest.MockInfo(c, reservedNamesYaml, nil)
+	snap.SanitizePlugsSlots(snapInfo)
+	c.Check(snapInfo.BadInterfaces, HasLen, 0)
+	c.Check(snapInfo.Plugs, HasLen, 2)
+	c.Check(snapInfo.Slots, HasLen, 2)
+}
+
+func (s *ReservedNamesSuite) TestCheckReservedNames(c *C) {
+	snapInfo := snaptest.MockInfo(c, reservedNamesYaml, nil)
+	c.Check(builtin.CheckReservedNames(snapInfo), ErrorMatches, `snap "consumer" has bad plugs or slots: `+
+		`network \(plug name "network" is reserved for the "network" interface, rename the plug or declare it with "interface: network"\); `+
+		`x11 \(slot name "x11" is reserved for the "x11" interface, rename the slot or declare it with "interface: x11"\)`)
+	// nothing is removed
+	c.Check(snapInfo.Plugs, HasLen, 2)
+	c.Check(snapInfo.Slots, HasLen, 2)
+}
+
+func (s *ReservedNamesSuite) TestSanitizePlugsSlotsForPack(c *C) {
+	snapInfo := snaptest.MockInfo(c, reservedNamesYaml, nil)
+	builtin.SanitizePlugsSlotsForPack(snapInfo)
+	c.Check(snap.BadInterfacesSummary(snapInfo), Equals, `snap "consumer" has bad plugs or slots: `+
+		`network (plug name "network" is reserved for the "network" interface, rename the plug or declare it with "interface: network"); `+
+		`x11 (slot name "x11" is reserved for the "x11" interface, rename the slot or declare it with "interface: x11")`)
+	c.Check(snapInfo.Plugs, HasLen, 1)
+	c.Check(snapInfo.Plugs["home"], NotNil)
+	c.Check(snapInfo.Slots, HasLen, 1)
+	c.Check(snapInfo.Slots["wayland"], NotNil)
+}
+
+func (s *ReservedNamesSuite) TestValidateSnapYamlReservedNames(c *C) {
+	err := builtin.ValidateSnapYaml([]byte(reservedNamesYaml))
+	c.Check(err, ErrorMatches, `snap "consumer" has bad plugs or slots: network \(.*\); x11 \(.*\)`)
+}
+
+func (s *ReservedNamesSuite) TestReservedNamesAllowed(c *C) {
+	builtin.AllowReservedNames([]string{"network", "x11"})
+
+	snapInfo := snaptest.MockInfo(c, reservedNamesYaml, nil)
+	c.Check(builtin.CheckReservedNames(snapInfo), IsNil)
+	builtin.SanitizePlugsSlotsForPack(snapInfo)
+	c.Check(snapInfo.BadInterfaces, HasLen, 0)
+	c.Check(snapInfo.Plugs, HasLen, 2)
+	c.Check(snapInfo.Slots, HasLen, 2)
+
+	// setting the list again replaces the previous one
+	builtin.AllowReservedNames([]string{"x11"})
+	snapInfo = snaptest.MockInfo(c, reservedNamesYaml, nil)
+	c.Check(builtin.CheckReservedNames(snapInfo), ErrorMatches, `snap "consumer" has bad plugs or slots: network \(plug name "network" is reserved for the "network" interface, .*\)`)
+}
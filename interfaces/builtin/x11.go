@@ -166,6 +166,10 @@ socket AF_NETLINK - NETLINK_KOBJECT_UEVENT
 bind
 `
 
+// x11Interface allows talking to an X server, either the one of the host or
+// one provided by another snap. Note that X does not isolate its clients:
+// any connected snap can eavesdrop on input and on the windows of the other
+// clients, so the interface provides little confinement on its own.
 type x11Interface struct {
 	commonInterface
 }
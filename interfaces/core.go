@@ -33,6 +33,9 @@ func BeforePreparePlug(iface Interface, plugInfo *snap.PlugInfo) error {
 		return fmt.Errorf("cannot sanitize plug %q (interface %q) using interface %q",
 			PlugRef{Snap: plugInfo.Snap.InstanceName(), Name: plugInfo.Name}, plugInfo.Interface, iface.Name())
 	}
+	if defaulter, ok := iface.(AttrDefaulter); ok {
+		plugInfo.Attrs = mergeAttrDefaults(plugInfo.Attrs, defaulter.PlugAttrDefaults())
+	}
 	var err error
 	if iface, ok := iface.(PlugSanitizer); ok {
 		err = iface.BeforePreparePlug(plugInfo)
@@ -84,6 +87,9 @@ func BeforePrepareSlot(iface Interface, slotInfo *snap.SlotInfo) error {
 		return fmt.Errorf("cannot sanitize slot %q (interface %q) using interface %q",
 			SlotRef{Snap: slotInfo.Snap.InstanceName(), Name: slotInfo.Name}, slotInfo.Interface, iface.Name())
 	}
+	if defaulter, ok := iface.(AttrDefaulter); ok {
+		slotInfo.Attrs = mergeAttrDefaults(slotInfo.Attrs, defaulter.SlotAttrDefaults())
+	}
 	var err error
 	if iface, ok := iface.(SlotSanitizer); ok {
 		err = iface.BeforePrepareSlot(slotInfo)
@@ -91,6 +97,41 @@ func BeforePrepareSlot(iface Interface, slotInfo *snap.SlotInfo) error {
 	return err
 }
 
+// mergeAttrDefaults sets the attributes that are missing from attrs to
+// their default value and returns the resulting attributes.
+func mergeAttrDefaults(attrs, defaults map[string]interface{}) map[string]interface{} {
+	for name, value := range defaults {
+		if _, ok := attrs[name]; ok {
+			continue
+		}
+		if attrs == nil {
+			attrs = make(map[string]interface{}, len(defaults))
+		}
+		attrs[name] = copyAttrValue(value)
+	}
+	return attrs
+}
+
+// copyAttrValue returns a deep copy of an attribute value, so that default
+// values are never shared between plugs or slots.
+func copyAttrValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		cpy := make([]interface{}, len(v))
+		for i, item := range v {
+			cpy[i] = copyAttrValue(item)
+		}
+		return cpy
+	case map[string]interface{}:
+		cpy := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			cpy[key] = copyAttrValue(item)
+		}
+		return cpy
+	}
+	return value
+}
+
 // SlotRef is a reference to a slot.
 type SlotRef struct {
 	Snap string `json:"snap"`
@@ -124,6 +165,9 @@ type Info struct {
 	DocURL  string
 	Plugs   []*snap.PlugInfo
 	Slots   []*snap.SlotInfo
+
+	ImplicitOnCore    bool
+	ImplicitOnClassic bool
 }
 
 // ConnRef holds information about plug and slot reference that form a particular connection.
@@ -202,6 +246,14 @@ type SlotSanitizer interface {
 	BeforePrepareSlot(slot *snap.SlotInfo) error
 }
 
+// AttrDefaulter can be implemented by Interfaces that have default values for
+// some attributes of their plugs or slots. The defaults are set on the plugs
+// and slots that don't have those attributes before they are sanitized.
+type AttrDefaulter interface {
+	PlugAttrDefaults() map[string]interface{}
+	SlotAttrDefaults() map[string]interface{}
+}
+
 // StaticInfo describes various static-info of a given interface.
 //
 // The Summary must be a one-line string of length suitable for listing views.
@@ -279,3 +279,56 @@ slots:
 		InterfaceName: "other",
 	}, slot), ErrorMatches, `cannot sanitize slot "snap:slot" \(interface "iface"\) using interface "other"`)
 }
+
+func (s *CoreSuite) TestSanitizeMergesAttrDefaults(c *C) {
+	info := snaptest.MockInfo(c, `
+name: snap
+version: 0
+plugs:
+  plug:
+    interface: iface
+    read-only: false
+  bare-plug:
+    interface: iface
+slots:
+  slot:
+    interface: iface
+`, nil)
+	var sanitizedAttrs map[string]interface{}
+	iface := &ifacetest.TestInterface{
+		InterfaceName: "iface",
+		PlugAttrDefaultValues: map[string]interface{}{
+			"read-only": true,
+			"paths":     []interface{}{"/a"},
+		},
+		SlotAttrDefaultValues: map[string]interface{}{"mode": "shared"},
+		BeforePreparePlugCallback: func(plug *snap.PlugInfo) error {
+			// defaults are merged before the plug is sanitized
+			sanitizedAttrs = plug.Attrs
+			return nil
+		},
+	}
+
+	plug := info.Plugs["plug"]
+	c.Assert(interfaces.BeforePreparePlug(iface, plug), IsNil)
+	c.Check(plug.Attrs, DeepEquals, map[string]interface{}{
+		"read-only": false,
+		"paths":     []interface{}{"/a"},
+	})
+	c.Check(sanitizedAttrs, DeepEquals, plug.Attrs)
+
+	barePlug := info.Plugs["bare-plug"]
+	c.Assert(interfaces.BeforePreparePlug(iface, barePlug), IsNil)
+	c.Check(barePlug.Attrs, DeepEquals, map[string]interface{}{
+		"read-only": true,
+		"paths":     []interface{}{"/a"},
+	})
+	// default values are not shared between plugs
+	barePlug.Attrs["paths"].([]interface{})[0] = "/b"
+	c.Check(plug.Attrs["paths"], DeepEquals, []interface{}{"/a"})
+	c.Check(iface.PlugAttrDefaultValues["paths"], DeepEquals, []interface{}{"/a"})
+
+	slot := info.Slots["slot"]
+	c.Assert(interfaces.BeforePrepareSlot(iface, slot), IsNil)
+	c.Check(slot.Attrs, DeepEquals, map[string]interface{}{"mode": "shared"})
+}
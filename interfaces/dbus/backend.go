@@ -184,6 +184,17 @@ func (b *Backend) Remove(snapName string) error {
 	return nil
 }
 
+// ProfileGlobs returns the globs matching dbus configuration files of a given snap.
+func (b *Backend) ProfileGlobs(snapName string) []string {
+	glob := fmt.Sprintf("%s.conf", interfaces.SecurityTagGlob(snapName))
+	return []string{filepath.Join(dirs.SnapDBusSystemPolicyDir, glob)}
+}
+
+// SpecificationSnippets returns the dbus snippets of a given specification.
+func (b *Backend) SpecificationSnippets(spec interfaces.Specification) map[string][]string {
+	return spec.(*Specification).Snippets()
+}
+
 // deriveContent combines security snippets collected from all the interfaces
 // affecting a given snap into a content map applicable to EnsureDirState.
 func (b *Backend) deriveContent(spec *Specification, snapInfo *snap.Info) (content map[string]osutil.FileState) {
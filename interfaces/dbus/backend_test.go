@@ -385,3 +385,27 @@ func (s *backendSuite) TestSetupWritesDbusFilesBothSnapdAndCoreInstalled(c *C) {
 		c.Check(filepath.Join(dirs.GlobalRootDir, fn), testutil.FileEquals, fmt.Sprintf("content of %s for snap snapd", filepath.Base(fn)))
 	}
 }
+
+func (s *backendSuite) TestProfileGlobs(c *C) {
+	globber, ok := s.Backend.(interfaces.SecurityBackendProfileGlobs)
+	c.Assert(ok, Equals, true)
+	c.Check(globber.ProfileGlobs("samba"), DeepEquals, []string{
+		filepath.Join(dirs.SnapDBusSystemPolicyDir, "snap.samba.*.conf"),
+	})
+}
+
+func (s *backendSuite) TestSpecificationSnippets(c *C) {
+	s.Iface.DBusPermanentSlotCallback = func(spec *dbus.Specification, slot *snap.SlotInfo) error {
+		spec.AddSnippet("<policy/>")
+		return nil
+	}
+	snapInfo := snaptest.MockInfo(c, ifacetest.SambaYamlV1, nil)
+	spec := s.Backend.NewSpecification()
+	c.Assert(spec.AddPermanentSlot(s.Iface, snapInfo.Slots["slot"]), IsNil)
+
+	snippeter, ok := s.Backend.(interfaces.SecurityBackendSnippets)
+	c.Assert(ok, Equals, true)
+	c.Check(snippeter.SpecificationSnippets(spec), DeepEquals, map[string][]string{
+		"snap.samba.smbd": {"<policy/>"},
+	})
+}
@@ -0,0 +1,79 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package interfaces
+
+import (
+	"regexp"
+
+	"github.com/snapcore/snapd/snap"
+)
+
+var attrVariablePattern = regexp.MustCompile(`\$(?:\{([A-Za-z0-9_]+)\}|([A-Za-z0-9_]+))`)
+
+// ExpandAttrVariables expands the variables referring to the given snap in
+// an attribute value. Only $SNAP, $SNAP_DATA, $SNAP_COMMON, $SNAP_NAME,
+// $SNAP_INSTANCE_NAME and $SNAP_REVISION are expanded, any other variable is
+// left as is. The paths are those seen from inside the snap's mount
+// namespace.
+//
+// Attributes are stored and checked against the policy as declared by the
+// snap, interfaces expand them only when generating security snippets.
+func ExpandAttrVariables(snapInfo *snap.Info, value string) string {
+	return attrVariablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		sub := attrVariablePattern.FindStringSubmatch(match)
+		name := sub[1]
+		if name == "" {
+			name = sub[2]
+		}
+		switch name {
+		case "SNAP", "SNAP_DATA", "SNAP_COMMON":
+			return snapInfo.ExpandSnapVariables("$" + name)
+		case "SNAP_NAME":
+			return snapInfo.SnapName()
+		case "SNAP_INSTANCE_NAME":
+			return snapInfo.InstanceName()
+		case "SNAP_REVISION":
+			return snapInfo.Revision.String()
+		}
+		return match
+	})
+}
+
+// ExpandAttrValue returns a copy of a string attribute, or of a list or map
+// attribute, with the variables in its strings expanded as done by
+// ExpandAttrVariables.
+func ExpandAttrValue(snapInfo *snap.Info, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return ExpandAttrVariables(snapInfo, v)
+	case []interface{}:
+		expanded := make([]interface{}, len(v))
+		for i, item := range v {
+			expanded[i] = ExpandAttrValue(snapInfo, item)
+		}
+		return expanded
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			expanded[key] = ExpandAttrValue(snapInfo, item)
+		}
+		return expanded
+	}
+	return value
+}
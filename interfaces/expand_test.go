@@ -0,0 +1,83 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package interfaces_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	. "github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+type expandSuite struct{}
+
+var _ = Suite(&expandSuite{})
+
+func (s *expandSuite) TestExpandAttrVariables(c *C) {
+	info := snaptest.MockInfo(c, "name: foo\nversion: 0\n", &snap.SideInfo{Revision: snap.R(7)})
+	info.InstanceKey = "inst"
+
+	for _, t := range []struct {
+		value, expanded string
+	}{
+		// the snap is seen under its snap name in its mount namespace
+		{"$SNAP/bin", "/snap/foo/7/bin"},
+		{"${SNAP_DATA}/socket", "/var/snap/foo/7/socket"},
+		{"$SNAP_COMMON", "/var/snap/foo/common"},
+		{"$SNAP_NAME-$SNAP_INSTANCE_NAME-$SNAP_REVISION", "foo-foo_inst-7"},
+		{"$HOME/.config", "$HOME/.config"},
+		{"${SNAP_USER_DATA}/foo", "${SNAP_USER_DATA}/foo"},
+		{"$SNAPPY", "$SNAPPY"},
+		{"no variables", "no variables"},
+	} {
+		c.Check(ExpandAttrVariables(info, t.value), Equals, t.expanded, Commentf("%s", t.value))
+	}
+}
+
+func (s *expandSuite) TestExpandAttrValue(c *C) {
+	info := snaptest.MockInfo(c, "name: producer\nversion: 0\n", &snap.SideInfo{Revision: snap.R(3)})
+
+	value := []interface{}{
+		"${SNAP_COMMON}/cache",
+		map[string]interface{}{
+			"source": map[string]interface{}{
+				"read": []interface{}{"$SNAP_DATA/socket", 42},
+			},
+		},
+	}
+	c.Check(ExpandAttrValue(info, value), DeepEquals, []interface{}{
+		"/var/snap/producer/common/cache",
+		map[string]interface{}{
+			"source": map[string]interface{}{
+				"read": []interface{}{"/var/snap/producer/3/socket", 42},
+			},
+		},
+	})
+	// the value itself is left as declared
+	c.Check(value, DeepEquals, []interface{}{
+		"${SNAP_COMMON}/cache",
+		map[string]interface{}{
+			"source": map[string]interface{}{
+				"read": []interface{}{"$SNAP_DATA/socket", 42},
+			},
+		},
+	})
+	c.Check(ExpandAttrValue(info, true), Equals, true)
+}
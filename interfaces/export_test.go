@@ -73,3 +73,33 @@ func MockReadBuildID(mock func(p string) (string, error)) (restore func()) {
 type SystemKey = systemKey
 
 var SystemKeyVersion = systemKeyVersion
+
+func MockCheckRepoInvariants(enabled bool) (restore func()) {
+	old := checkRepoInvariants
+	checkRepoInvariants = enabled
+	return func() {
+		checkRepoInvariants = old
+	}
+}
+
+// The helpers below corrupt the internals of the repository to exercise
+// CheckInvariants.
+
+func (r *Repository) ForgetPlugInternal(snapName, plugName string) {
+	delete(r.plugs[snapName], plugName)
+}
+
+func (r *Repository) ReindexSlotInternal(snapName, slotName, newName string) {
+	r.slots[snapName][newName] = r.slots[snapName][slotName]
+	delete(r.slots[snapName], slotName)
+}
+
+func (r *Repository) ForgetSlotConnectionsInternal(snapName, slotName string) {
+	delete(r.slotPlugs, r.slots[snapName][slotName])
+}
+
+func (r *Repository) ReplaceConnectionInternal(plugSnap, plugName, slotSnap, slotName string) {
+	plug := r.plugs[plugSnap][plugName]
+	slot := r.slots[slotSnap][slotName]
+	r.plugSlots[plug][slot] = &Connection{Plug: &ConnectedPlug{plugInfo: plug}, Slot: &ConnectedSlot{slotInfo: slot}}
+}
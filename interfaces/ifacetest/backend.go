@@ -129,3 +129,41 @@ func (b *TestSecurityBackendDiscardingLate) RemoveLate(snapName string, rev snap
 	}
 	return b.RemoveLateCallback(snapName, rev, typ)
 }
+
+// RecordingSecurityBackend is a TestSecurityBackend that also renders the
+// specification of each snap it sets up, so that tests can check the policy
+// resulting from connections without any real security tooling. It uses
+// the Specification from this package.
+type RecordingSecurityBackend struct {
+	TestSecurityBackend
+	// Snippets maps snap instance names to the snippets rendered by the
+	// most recent successful call to Setup. Remove drops the entry.
+	Snippets map[string][]string
+}
+
+// Setup records information about the call, calls the setup callback if one
+// is defined and then records the snippets of the snap.
+func (b *RecordingSecurityBackend) Setup(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository, tm timings.Measurer) error {
+	if err := b.TestSecurityBackend.Setup(snapInfo, opts, repo, tm); err != nil {
+		return err
+	}
+	spec, err := repo.SnapSpecification(b.Name(), snapInfo.InstanceName())
+	if err != nil {
+		return err
+	}
+	if b.Snippets == nil {
+		b.Snippets = make(map[string][]string)
+	}
+	b.Snippets[snapInfo.InstanceName()] = append([]string(nil), spec.(*Specification).Snippets...)
+	return nil
+}
+
+// Remove records information about the call, calls the remove callback if
+// one is defined and forgets the snippets of the snap.
+func (b *RecordingSecurityBackend) Remove(snapName string) error {
+	if err := b.TestSecurityBackend.Remove(snapName); err != nil {
+		return err
+	}
+	delete(b.Snippets, snapName)
+	return nil
+}
@@ -0,0 +1,111 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest_test
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/snap"
+)
+
+type BackendSuite struct {
+	backend *ifacetest.RecordingSecurityBackend
+	repo    *interfaces.Repository
+}
+
+var _ = Suite(&BackendSuite{})
+
+func (s *BackendSuite) SetUpTest(c *C) {
+	s.backend = &ifacetest.RecordingSecurityBackend{
+		TestSecurityBackend: ifacetest.TestSecurityBackend{BackendName: "test"},
+	}
+	s.repo = ifacetest.MockRepository(c, ifacetest.RepositoryFixture{
+		Interfaces: []interfaces.Interface{&ifacetest.TestInterface{
+			InterfaceName: "iface",
+			TestConnectedPlugCallback: func(spec *ifacetest.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+				spec.AddSnippet("connected to " + slot.Snap().InstanceName())
+				return nil
+			},
+		}},
+		Backends: []interfaces.SecurityBackend{s.backend},
+		Snaps: []string{`name: consumer
+version: 0
+apps:
+  app:
+plugs:
+  plug:
+    interface: iface
+`, `name: producer
+version: 0
+slots:
+  slot:
+    interface: iface
+`},
+		Connections: []string{"consumer:plug producer:slot"},
+	})
+}
+
+func (s *BackendSuite) TestRecordsSnippets(c *C) {
+	consumer := s.repo.Plug("consumer", "plug").Snap
+	producer := s.repo.Slot("producer", "slot").Snap
+	opts := interfaces.ConfinementOptions{DevMode: true}
+
+	c.Assert(s.backend.Setup(consumer, opts, s.repo, nil), IsNil)
+	c.Assert(s.backend.Setup(producer, opts, s.repo, nil), IsNil)
+	c.Check(s.backend.SetupCalls, DeepEquals, []ifacetest.TestSetupCall{
+		{SnapInfo: consumer, Options: opts},
+		{SnapInfo: producer, Options: opts},
+	})
+	c.Check(s.backend.Snippets, DeepEquals, map[string][]string{
+		"consumer": {"connected to producer"},
+		"producer": nil,
+	})
+
+	c.Assert(s.repo.Disconnect("consumer", "plug", "producer", "slot"), IsNil)
+	c.Assert(s.backend.Setup(consumer, opts, s.repo, nil), IsNil)
+	c.Check(s.backend.Snippets["consumer"], HasLen, 0)
+
+	c.Assert(s.backend.Remove("consumer"), IsNil)
+	c.Check(s.backend.RemoveCalls, DeepEquals, []string{"consumer"})
+	c.Check(s.backend.Snippets, DeepEquals, map[string][]string{
+		"producer": nil,
+	})
+}
+
+func (s *BackendSuite) TestCallbackErrors(c *C) {
+	consumer := s.repo.Plug("consumer", "plug").Snap
+	s.backend.SetupCallback = func(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) error {
+		return errors.New("setup failed")
+	}
+	s.backend.RemoveCallback = func(snapName string) error {
+		return errors.New("remove failed")
+	}
+
+	c.Check(s.backend.Setup(consumer, interfaces.ConfinementOptions{}, s.repo, nil), ErrorMatches, "setup failed")
+	c.Check(s.backend.Snippets, HasLen, 0)
+
+	s.backend.Snippets = map[string][]string{"consumer": {"old"}}
+	c.Check(s.backend.Remove("consumer"), ErrorMatches, "remove failed")
+	c.Check(s.backend.Snippets, HasLen, 1)
+}
@@ -0,0 +1,195 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/dbus"
+	"github.com/snapcore/snapd/interfaces/kmod"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/interfaces/polkit"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/systemd"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/interfaces/utils"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+// ConformanceFixture describes the snaps CheckConformance uses to exercise
+// an interface.
+type ConformanceFixture struct {
+	// PlugSnapYaml is the snap.yaml of a snap with a plug of the
+	// interface. The first such plug, by name, is used.
+	PlugSnapYaml string
+	// SlotSnapYaml is the snap.yaml of a snap with a slot of the
+	// interface. The first such slot, by name, is used.
+	SlotSnapYaml string
+}
+
+func conformanceBackends() []interfaces.SecurityBackend {
+	return []interfaces.SecurityBackend{
+		&apparmor.Backend{},
+		&dbus.Backend{},
+		&kmod.Backend{},
+		&mount.Backend{},
+		&polkit.Backend{},
+		&seccomp.Backend{},
+		&systemd.Backend{},
+		&udev.Backend{},
+	}
+}
+
+// CheckConformance checks that an interface implementation follows the
+// rules expected by snapd, so that third-party implementations can be
+// checked from their own test packages:
+//
+//   - the name is a valid interface name and a summary is provided
+//   - sanitizing a plug or slot twice has the same result as doing it once
+//   - the specifications of all the security backends are the same when
+//     built twice from identical repositories
+//   - plugs and slots without any attributes are handled without panicking,
+//     both when sanitized and when added to specifications
+func CheckConformance(c *check.C, iface interfaces.Interface, fixture ConformanceFixture) {
+	name := iface.Name()
+	comment := check.Commentf("interface %q", name)
+	c.Check(snap.ValidateInterfaceName(name), check.IsNil, comment)
+	c.Check(interfaces.StaticInfoOf(iface).Summary, check.Not(check.Equals), "", comment)
+
+	plugInfo := conformancePlug(c, fixture.PlugSnapYaml, name)
+	slotInfo := conformanceSlot(c, fixture.SlotSnapYaml, name)
+
+	// sanitizing is idempotent
+	err1 := interfaces.BeforePreparePlug(iface, plugInfo)
+	attrs1 := copyAttrs(plugInfo.Attrs)
+	err2 := interfaces.BeforePreparePlug(iface, plugInfo)
+	c.Check(fmt.Sprint(err2), check.Equals, fmt.Sprint(err1), check.Commentf("interface %q: plug sanitized twice", name))
+	c.Check(plugInfo.Attrs, check.DeepEquals, attrs1, check.Commentf("interface %q: plug sanitized twice", name))
+	err1 = interfaces.BeforePrepareSlot(iface, slotInfo)
+	attrs1 = copyAttrs(slotInfo.Attrs)
+	err2 = interfaces.BeforePrepareSlot(iface, slotInfo)
+	c.Check(fmt.Sprint(err2), check.Equals, fmt.Sprint(err1), check.Commentf("interface %q: slot sanitized twice", name))
+	c.Check(slotInfo.Attrs, check.DeepEquals, attrs1, check.Commentf("interface %q: slot sanitized twice", name))
+
+	// snippets are deterministic
+	repo1 := conformanceRepository(c, iface, fixture)
+	repo2 := conformanceRepository(c, iface, fixture)
+	for _, backend := range conformanceBackends() {
+		for _, snapName := range []string{plugInfo.Snap.InstanceName(), slotInfo.Snap.InstanceName()} {
+			spec1, err := repo1.SnapSpecification(backend.Name(), snapName)
+			c.Assert(err, check.IsNil)
+			spec2, err := repo2.SnapSpecification(backend.Name(), snapName)
+			c.Assert(err, check.IsNil)
+			c.Check(spec2, check.DeepEquals, spec1, check.Commentf("interface %q: %s specification of snap %q", name, backend.Name(), snapName))
+		}
+	}
+
+	// plugs and slots without attributes do not cause panics
+	barePlug := &snap.PlugInfo{Snap: plugInfo.Snap, Name: plugInfo.Name, Interface: name, Apps: plugInfo.Apps, Hooks: plugInfo.Hooks}
+	bareSlot := &snap.SlotInfo{Snap: slotInfo.Snap, Name: slotInfo.Name, Interface: name, Apps: slotInfo.Apps, Hooks: slotInfo.Hooks}
+	checkNoPanic(c, fmt.Sprintf("interface %q: sanitizing a plug without attributes", name), func() {
+		interfaces.BeforePreparePlug(iface, barePlug)
+	})
+	checkNoPanic(c, fmt.Sprintf("interface %q: sanitizing a slot without attributes", name), func() {
+		interfaces.BeforePrepareSlot(iface, bareSlot)
+	})
+	connectedPlug := interfaces.NewConnectedPlug(barePlug, nil, nil)
+	connectedSlot := interfaces.NewConnectedSlot(bareSlot, nil, nil)
+	for _, backend := range conformanceBackends() {
+		spec := backend.NewSpecification()
+		checkNoPanic(c, fmt.Sprintf("interface %q: %s specification without attributes", name, backend.Name()), func() {
+			spec.AddPermanentPlug(iface, barePlug)
+			spec.AddPermanentSlot(iface, bareSlot)
+			spec.AddConnectedPlug(iface, connectedPlug, connectedSlot)
+			spec.AddConnectedSlot(iface, connectedPlug, connectedSlot)
+		})
+	}
+}
+
+func copyAttrs(attrs map[string]interface{}) map[string]interface{} {
+	if attrs == nil {
+		return nil
+	}
+	return utils.CopyAttributes(attrs)
+}
+
+func checkNoPanic(c *check.C, what string, f func()) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.Errorf("%s panicked: %v", what, err)
+		}
+	}()
+	f()
+}
+
+func conformancePlug(c *check.C, yaml, ifaceName string) *snap.PlugInfo {
+	info := snaptest.MockInfo(c, yaml, nil)
+	var names []string
+	for plugName, plug := range info.Plugs {
+		if plug.Interface == ifaceName {
+			names = append(names, plugName)
+		}
+	}
+	if len(names) == 0 {
+		c.Fatalf("snap %q has no plug of interface %q", info.InstanceName(), ifaceName)
+	}
+	sort.Strings(names)
+	return info.Plugs[names[0]]
+}
+
+func conformanceSlot(c *check.C, yaml, ifaceName string) *snap.SlotInfo {
+	info := snaptest.MockInfo(c, yaml, nil)
+	var names []string
+	for slotName, slot := range info.Slots {
+		if slot.Interface == ifaceName {
+			names = append(names, slotName)
+		}
+	}
+	if len(names) == 0 {
+		c.Fatalf("snap %q has no slot of interface %q", info.InstanceName(), ifaceName)
+	}
+	sort.Strings(names)
+	return info.Slots[names[0]]
+}
+
+// conformanceRepository returns a repository with the sanitized fixture
+// snaps connected to each other.
+func conformanceRepository(c *check.C, iface interfaces.Interface, fixture ConformanceFixture) *interfaces.Repository {
+	repo := interfaces.NewRepository()
+	c.Assert(repo.AddInterface(iface), check.IsNil)
+	for _, backend := range conformanceBackends() {
+		c.Assert(repo.AddBackend(backend), check.IsNil)
+	}
+	plugInfo := conformancePlug(c, fixture.PlugSnapYaml, iface.Name())
+	slotInfo := conformanceSlot(c, fixture.SlotSnapYaml, iface.Name())
+	c.Assert(interfaces.BeforePreparePlug(iface, plugInfo), check.IsNil)
+	c.Assert(interfaces.BeforePrepareSlot(iface, slotInfo), check.IsNil)
+	c.Assert(repo.AddSnap(plugInfo.Snap), check.IsNil)
+	c.Assert(repo.AddSnap(slotInfo.Snap), check.IsNil)
+	_, err := repo.Connect(interfaces.NewConnRef(plugInfo, slotInfo), nil, nil, nil, nil, nil)
+	c.Assert(err, check.IsNil)
+	return repo
+}
@@ -0,0 +1,114 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	// for interfaces.ByName
+	_ "github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/snap"
+)
+
+type ConformanceSuite struct{}
+
+var _ = Suite(&ConformanceSuite{})
+
+func (s *ConformanceSuite) TestTestInterface(c *C) {
+	iface := &ifacetest.TestInterface{
+		InterfaceName:       "iface",
+		InterfaceStaticInfo: interfaces.StaticInfo{Summary: "test interface"},
+		BeforePreparePlugCallback: func(plug *snap.PlugInfo) error {
+			if plug.Attrs == nil {
+				plug.Attrs = make(map[string]interface{})
+			}
+			if _, ok := plug.Attrs["path"]; !ok {
+				plug.Attrs["path"] = "/default"
+			}
+			return nil
+		},
+		AppArmorConnectedPlugCallback: func(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			var path string
+			plug.Attr("path", &path)
+			spec.AddSnippet(path + " r,")
+			return nil
+		},
+	}
+	ifacetest.CheckConformance(c, iface, ifacetest.ConformanceFixture{
+		PlugSnapYaml: `name: consumer
+version: 0
+apps:
+  app:
+plugs:
+  plug:
+    interface: iface
+    path: /foo
+`,
+		SlotSnapYaml: `name: producer
+version: 0
+apps:
+  app:
+slots:
+  slot:
+    interface: iface
+`,
+	})
+}
+
+func (s *ConformanceSuite) TestBuiltinInterfaces(c *C) {
+	for _, name := range []string{"network", "home", "dbus"} {
+		iface, err := interfaces.ByName(name)
+		c.Assert(err, IsNil)
+		fixture := ifacetest.ConformanceFixture{
+			PlugSnapYaml: `name: consumer
+version: 0
+apps:
+  app:
+plugs:
+  plug:
+    interface: ` + name + `
+`,
+			SlotSnapYaml: `name: core
+version: 0
+type: os
+slots:
+  slot:
+    interface: ` + name + `
+`,
+		}
+		if name == "dbus" {
+			fixture.PlugSnapYaml += "    bus: session\n    name: org.example.Foo\n"
+			fixture.SlotSnapYaml = `name: producer
+version: 0
+apps:
+  app:
+slots:
+  slot:
+    interface: dbus
+    bus: session
+    name: org.example.Foo
+`
+		}
+		ifacetest.CheckConformance(c, iface, fixture)
+	}
+}
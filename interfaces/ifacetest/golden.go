@@ -0,0 +1,104 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/udev"
+	"github.com/snapcore/snapd/testutil"
+)
+
+// UpdateGoldenEnv is the environment variable that makes CheckGolden
+// write the golden files instead of comparing against them.
+const UpdateGoldenEnv = "SNAPD_UPDATE_GOLDEN"
+
+func renderTaggedSnippets(buf *bytes.Buffer, snippets map[string][]string) {
+	tags := make([]string, 0, len(snippets))
+	for tag := range snippets {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		fmt.Fprintf(buf, "# %s\n", tag)
+		renderSnippets(buf, snippets[tag])
+	}
+}
+
+func renderSnippets(buf *bytes.Buffer, snippets []string) {
+	sorted := append([]string(nil), snippets...)
+	sort.Strings(sorted)
+	for _, snippet := range sorted {
+		buf.WriteString(strings.TrimRight(snippet, "\n"))
+		buf.WriteString("\n")
+	}
+}
+
+// RenderSnapPolicy returns the policy generated by the interfaces of the
+// given snap for the given security system, in a stable textual form
+// suitable for golden files. The repository must know a backend for the
+// security system, snippets are sorted so that the output does not depend
+// on the order in which the plugs and slots are visited.
+func RenderSnapPolicy(c *check.C, repo *interfaces.Repository, system interfaces.SecuritySystem, snapName string) string {
+	spec, err := repo.SnapSpecification(system, snapName)
+	c.Assert(err, check.IsNil)
+
+	var buf bytes.Buffer
+	switch spec := spec.(type) {
+	case *apparmor.Specification:
+		renderTaggedSnippets(&buf, spec.Snippets())
+		if updateNS := spec.UpdateNS(); len(updateNS) > 0 {
+			buf.WriteString("# snap-update-ns\n")
+			renderSnippets(&buf, updateNS)
+		}
+	case *seccomp.Specification:
+		renderTaggedSnippets(&buf, spec.Snippets())
+	case *udev.Specification:
+		renderSnippets(&buf, spec.Snippets())
+	case *Specification:
+		renderSnippets(&buf, spec.Snippets)
+	default:
+		c.Fatalf("cannot render specification of type %T", spec)
+	}
+	return buf.String()
+}
+
+// CheckGolden checks that the content of the golden file at the given path
+// is the actual content. When the environment variable named by
+// UpdateGoldenEnv is set to 1, the golden file is written instead.
+func CheckGolden(c *check.C, path, actual string) {
+	if os.Getenv(UpdateGoldenEnv) == "1" {
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0755), check.IsNil)
+		c.Assert(ioutil.WriteFile(path, []byte(actual), 0644), check.IsNil)
+		return
+	}
+	c.Check(path, testutil.FileEquals, actual)
+}
@@ -0,0 +1,114 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type GoldenSuite struct {
+	testutil.BaseTest
+	repo *interfaces.Repository
+}
+
+var _ = Suite(&GoldenSuite{})
+
+func (s *GoldenSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	iface := &ifacetest.TestInterface{
+		InterfaceName: "iface",
+		TestConnectedPlugCallback: func(spec *ifacetest.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddSnippet("connected " + slot.Snap().InstanceName())
+			return nil
+		},
+		TestPermanentPlugCallback: func(spec *ifacetest.Specification, plug *snap.PlugInfo) error {
+			spec.AddSnippet("permanent " + plug.Name)
+			return nil
+		},
+		AppArmorConnectedPlugCallback: func(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddSnippet("# connected to " + slot.Snap().InstanceName() + "\n")
+			return nil
+		},
+	}
+	s.repo = ifacetest.MockRepository(c, ifacetest.RepositoryFixture{
+		Interfaces: []interfaces.Interface{iface},
+		Backends: []interfaces.SecurityBackend{
+			&ifacetest.TestSecurityBackend{BackendName: "test"},
+			&apparmor.Backend{},
+		},
+		Snaps: []string{`name: consumer
+version: 0
+apps:
+  app:
+  other:
+plugs:
+  plug:
+    interface: iface
+`, `name: producer
+version: 0
+slots:
+  slot:
+    interface: iface
+`, `name: producer2
+version: 0
+slots:
+  slot:
+    interface: iface
+`},
+		Connections: []string{"consumer:plug producer:slot", "consumer:plug producer2:slot"},
+	})
+}
+
+func (s *GoldenSuite) TestRenderSnapPolicy(c *C) {
+	c.Check(ifacetest.RenderSnapPolicy(c, s.repo, "test", "consumer"), Equals, `connected producer
+connected producer2
+permanent plug
+`)
+	c.Check(ifacetest.RenderSnapPolicy(c, s.repo, "apparmor", "consumer"), Equals, `# snap.consumer.app
+# connected to producer
+# connected to producer2
+# snap.consumer.other
+# connected to producer
+# connected to producer2
+`)
+	c.Check(ifacetest.RenderSnapPolicy(c, s.repo, "apparmor", "producer"), Equals, "")
+}
+
+func (s *GoldenSuite) TestCheckGolden(c *C) {
+	path := filepath.Join(c.MkDir(), "golden", "consumer.test")
+	policy := ifacetest.RenderSnapPolicy(c, s.repo, "test", "consumer")
+
+	os.Setenv(ifacetest.UpdateGoldenEnv, "1")
+	s.AddCleanup(func() { os.Unsetenv(ifacetest.UpdateGoldenEnv) })
+	ifacetest.CheckGolden(c, path, policy)
+	c.Check(path, testutil.FileEquals, policy)
+
+	os.Unsetenv(ifacetest.UpdateGoldenEnv)
+	ifacetest.CheckGolden(c, path, policy)
+}
@@ -0,0 +1,62 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest
+
+import (
+	"fmt"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+// MockPlug returns the named plug of the snap described by the given yaml.
+func MockPlug(c *check.C, yaml string, si *snap.SideInfo, plugName string) *snap.PlugInfo {
+	info := snaptest.MockInfo(c, yaml, si)
+	if plugInfo, ok := info.Plugs[plugName]; ok {
+		return plugInfo
+	}
+	panic(fmt.Sprintf("cannot find plug %q in snap %q", plugName, info.InstanceName()))
+}
+
+// MockSlot returns the named slot of the snap described by the given yaml.
+func MockSlot(c *check.C, yaml string, si *snap.SideInfo, slotName string) *snap.SlotInfo {
+	info := snaptest.MockInfo(c, yaml, si)
+	if slotInfo, ok := info.Slots[slotName]; ok {
+		return slotInfo
+	}
+	panic(fmt.Sprintf("cannot find slot %q in snap %q", slotName, info.InstanceName()))
+}
+
+// MockConnectedPlug returns the named plug of the snap described by the
+// given yaml, along with a connected plug without dynamic attributes.
+func MockConnectedPlug(c *check.C, yaml string, si *snap.SideInfo, plugName string) (*interfaces.ConnectedPlug, *snap.PlugInfo) {
+	plugInfo := MockPlug(c, yaml, si, plugName)
+	return interfaces.NewConnectedPlug(plugInfo, nil, nil), plugInfo
+}
+
+// MockConnectedSlot returns the named slot of the snap described by the
+// given yaml, along with a connected slot without dynamic attributes.
+func MockConnectedSlot(c *check.C, yaml string, si *snap.SideInfo, slotName string) (*interfaces.ConnectedSlot, *snap.SlotInfo) {
+	slotInfo := MockSlot(c, yaml, si, slotName)
+	return interfaces.NewConnectedSlot(slotInfo, nil, nil), slotInfo
+}
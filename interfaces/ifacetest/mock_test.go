@@ -0,0 +1,72 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/snap"
+)
+
+type MockSuite struct{}
+
+var _ = Suite(&MockSuite{})
+
+const mockSnapYaml = `name: snap
+version: 0
+plugs:
+  plug:
+    interface: iface
+    attr: plug-value
+slots:
+  slot:
+    interface: iface
+    attr: slot-value
+`
+
+func (s *MockSuite) TestMockPlugAndSlot(c *C) {
+	plug := ifacetest.MockPlug(c, mockSnapYaml, &snap.SideInfo{Revision: snap.R(2)}, "plug")
+	c.Check(plug.Name, Equals, "plug")
+	c.Check(plug.Interface, Equals, "iface")
+	c.Check(plug.Snap.Revision, Equals, snap.R(2))
+
+	slot := ifacetest.MockSlot(c, mockSnapYaml, nil, "slot")
+	c.Check(slot.Name, Equals, "slot")
+	c.Check(slot.Snap.InstanceName(), Equals, "snap")
+
+	c.Check(func() { ifacetest.MockPlug(c, mockSnapYaml, nil, "missing") }, PanicMatches, `cannot find plug "missing" in snap "snap"`)
+	c.Check(func() { ifacetest.MockSlot(c, mockSnapYaml, nil, "missing") }, PanicMatches, `cannot find slot "missing" in snap "snap"`)
+}
+
+func (s *MockSuite) TestMockConnectedPlugAndSlot(c *C) {
+	plug, plugInfo := ifacetest.MockConnectedPlug(c, mockSnapYaml, nil, "plug")
+	c.Check(plug.Name(), Equals, "plug")
+	c.Check(plugInfo.Name, Equals, "plug")
+	var value string
+	c.Assert(plug.Attr("attr", &value), IsNil)
+	c.Check(value, Equals, "plug-value")
+
+	slot, slotInfo := ifacetest.MockConnectedSlot(c, mockSnapYaml, nil, "slot")
+	c.Check(slot.Name(), Equals, "slot")
+	c.Check(slotInfo.Name, Equals, "slot")
+	c.Assert(slot.Attr("attr", &value), IsNil)
+	c.Check(value, Equals, "slot-value")
+}
@@ -0,0 +1,217 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest
+
+import (
+	"sync"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/snap"
+)
+
+// RepositoryCall describes a call made to a RecordingRepository.
+type RepositoryCall struct {
+	// Method is the name of the called method.
+	Method string
+	// Args are the arguments of the call, except for functions.
+	Args []interface{}
+}
+
+// RecordingRepository implements interfaces.ConnectionRepository and
+// records all the calls made to it. Calls are forwarded to Repo when it is
+// set, otherwise they return zero values.
+type RecordingRepository struct {
+	// Repo is the repository the calls are forwarded to, if any.
+	Repo *interfaces.Repository
+	// Errors maps method names to errors returned by those methods
+	// instead of forwarding the call.
+	Errors map[string]error
+
+	m     sync.Mutex
+	calls []RepositoryCall
+}
+
+var _ interfaces.ConnectionRepository = (*RecordingRepository)(nil)
+
+// Calls returns the calls made so far.
+func (r *RecordingRepository) Calls() []RepositoryCall {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return append([]RepositoryCall(nil), r.calls...)
+}
+
+// CallsTo returns the arguments of the calls made so far to the given
+// method.
+func (r *RecordingRepository) CallsTo(method string) [][]interface{} {
+	r.m.Lock()
+	defer r.m.Unlock()
+	var args [][]interface{}
+	for _, call := range r.calls {
+		if call.Method == method {
+			args = append(args, call.Args)
+		}
+	}
+	return args
+}
+
+// record stores the call and returns the error configured for the method.
+func (r *RecordingRepository) record(method string, args ...interface{}) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.calls = append(r.calls, RepositoryCall{Method: method, Args: args})
+	return r.Errors[method]
+}
+
+func (r *RecordingRepository) Interface(interfaceName string) interfaces.Interface {
+	r.record("Interface", interfaceName)
+	if r.Repo == nil {
+		return nil
+	}
+	return r.Repo.Interface(interfaceName)
+}
+
+func (r *RecordingRepository) Info(opts *interfaces.InfoOptions) []*interfaces.Info {
+	r.record("Info", opts)
+	if r.Repo == nil {
+		return nil
+	}
+	return r.Repo.Info(opts)
+}
+
+func (r *RecordingRepository) Interfaces() *interfaces.Interfaces {
+	r.record("Interfaces")
+	if r.Repo == nil {
+		return &interfaces.Interfaces{}
+	}
+	return r.Repo.Interfaces()
+}
+
+func (r *RecordingRepository) AllPlugs(interfaceName string) []*snap.PlugInfo {
+	r.record("AllPlugs", interfaceName)
+	if r.Repo == nil {
+		return nil
+	}
+	return r.Repo.AllPlugs(interfaceName)
+}
+
+func (r *RecordingRepository) Plugs(snapName string) []*snap.PlugInfo {
+	r.record("Plugs", snapName)
+	if r.Repo == nil {
+		return nil
+	}
+	return r.Repo.Plugs(snapName)
+}
+
+func (r *RecordingRepository) Plug(snapName, plugName string) *snap.PlugInfo {
+	r.record("Plug", snapName, plugName)
+	if r.Repo == nil {
+		return nil
+	}
+	return r.Repo.Plug(snapName, plugName)
+}
+
+func (r *RecordingRepository) AllSlots(interfaceName string) []*snap.SlotInfo {
+	r.record("AllSlots", interfaceName)
+	if r.Repo == nil {
+		return nil
+	}
+	return r.Repo.AllSlots(interfaceName)
+}
+
+func (r *RecordingRepository) Slots(snapName string) []*snap.SlotInfo {
+	r.record("Slots", snapName)
+	if r.Repo == nil {
+		return nil
+	}
+	return r.Repo.Slots(snapName)
+}
+
+func (r *RecordingRepository) Slot(snapName, slotName string) *snap.SlotInfo {
+	r.record("Slot", snapName, slotName)
+	if r.Repo == nil {
+		return nil
+	}
+	return r.Repo.Slot(snapName, slotName)
+}
+
+func (r *RecordingRepository) ResolveConnect(plugSnapName, plugName, slotSnapName, slotName string) (*interfaces.ConnRef, error) {
+	if err := r.record("ResolveConnect", plugSnapName, plugName, slotSnapName, slotName); err != nil {
+		return nil, err
+	}
+	if r.Repo == nil {
+		return &interfaces.ConnRef{
+			PlugRef: interfaces.PlugRef{Snap: plugSnapName, Name: plugName},
+			SlotRef: interfaces.SlotRef{Snap: slotSnapName, Name: slotName},
+		}, nil
+	}
+	return r.Repo.ResolveConnect(plugSnapName, plugName, slotSnapName, slotName)
+}
+
+func (r *RecordingRepository) PreviewConnection(ref *interfaces.ConnRef) (map[interfaces.SecuritySystem]map[string][]string, error) {
+	if err := r.record("PreviewConnection", ref); err != nil {
+		return nil, err
+	}
+	if r.Repo == nil {
+		return nil, nil
+	}
+	return r.Repo.PreviewConnection(ref)
+}
+
+// Connect records the connection reference and the attributes, the policy
+// check is not recorded.
+func (r *RecordingRepository) Connect(ref *interfaces.ConnRef, plugStaticAttrs, plugDynamicAttrs, slotStaticAttrs, slotDynamicAttrs map[string]interface{}, policyCheck interfaces.PolicyFunc) (*interfaces.Connection, error) {
+	if err := r.record("Connect", ref, plugStaticAttrs, plugDynamicAttrs, slotStaticAttrs, slotDynamicAttrs); err != nil {
+		return nil, err
+	}
+	if r.Repo == nil {
+		return nil, nil
+	}
+	return r.Repo.Connect(ref, plugStaticAttrs, plugDynamicAttrs, slotStaticAttrs, slotDynamicAttrs, policyCheck)
+}
+
+func (r *RecordingRepository) Disconnect(plugSnapName, plugName, slotSnapName, slotName string) error {
+	if err := r.record("Disconnect", plugSnapName, plugName, slotSnapName, slotName); err != nil {
+		return err
+	}
+	if r.Repo == nil {
+		return nil
+	}
+	return r.Repo.Disconnect(plugSnapName, plugName, slotSnapName, slotName)
+}
+
+func (r *RecordingRepository) Connected(snapName, plugOrSlotName string) ([]*interfaces.ConnRef, error) {
+	if err := r.record("Connected", snapName, plugOrSlotName); err != nil {
+		return nil, err
+	}
+	if r.Repo == nil {
+		return nil, nil
+	}
+	return r.Repo.Connected(snapName, plugOrSlotName)
+}
+
+func (r *RecordingRepository) Connections(snapName string) ([]*interfaces.ConnRef, error) {
+	if err := r.record("Connections", snapName); err != nil {
+		return nil, err
+	}
+	if r.Repo == nil {
+		return nil, nil
+	}
+	return r.Repo.Connections(snapName)
+}
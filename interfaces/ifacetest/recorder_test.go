@@ -0,0 +1,103 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest_test
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+)
+
+type RecorderSuite struct{}
+
+var _ = Suite(&RecorderSuite{})
+
+func (s *RecorderSuite) TestWithoutRepository(c *C) {
+	rec := &ifacetest.RecordingRepository{}
+
+	connRef, err := rec.ResolveConnect("consumer", "plug", "producer", "slot")
+	c.Assert(err, IsNil)
+	c.Check(connRef.ID(), Equals, "consumer:plug producer:slot")
+	conn, err := rec.Connect(connRef, nil, map[string]interface{}{"a": 1}, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(conn, IsNil)
+	c.Check(rec.Plug("consumer", "plug"), IsNil)
+	c.Check(rec.Interfaces(), DeepEquals, &interfaces.Interfaces{})
+	c.Check(rec.Disconnect("consumer", "plug", "producer", "slot"), IsNil)
+
+	c.Check(rec.Calls(), DeepEquals, []ifacetest.RepositoryCall{
+		{Method: "ResolveConnect", Args: []interface{}{"consumer", "plug", "producer", "slot"}},
+		{Method: "Connect", Args: []interface{}{connRef, map[string]interface{}(nil), map[string]interface{}{"a": 1}, map[string]interface{}(nil), map[string]interface{}(nil)}},
+		{Method: "Plug", Args: []interface{}{"consumer", "plug"}},
+		{Method: "Interfaces", Args: nil},
+		{Method: "Disconnect", Args: []interface{}{"consumer", "plug", "producer", "slot"}},
+	})
+	c.Check(rec.CallsTo("Plug"), DeepEquals, [][]interface{}{{"consumer", "plug"}})
+	c.Check(rec.CallsTo("Slot"), HasLen, 0)
+}
+
+func (s *RecorderSuite) TestForwardsToRepository(c *C) {
+	repo := ifacetest.MockRepository(c, ifacetest.RepositoryFixture{
+		Interfaces: []interfaces.Interface{&ifacetest.TestInterface{InterfaceName: "iface"}},
+		Snaps: []string{`name: consumer
+version: 0
+plugs:
+  plug:
+    interface: iface
+`, `name: producer
+version: 0
+slots:
+  slot:
+    interface: iface
+`},
+	})
+	rec := &ifacetest.RecordingRepository{Repo: repo}
+
+	connRef, err := rec.ResolveConnect("consumer", "plug", "producer", "")
+	c.Assert(err, IsNil)
+	c.Check(connRef.ID(), Equals, "consumer:plug producer:slot")
+	_, err = rec.Connect(connRef, nil, nil, nil, nil, nil)
+	c.Assert(err, IsNil)
+
+	conns, err := repo.Connected("consumer", "plug")
+	c.Assert(err, IsNil)
+	c.Check(conns, DeepEquals, []*interfaces.ConnRef{connRef})
+	conns, err = rec.Connections("producer")
+	c.Assert(err, IsNil)
+	c.Check(conns, DeepEquals, []*interfaces.ConnRef{connRef})
+	c.Check(rec.AllPlugs("iface"), DeepEquals, repo.AllPlugs("iface"))
+	c.Check(rec.CallsTo("Connect"), HasLen, 1)
+}
+
+func (s *RecorderSuite) TestErrors(c *C) {
+	repo := interfaces.NewRepository()
+	rec := &ifacetest.RecordingRepository{
+		Repo:   repo,
+		Errors: map[string]error{"Disconnect": errors.New("boom")},
+	}
+
+	c.Check(rec.Disconnect("consumer", "plug", "producer", "slot"), ErrorMatches, "boom")
+	_, err := rec.Connected("consumer", "plug")
+	c.Check(err, ErrorMatches, `snap "consumer" has no plug or slot named "plug"`)
+	c.Check(rec.Calls(), HasLen, 2)
+}
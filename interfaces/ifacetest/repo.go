@@ -0,0 +1,64 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest
+
+import (
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+// RepositoryFixture describes the content of a repository made by
+// MockRepository.
+type RepositoryFixture struct {
+	// Interfaces are added to the repository first.
+	Interfaces []interfaces.Interface
+	// Backends are the security backends known to the repository.
+	Backends []interfaces.SecurityBackend
+	// Snaps are the yaml descriptions of the snaps whose plugs and
+	// slots are added to the repository.
+	Snaps []string
+	// Connections are the IDs of the connections to establish, in the
+	// "snap:plug snap:slot" form.
+	Connections []string
+}
+
+// MockRepository returns a new repository populated as described by the
+// fixture. The connections are established without any policy check.
+func MockRepository(c *check.C, fixture RepositoryFixture) *interfaces.Repository {
+	repo := interfaces.NewRepository()
+	for _, iface := range fixture.Interfaces {
+		c.Assert(repo.AddInterface(iface), check.IsNil)
+	}
+	for _, backend := range fixture.Backends {
+		c.Assert(repo.AddBackend(backend), check.IsNil)
+	}
+	for _, yaml := range fixture.Snaps {
+		c.Assert(repo.AddSnap(snaptest.MockInfo(c, yaml, nil)), check.IsNil)
+	}
+	for _, id := range fixture.Connections {
+		connRef, err := interfaces.ParseConnRef(id)
+		c.Assert(err, check.IsNil)
+		_, err = repo.Connect(connRef, nil, nil, nil, nil, nil)
+		c.Assert(err, check.IsNil)
+	}
+	return repo
+}
@@ -0,0 +1,72 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+)
+
+type RepositorySuite struct{}
+
+var _ = Suite(&RepositorySuite{})
+
+func (s *RepositorySuite) TestMockRepository(c *C) {
+	repo := ifacetest.MockRepository(c, ifacetest.RepositoryFixture{
+		Interfaces: []interfaces.Interface{
+			&ifacetest.TestInterface{InterfaceName: "iface"},
+			&ifacetest.TestInterface{InterfaceName: "other"},
+		},
+		Snaps: []string{`name: consumer
+version: 0
+plugs:
+  plug:
+    interface: iface
+  other-plug:
+    interface: other
+`, `name: producer
+version: 0
+slots:
+  slot:
+    interface: iface
+`},
+		Connections: []string{"consumer:plug producer:slot"},
+	})
+
+	c.Check(repo.AllInterfaces(), HasLen, 2)
+	c.Check(repo.Plug("consumer", "plug"), NotNil)
+	c.Check(repo.Plug("consumer", "other-plug"), NotNil)
+	c.Check(repo.Slot("producer", "slot"), NotNil)
+	conns, err := repo.Connected("consumer", "plug")
+	c.Assert(err, IsNil)
+	c.Check(conns, DeepEquals, []*interfaces.ConnRef{
+		interfaces.NewConnRef(repo.Plug("consumer", "plug"), repo.Slot("producer", "slot")),
+	})
+	conns, err = repo.Connected("consumer", "other-plug")
+	c.Assert(err, IsNil)
+	c.Check(conns, HasLen, 0)
+}
+
+func (s *RepositorySuite) TestMockRepositoryEmpty(c *C) {
+	repo := ifacetest.MockRepository(c, ifacetest.RepositoryFixture{})
+	c.Check(repo.AllInterfaces(), HasLen, 0)
+}
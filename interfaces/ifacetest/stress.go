@@ -0,0 +1,238 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+// StressInterface is the name of the interface used by the plugs and slots
+// of the snaps added by StressRepository.
+const StressInterface = "stress"
+
+// StressOptions tunes the load generated by StressRepository.
+type StressOptions struct {
+	// Workers is the number of goroutines operating on the repository
+	// concurrently, 8 by default.
+	Workers int
+	// Snaps is the number of consumer and of producer snaps, 4 by default.
+	Snaps int
+	// Rounds is the number of operations made by each worker while
+	// connections are churned, 200 by default.
+	Rounds int
+}
+
+func (opts *StressOptions) withDefaults() StressOptions {
+	res := StressOptions{Workers: 8, Snaps: 4, Rounds: 200}
+	if opts == nil {
+		return res
+	}
+	if opts.Workers > 0 {
+		res.Workers = opts.Workers
+	}
+	if opts.Snaps > 0 {
+		res.Snaps = opts.Snaps
+	}
+	if opts.Rounds > 0 {
+		res.Rounds = opts.Rounds
+	}
+	return res
+}
+
+const stressConsumerYaml = `name: stress-consumer-%d
+version: 0
+plugs:
+  plug-a:
+    interface: stress
+  plug-b:
+    interface: stress
+`
+
+const stressProducerYaml = `name: stress-producer-%d
+version: 0
+slots:
+  slot-a:
+    interface: stress
+  slot-b:
+    interface: stress
+`
+
+// StressRepository hammers the repository from many goroutines and checks
+// its invariants with Repository.CheckInvariants after each phase. Snaps are
+// first added concurrently, then connections are made and broken while
+// plugs are removed and re-added and the repository is queried, and
+// finally all the snaps are disconnected and removed again.
+//
+// Only the stress-consumer-N and stress-producer-N snaps are modified, so
+// downstreams can stress a repository holding their own fixtures. The
+// StressInterface interface is added if needed. The harness is most useful
+// with the race detector enabled.
+func StressRepository(c *check.C, repo *interfaces.Repository, opts *StressOptions) {
+	o := opts.withDefaults()
+	if repo.Interface(StressInterface) == nil {
+		c.Assert(repo.AddInterface(&TestInterface{InterfaceName: StressInterface}), check.IsNil)
+	}
+
+	// snaptest asserts on c so the snaps are prepared upfront
+	var consumers, producers []*snap.Info
+	for i := 0; i < o.Snaps; i++ {
+		consumers = append(consumers, snaptest.MockInfo(c, fmt.Sprintf(stressConsumerYaml, i), nil))
+		producers = append(producers, snaptest.MockInfo(c, fmt.Sprintf(stressProducerYaml, i), nil))
+	}
+	all := append(append([]*snap.Info{}, consumers...), producers...)
+
+	var errsMu sync.Mutex
+	var errs []error
+	report := func(err error) {
+		errsMu.Lock()
+		defer errsMu.Unlock()
+		errs = append(errs, err)
+	}
+	runPhase := func(phase string, work func(w int, rng *rand.Rand)) {
+		var wg sync.WaitGroup
+		for w := 0; w < o.Workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				work(w, rand.New(rand.NewSource(int64(w))))
+			}(w)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			c.Errorf("%s: %v", phase, err)
+		}
+		errs = nil
+		c.Check(repo.CheckInvariants(), check.IsNil, check.Commentf("%s", phase))
+	}
+
+	// phase 1: add all the snaps while querying the repository
+	runPhase("add", func(w int, rng *rand.Rand) {
+		for i := w; i < len(all); i += o.Workers {
+			if err := repo.AddSnap(all[i]); err != nil {
+				report(err)
+			}
+		}
+		for i := 0; i < len(all); i++ {
+			stressQuery(repo, rng)
+		}
+	})
+	for _, info := range all {
+		c.Check(repo.Plugs(info.InstanceName()), check.HasLen, len(info.Plugs))
+		c.Check(repo.Slots(info.InstanceName()), check.HasLen, len(info.Slots))
+	}
+
+	// phase 2: churn connections, plugs and queries; operations are
+	// expected to fail when racing with each other so only the final
+	// state is checked
+	randomRef := func(rng *rand.Rand) *interfaces.ConnRef {
+		plugs := []string{"plug-a", "plug-b"}
+		slots := []string{"slot-a", "slot-b"}
+		return &interfaces.ConnRef{
+			PlugRef: interfaces.PlugRef{Snap: consumers[rng.Intn(len(consumers))].InstanceName(), Name: plugs[rng.Intn(2)]},
+			SlotRef: interfaces.SlotRef{Snap: producers[rng.Intn(len(producers))].InstanceName(), Name: slots[rng.Intn(2)]},
+		}
+	}
+	runPhase("churn", func(w int, rng *rand.Rand) {
+		for i := 0; i < o.Rounds; i++ {
+			ref := randomRef(rng)
+			switch rng.Intn(5) {
+			case 0, 1:
+				repo.Connect(ref, nil, nil, nil, nil, nil)
+			case 2:
+				repo.Disconnect(ref.PlugRef.Snap, ref.PlugRef.Name, ref.SlotRef.Snap, ref.SlotRef.Name)
+			case 3:
+				// fails while the plug is connected
+				if repo.RemovePlug(ref.PlugRef.Snap, ref.PlugRef.Name) == nil {
+					info := consumers[0]
+					for _, consumer := range consumers {
+						if consumer.InstanceName() == ref.PlugRef.Snap {
+							info = consumer
+						}
+					}
+					if err := repo.AddPlug(info.Plugs[ref.PlugRef.Name]); err != nil {
+						report(err)
+					}
+				}
+			default:
+				stressQuery(repo, rng)
+			}
+		}
+	})
+
+	// phase 3: disconnect and remove all the snaps while querying the
+	// repository
+	runPhase("remove", func(w int, rng *rand.Rand) {
+		for i := w; i < len(all); i += o.Workers {
+			name := all[i].InstanceName()
+			if _, err := repo.DisconnectSnap(name); err != nil {
+				report(err)
+			}
+			stressQuery(repo, rng)
+		}
+	})
+	runPhase("remove", func(w int, rng *rand.Rand) {
+		for i := w; i < len(all); i += o.Workers {
+			if err := repo.RemoveSnap(all[i].InstanceName()); err != nil {
+				report(err)
+			}
+			stressQuery(repo, rng)
+		}
+	})
+	for _, info := range all {
+		c.Check(repo.Plugs(info.InstanceName()), check.HasLen, 0)
+		c.Check(repo.Slots(info.InstanceName()), check.HasLen, 0)
+	}
+}
+
+// stressQuery runs a read-only query against the repository, walking the
+// result so that the race detector sees any data shared with the
+// repository.
+func stressQuery(repo *interfaces.Repository, rng *rand.Rand) {
+	var refs []*interfaces.ConnRef
+	switch rng.Intn(4) {
+	case 0:
+		ifaces := repo.Interfaces()
+		for _, plug := range ifaces.Plugs {
+			_ = plug.Name
+		}
+		refs = ifaces.Connections
+	case 1:
+		for _, plug := range repo.AllPlugs(StressInterface) {
+			refs, _ = repo.Connected(plug.Snap.InstanceName(), plug.Name)
+		}
+	case 2:
+		for _, slot := range repo.AllSlots(StressInterface) {
+			refs, _ = repo.Connections(slot.Snap.InstanceName())
+		}
+	default:
+		repo.Info(&interfaces.InfoOptions{Names: []string{StressInterface}, Plugs: true, Slots: true, Connected: true})
+	}
+	for _, ref := range refs {
+		_ = ref.ID()
+	}
+}
@@ -0,0 +1,67 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacetest_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+)
+
+type StressSuite struct{}
+
+var _ = Suite(&StressSuite{})
+
+func (s *StressSuite) TestStressEmptyRepository(c *C) {
+	repo := interfaces.NewRepository()
+	ifacetest.StressRepository(c, repo, nil)
+
+	c.Check(repo.Interface(ifacetest.StressInterface), NotNil)
+	c.Check(repo.Interfaces(), DeepEquals, &interfaces.Interfaces{})
+}
+
+func (s *StressSuite) TestStressKeepsOtherSnaps(c *C) {
+	repo := ifacetest.MockRepository(c, ifacetest.RepositoryFixture{
+		Interfaces: []interfaces.Interface{
+			&ifacetest.TestInterface{InterfaceName: "iface"},
+			&ifacetest.TestInterface{InterfaceName: ifacetest.StressInterface},
+		},
+		Snaps: []string{`name: consumer
+version: 0
+plugs:
+  plug:
+    interface: iface
+  stress:
+`, `name: producer
+version: 0
+slots:
+  slot:
+    interface: iface
+  stress:
+`},
+		Connections: []string{"consumer:plug producer:slot", "consumer:stress producer:stress"},
+	})
+	before := repo.Interfaces()
+
+	ifacetest.StressRepository(c, repo, &ifacetest.StressOptions{Workers: 3, Snaps: 2, Rounds: 50})
+
+	c.Check(repo.Interfaces(), DeepEquals, before)
+}
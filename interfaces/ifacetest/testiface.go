@@ -49,6 +49,11 @@ type TestInterface struct {
 	BeforeConnectPlugCallback func(plug *interfaces.ConnectedPlug) error
 	BeforeConnectSlotCallback func(slot *interfaces.ConnectedSlot) error
 
+	// PlugAttrDefaultValues and SlotAttrDefaultValues are returned by
+	// PlugAttrDefaults and SlotAttrDefaults.
+	PlugAttrDefaultValues map[string]interface{}
+	SlotAttrDefaultValues map[string]interface{}
+
 	// Support for interacting with the test backend.
 
 	TestConnectedPlugCallback func(spec *Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error
@@ -169,6 +174,16 @@ func (t *TestInterface) BeforeConnectSlot(slot *interfaces.ConnectedSlot) error
 	return nil
 }
 
+// PlugAttrDefaults returns the default values of plug attributes.
+func (t *TestInterface) PlugAttrDefaults() map[string]interface{} {
+	return t.PlugAttrDefaultValues
+}
+
+// SlotAttrDefaults returns the default values of slot attributes.
+func (t *TestInterface) SlotAttrDefaults() map[string]interface{} {
+	return t.SlotAttrDefaultValues
+}
+
 // AutoConnect returns whether plug and slot should be implicitly
 // auto-connected assuming they will be an unambiguous connection
 // candidate.
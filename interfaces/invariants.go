@@ -0,0 +1,142 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snap"
+)
+
+// checkRepoInvariants enables verifying the consistency of the repository
+// after every change. It is expensive and only meant for debugging.
+var checkRepoInvariants = osutil.GetenvBool("SNAPD_DEBUG_REPO_INVARIANTS")
+
+// CheckInvariants verifies the internal consistency of the repository: plugs
+// and slots are indexed under their own snap and name, both sides of every
+// connection agree with each other and refer to plugs and slots that are
+// known to the repository, and the orderings used for listing are total.
+//
+// When SNAPD_DEBUG_REPO_INVARIANTS is set the check is made after every
+// change and a violation causes a panic.
+func (r *Repository) CheckInvariants() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.checkInvariants()
+}
+
+// debugCheckInvariants must be called with the repository lock held.
+func (r *Repository) debugCheckInvariants() {
+	if !checkRepoInvariants {
+		return
+	}
+	if err := r.checkInvariants(); err != nil {
+		panic(fmt.Sprintf("internal error: %v", err))
+	}
+}
+
+func (r *Repository) checkInvariants() error {
+	var allPlugs []*snap.PlugInfo
+	for snapName, plugs := range r.plugs {
+		for plugName, plug := range plugs {
+			if plug.Snap.InstanceName() != snapName || plug.Name != plugName {
+				return fmt.Errorf("plug %s:%s is indexed as %s:%s", plug.Snap.InstanceName(), plug.Name, snapName, plugName)
+			}
+			allPlugs = append(allPlugs, plug)
+		}
+	}
+	var allSlots []*snap.SlotInfo
+	for snapName, slots := range r.slots {
+		for slotName, slot := range slots {
+			if slot.Snap.InstanceName() != snapName || slot.Name != slotName {
+				return fmt.Errorf("slot %s:%s is indexed as %s:%s", slot.Snap.InstanceName(), slot.Name, snapName, slotName)
+			}
+			allSlots = append(allSlots, slot)
+		}
+	}
+
+	knownPlug := func(plug *snap.PlugInfo) bool {
+		return r.plugs[plug.Snap.InstanceName()][plug.Name] == plug
+	}
+	knownSlot := func(slot *snap.SlotInfo) bool {
+		return r.slots[slot.Snap.InstanceName()][slot.Name] == slot
+	}
+
+	var allConns []*ConnRef
+	for plug, slots := range r.plugSlots {
+		if !knownPlug(plug) {
+			return fmt.Errorf("connected plug %s:%s is not in the repository", plug.Snap.InstanceName(), plug.Name)
+		}
+		if len(slots) == 0 {
+			return fmt.Errorf("plug %s:%s has an empty set of connections", plug.Snap.InstanceName(), plug.Name)
+		}
+		for slot, conn := range slots {
+			connRef := NewConnRef(plug, slot)
+			if !knownSlot(slot) {
+				return fmt.Errorf("connection %s refers to a slot that is not in the repository", connRef.ID())
+			}
+			if r.slotPlugs[slot][plug] != conn {
+				return fmt.Errorf("connection %s is not recorded for its slot", connRef.ID())
+			}
+			if conn == nil || conn.Plug == nil || conn.Slot == nil || conn.Plug.plugInfo != plug || conn.Slot.slotInfo != slot {
+				return fmt.Errorf("connection %s does not match its plug and slot", connRef.ID())
+			}
+			allConns = append(allConns, connRef)
+		}
+	}
+	for slot, plugs := range r.slotPlugs {
+		if !knownSlot(slot) {
+			return fmt.Errorf("connected slot %s:%s is not in the repository", slot.Snap.InstanceName(), slot.Name)
+		}
+		if len(plugs) == 0 {
+			return fmt.Errorf("slot %s:%s has an empty set of connections", slot.Snap.InstanceName(), slot.Name)
+		}
+		for plug, conn := range plugs {
+			if r.plugSlots[plug][slot] != conn {
+				return fmt.Errorf("connection %s is not recorded for its plug", NewConnRef(plug, slot).ID())
+			}
+		}
+	}
+
+	// listings are sorted, they are only stable if no two elements
+	// compare equal
+	sort.Sort(byPlugSnapAndName(allPlugs))
+	for i := 1; i < len(allPlugs); i++ {
+		if !byPlugSnapAndName(allPlugs).Less(i-1, i) {
+			return fmt.Errorf("plug %s:%s is not ordered", allPlugs[i].Snap.InstanceName(), allPlugs[i].Name)
+		}
+	}
+	sort.Sort(bySlotSnapAndName(allSlots))
+	for i := 1; i < len(allSlots); i++ {
+		if !bySlotSnapAndName(allSlots).Less(i-1, i) {
+			return fmt.Errorf("slot %s:%s is not ordered", allSlots[i].Snap.InstanceName(), allSlots[i].Name)
+		}
+	}
+	sort.Sort(byConnRef(allConns))
+	for i := 1; i < len(allConns); i++ {
+		if !byConnRef(allConns).Less(i-1, i) {
+			return fmt.Errorf("connection %s is not ordered", allConns[i].ID())
+		}
+	}
+	return nil
+}
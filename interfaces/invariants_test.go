@@ -0,0 +1,103 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	. "github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type invariantsSuite struct {
+	testutil.BaseTest
+	repo *Repository
+}
+
+var _ = Suite(&invariantsSuite{})
+
+func (s *invariantsSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.repo = ifacetest.MockRepository(c, ifacetest.RepositoryFixture{
+		Interfaces: []Interface{&ifacetest.TestInterface{InterfaceName: "iface"}},
+		Snaps: []string{`name: consumer
+version: 0
+plugs:
+  plug:
+    interface: iface
+  other-plug:
+    interface: iface
+`, `name: producer
+version: 0
+slots:
+  slot:
+    interface: iface
+  other-slot:
+    interface: iface
+`},
+		Connections: []string{"consumer:plug producer:slot", "consumer:other-plug producer:slot"},
+	})
+}
+
+func (s *invariantsSuite) TestConsistent(c *C) {
+	c.Check(s.repo.CheckInvariants(), IsNil)
+	c.Check(NewRepository().CheckInvariants(), IsNil)
+
+	c.Assert(s.repo.Disconnect("consumer", "plug", "producer", "slot"), IsNil)
+	c.Check(s.repo.CheckInvariants(), IsNil)
+	_, err := s.repo.DisconnectSnap("producer")
+	c.Assert(err, IsNil)
+	c.Assert(s.repo.RemoveSnap("producer"), IsNil)
+	c.Check(s.repo.CheckInvariants(), IsNil)
+}
+
+func (s *invariantsSuite) TestDanglingPlug(c *C) {
+	s.repo.ForgetPlugInternal("consumer", "plug")
+	c.Check(s.repo.CheckInvariants(), ErrorMatches, `connected plug consumer:plug is not in the repository`)
+}
+
+func (s *invariantsSuite) TestMisindexedSlot(c *C) {
+	s.repo.ReindexSlotInternal("producer", "other-slot", "renamed")
+	c.Check(s.repo.CheckInvariants(), ErrorMatches, `slot producer:other-slot is indexed as producer:renamed`)
+}
+
+func (s *invariantsSuite) TestAsymmetricConnection(c *C) {
+	s.repo.ForgetSlotConnectionsInternal("producer", "slot")
+	c.Check(s.repo.CheckInvariants(), ErrorMatches, `connection consumer:(other-)?plug producer:slot is not recorded for its slot`)
+}
+
+func (s *invariantsSuite) TestMismatchedConnection(c *C) {
+	s.repo.ReplaceConnectionInternal("consumer", "plug", "producer", "slot")
+	c.Check(s.repo.CheckInvariants(), ErrorMatches, `connection consumer:plug producer:slot is not recorded for its slot`)
+}
+
+func (s *invariantsSuite) TestDebugCheckPanics(c *C) {
+	restore := MockCheckRepoInvariants(true)
+	defer restore()
+
+	// consistent changes go through
+	c.Assert(s.repo.Disconnect("consumer", "other-plug", "producer", "slot"), IsNil)
+
+	s.repo.ForgetSlotConnectionsInternal("producer", "slot")
+	connRef, err := ParseConnRef("consumer:plug producer:other-slot")
+	c.Assert(err, IsNil)
+	c.Check(func() { s.repo.Connect(connRef, nil, nil, nil, nil, nil) }, PanicMatches, `internal error: connection consumer:plug producer:slot is not recorded for its slot`)
+}
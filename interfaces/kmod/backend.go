@@ -40,6 +40,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 
 	"github.com/snapcore/snapd/dirs"
@@ -163,6 +164,15 @@ func (b *Backend) Remove(snapName string) error {
 	return nil
 }
 
+// ProfileGlobs returns the globs matching kernel module configuration files of a given snap.
+func (b *Backend) ProfileGlobs(snapName string) []string {
+	glob := interfaces.SecurityTagGlob(snapName)
+	return []string{
+		filepath.Join(dirs.SnapKModModulesDir, glob),
+		filepath.Join(dirs.SnapKModModprobeDir, glob),
+	}
+}
+
 func deriveContent(spec *Specification, snapInfo *snap.Info) (map[string]osutil.FileState, []string) {
 	if len(spec.modules) == 0 {
 		return nil, nil
@@ -197,3 +197,12 @@ func (s *backendSuite) TestSecurityIsStable(c *C) {
 func (s *backendSuite) TestSandboxFeatures(c *C) {
 	c.Assert(s.Backend.SandboxFeatures(), DeepEquals, []string{"mediated-modprobe"})
 }
+
+func (s *backendSuite) TestProfileGlobs(c *C) {
+	globber, ok := s.Backend.(interfaces.SecurityBackendProfileGlobs)
+	c.Assert(ok, Equals, true)
+	c.Check(globber.ProfileGlobs("samba"), DeepEquals, []string{
+		filepath.Join(dirs.SnapKModModulesDir, "snap.samba.*"),
+		filepath.Join(dirs.SnapKModModprobeDir, "snap.samba.*"),
+	})
+}
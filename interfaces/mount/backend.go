@@ -33,6 +33,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
@@ -93,6 +94,12 @@ func (b *Backend) Remove(snapName string) error {
 	return DiscardSnapNamespace(snapName)
 }
 
+// ProfileGlobs returns the globs matching mount profiles of a given snap.
+func (b *Backend) ProfileGlobs(snapName string) []string {
+	glob := fmt.Sprintf("snap.%s.*fstab", snapName)
+	return []string{filepath.Join(dirs.SnapMountPolicyDir, glob)}
+}
+
 // addMountProfile adds a mount profile with the given name, based on the given entries.
 //
 // If there are no entries no profile is generated.
@@ -246,3 +246,11 @@ func (s *backendSuite) TestSandboxFeatures(c *C) {
 		"stale-base-invalidation",
 	})
 }
+
+func (s *backendSuite) TestProfileGlobs(c *C) {
+	globber, ok := s.Backend.(interfaces.SecurityBackendProfileGlobs)
+	c.Assert(ok, Equals, true)
+	c.Check(globber.ProfileGlobs("samba"), DeepEquals, []string{
+		filepath.Join(dirs.SnapMountPolicyDir, "snap.samba.*fstab"),
+	})
+}
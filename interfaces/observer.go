@@ -0,0 +1,111 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces
+
+import (
+	"github.com/snapcore/snapd/snap"
+)
+
+// RepositoryEventKind describes what kind of change was made to the repository.
+type RepositoryEventKind string
+
+const (
+	// SnapAddedEvent is emitted when the plugs and slots of a snap are added.
+	SnapAddedEvent RepositoryEventKind = "snap-added"
+	// SnapRemovedEvent is emitted when the plugs and slots of a snap are removed.
+	SnapRemovedEvent RepositoryEventKind = "snap-removed"
+	// PlugAddedEvent is emitted when a single plug is added.
+	PlugAddedEvent RepositoryEventKind = "plug-added"
+	// PlugRemovedEvent is emitted when a single plug is removed.
+	PlugRemovedEvent RepositoryEventKind = "plug-removed"
+	// SlotAddedEvent is emitted when a single slot is added.
+	SlotAddedEvent RepositoryEventKind = "slot-added"
+	// SlotRemovedEvent is emitted when a single slot is removed.
+	SlotRemovedEvent RepositoryEventKind = "slot-removed"
+	// ConnectedEvent is emitted when a plug is connected to a slot.
+	ConnectedEvent RepositoryEventKind = "connected"
+	// DisconnectedEvent is emitted when a plug is disconnected from a slot.
+	DisconnectedEvent RepositoryEventKind = "disconnected"
+)
+
+// RepositoryEvent describes a single change made to the repository.
+//
+// Snap is set for all events. Name is the name of the plug or slot for plug
+// and slot events. Conn is set for connection events only.
+type RepositoryEvent struct {
+	Kind RepositoryEventKind
+	Snap string
+	Name string
+	Conn *ConnRef
+}
+
+// RepositoryObserver is notified of changes made to the repository.
+type RepositoryObserver interface {
+	// RepositoryChanged is called after the change was applied.
+	//
+	// It is called with the repository lock held, implementations must
+	// not block and must not call back into the repository.
+	RepositoryChanged(ev *RepositoryEvent)
+}
+
+type observerEntry struct {
+	id       int
+	observer RepositoryObserver
+}
+
+// AddObserver registers an observer that is notified of changes made to the
+// plugs, slots and connections of the repository. The returned function
+// unregisters the observer.
+func (r *Repository) AddObserver(o RepositoryObserver) (remove func()) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.lastObserverID++
+	id := r.lastObserverID
+	r.observers = append(r.observers, observerEntry{id: id, observer: o})
+	return func() {
+		r.m.Lock()
+		defer r.m.Unlock()
+
+		for i, entry := range r.observers {
+			if entry.id == id {
+				r.observers = append(r.observers[:i], r.observers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// notify must be called with the repository lock held.
+func (r *Repository) notify(ev *RepositoryEvent) {
+	r.debugCheckInvariants()
+	for _, entry := range r.observers {
+		entry.observer.RepositoryChanged(ev)
+	}
+}
+
+func (r *Repository) notifyConnection(kind RepositoryEventKind, plug *snap.PlugInfo, slot *snap.SlotInfo) {
+	r.debugCheckInvariants()
+	if len(r.observers) == 0 {
+		return
+	}
+	conn := NewConnRef(plug, slot)
+	r.notify(&RepositoryEvent{Kind: kind, Snap: conn.PlugRef.Snap, Conn: conn})
+}
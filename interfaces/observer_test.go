@@ -0,0 +1,143 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	. "github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type observerSuite struct {
+	testutil.BaseTest
+	repo *Repository
+}
+
+var _ = Suite(&observerSuite{})
+
+type recordingObserver struct {
+	events []*RepositoryEvent
+}
+
+func (o *recordingObserver) RepositoryChanged(ev *RepositoryEvent) {
+	o.events = append(o.events, ev)
+}
+
+func (s *observerSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.BaseTest.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	s.repo = NewRepository()
+	c.Assert(s.repo.AddInterface(&ifacetest.TestInterface{InterfaceName: "iface"}), IsNil)
+}
+
+func (s *observerSuite) TearDownTest(c *C) {
+	s.BaseTest.TearDownTest(c)
+}
+
+func (s *observerSuite) TestEvents(c *C) {
+	o := &recordingObserver{}
+	remove := s.repo.AddObserver(o)
+
+	consumer := snaptest.MockInfo(c, `
+name: consumer
+version: 0
+plugs:
+  plug:
+    interface: iface
+`, nil)
+	producer := snaptest.MockInfo(c, `
+name: producer
+version: 0
+slots:
+  slot:
+    interface: iface
+`, nil)
+	c.Assert(s.repo.AddSnap(consumer), IsNil)
+	c.Assert(s.repo.AddSnap(producer), IsNil)
+
+	connRef := &ConnRef{
+		PlugRef: PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: SlotRef{Snap: "producer", Name: "slot"},
+	}
+	_, err := s.repo.Connect(connRef, nil, nil, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(s.repo.Disconnect("consumer", "plug", "producer", "slot"), IsNil)
+
+	c.Assert(s.repo.RemoveSlot("producer", "slot"), IsNil)
+	c.Assert(s.repo.AddSlot(producer.Slots["slot"]), IsNil)
+	c.Assert(s.repo.RemovePlug("consumer", "plug"), IsNil)
+	c.Assert(s.repo.AddPlug(consumer.Plugs["plug"]), IsNil)
+
+	c.Assert(s.repo.RemoveSnap("consumer"), IsNil)
+	// removing an unknown snap is not reported
+	c.Assert(s.repo.RemoveSnap("unknown"), IsNil)
+
+	c.Check(o.events, DeepEquals, []*RepositoryEvent{
+		{Kind: SnapAddedEvent, Snap: "consumer"},
+		{Kind: SnapAddedEvent, Snap: "producer"},
+		{Kind: ConnectedEvent, Snap: "consumer", Conn: connRef},
+		{Kind: DisconnectedEvent, Snap: "consumer", Conn: connRef},
+		{Kind: SlotRemovedEvent, Snap: "producer", Name: "slot"},
+		{Kind: SlotAddedEvent, Snap: "producer", Name: "slot"},
+		{Kind: PlugRemovedEvent, Snap: "consumer", Name: "plug"},
+		{Kind: PlugAddedEvent, Snap: "consumer", Name: "plug"},
+		{Kind: SnapRemovedEvent, Snap: "consumer"},
+	})
+
+	// no more events after the observer is removed
+	remove()
+	c.Assert(s.repo.RemoveSnap("producer"), IsNil)
+	c.Check(o.events, HasLen, 9)
+}
+
+func (s *observerSuite) TestFailedChangesAreNotReported(c *C) {
+	o := &recordingObserver{}
+	s.repo.AddObserver(o)
+
+	c.Check(s.repo.Disconnect("consumer", "plug", "producer", "slot"), NotNil)
+	_, err := s.repo.Connect(&ConnRef{
+		PlugRef: PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: SlotRef{Snap: "producer", Name: "slot"},
+	}, nil, nil, nil, nil, nil)
+	c.Check(err, NotNil)
+	c.Check(s.repo.RemovePlug("consumer", "plug"), NotNil)
+
+	c.Check(o.events, HasLen, 0)
+}
+
+func (s *observerSuite) TestMultipleObservers(c *C) {
+	o1 := &recordingObserver{}
+	o2 := &recordingObserver{}
+	remove1 := s.repo.AddObserver(o1)
+	s.repo.AddObserver(o2)
+	remove1()
+
+	info := snaptest.MockInfo(c, "name: foo\nversion: 0\n", nil)
+	c.Assert(s.repo.AddSnap(info), IsNil)
+
+	c.Check(o1.events, HasLen, 0)
+	c.Check(o2.events, DeepEquals, []*RepositoryEvent{
+		{Kind: SnapAddedEvent, Snap: "foo"},
+	})
+}
@@ -609,7 +609,7 @@ var (
 		"avahi-control":             {"app", "core"},
 		"avahi-observe":             {"app", "core"},
 		"bluez":                     {"app", "core"},
-		"bool-file":                 {"core", "gadget"},
+		"bool-file":                 {"core", "gadget", "kernel"},
 		"browser-support":           {"core"},
 		"content":                   {"app", "gadget"},
 		"core-support":              {"core"},
@@ -618,15 +618,15 @@ var (
 		"dbus":                      {"app"},
 		"docker-support":            {"core"},
 		"desktop-launch":            {"core"},
-		"dsp":                       {"core", "gadget"},
+		"dsp":                       {"core", "gadget", "kernel"},
 		"dummy":                     {"app"},
 		"fwupd":                     {"app", "core"},
-		"gpio":                      {"core", "gadget"},
+		"gpio":                      {"core", "gadget", "kernel"},
 		"gpio-control":              {"core"},
 		"greengrass-support":        {"core"},
-		"hidraw":                    {"core", "gadget"},
-		"i2c":                       {"core", "gadget"},
-		"iio":                       {"core", "gadget"},
+		"hidraw":                    {"core", "gadget", "kernel"},
+		"i2c":                       {"core", "gadget", "kernel"},
+		"iio":                       {"core", "gadget", "kernel"},
 		"kernel-module-load":        {"core"},
 		"kubernetes-support":        {"core"},
 		"location-control":          {"app"},
@@ -639,7 +639,7 @@ var (
 		"modem-manager":             {"app", "core"},
 		"mount-control":             {"core"},
 		"mpris":                     {"app"},
-		"netlink-driver":            {"core", "gadget"},
+		"netlink-driver":            {"core", "gadget", "kernel"},
 		"network-manager":           {"app", "core"},
 		"network-manager-observe":   {"app", "core"},
 		"network-status":            {"core"},
@@ -648,19 +648,19 @@ var (
 		"power-control":             {"core"},
 		"ppp":                       {"core"},
 		"pulseaudio":                {"app", "core"},
-		"pwm":                       {"core", "gadget"},
+		"pwm":                       {"core", "gadget", "kernel"},
 		"qualcomm-ipc-router":       {"core"},
-		"raw-volume":                {"core", "gadget"},
+		"raw-volume":                {"core", "gadget", "kernel"},
 		"scsi-generic":              {"core"},
 		"sd-control":                {"core"},
-		"serial-port":               {"core", "gadget"},
-		"spi":                       {"core", "gadget"},
+		"serial-port":               {"core", "gadget", "kernel"},
+		"spi":                       {"core", "gadget", "kernel"},
 		"storage-framework-service": {"app"},
 		"thumbnailer-service":       {"app"},
 		"ubuntu-download-manager":   {"app"},
 		"udisks2":                   {"app", "core"},
 		"uhid":                      {"core"},
-		"uio":                       {"core", "gadget"},
+		"uio":                       {"core", "gadget", "kernel"},
 		"unity8":                    {"app"},
 		"unity8-calendar":           {"app"},
 		"unity8-contacts":           {"app"},
@@ -744,6 +744,7 @@ func (s *baseDeclSuite) TestPlugInstallation(c *C) {
 		"microstack-support":    true,
 		"mount-control":         true,
 		"multipass-support":     true,
+		"old-security":          true,
 		"packagekit-control":    true,
 		"personal-files":        true,
 		"polkit":                true,
@@ -988,6 +989,7 @@ func (s *baseDeclSuite) TestSanity(c *C) {
 		"microstack-support":    true,
 		"mount-control":         true,
 		"multipass-support":     true,
+		"old-security":          true,
 		"packagekit-control":    true,
 		"personal-files":        true,
 		"polkit":                true,
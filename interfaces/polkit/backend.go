@@ -30,6 +30,7 @@ package polkit
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
@@ -92,6 +93,11 @@ func (b *Backend) Remove(snapName string) error {
 	return nil
 }
 
+// ProfileGlobs returns the globs matching polkit policy files of a given snap.
+func (b *Backend) ProfileGlobs(snapName string) []string {
+	return []string{filepath.Join(dirs.SnapPolkitPolicyDir, polkitPolicyName(snapName, "*"))}
+}
+
 // deriveContent combines security snippets collected from all the interfaces
 // affecting a given snap into a content map applicable to EnsureDirState.
 func deriveContent(spec *Specification, snapInfo *snap.Info) map[string]osutil.FileState {
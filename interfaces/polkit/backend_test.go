@@ -129,3 +129,11 @@ func (s *backendSuite) TestUnexpectedPolicyFilesremoved(c *C) {
 func (s *backendSuite) TestSandboxFeatures(c *C) {
 	c.Assert(s.Backend.SandboxFeatures(), HasLen, 0)
 }
+
+func (s *backendSuite) TestProfileGlobs(c *C) {
+	globber, ok := s.Backend.(interfaces.SecurityBackendProfileGlobs)
+	c.Assert(ok, Equals, true)
+	c.Check(globber.ProfileGlobs("samba"), DeepEquals, []string{
+		filepath.Join(dirs.SnapPolkitPolicyDir, "snap.samba.interface.*.policy"),
+	})
+}
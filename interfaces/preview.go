@@ -0,0 +1,91 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces
+
+import (
+	"fmt"
+)
+
+// PreviewConnection returns the snippets that connecting the given plug and
+// slot would contribute to the security artefacts of the snaps involved,
+// keyed by security system and then by security tag. Only backends
+// implementing SecurityBackendSnippets are considered.
+//
+// The repository is not modified. The connection is assumed to carry the
+// static attributes of the plug and slot only, dynamic attributes are not
+// known until the interface hooks have run. Connection policy is not
+// checked either.
+func (r *Repository) PreviewConnection(ref *ConnRef) (map[SecuritySystem]map[string][]string, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	plug := r.plugs[ref.PlugRef.Snap][ref.PlugRef.Name]
+	if plug == nil {
+		return nil, &NoPlugOrSlotError{
+			message: fmt.Sprintf("snap %q has no plug named %q",
+				ref.PlugRef.Snap, ref.PlugRef.Name)}
+	}
+	slot := r.slots[ref.SlotRef.Snap][ref.SlotRef.Name]
+	if slot == nil {
+		return nil, &NoPlugOrSlotError{
+			message: fmt.Sprintf("snap %q has no slot named %q",
+				ref.SlotRef.Snap, ref.SlotRef.Name)}
+	}
+	if slot.Interface != plug.Interface {
+		return nil, fmt.Errorf(`cannot connect plug "%s:%s" (interface %q) to "%s:%s" (interface %q)`,
+			ref.PlugRef.Snap, ref.PlugRef.Name, plug.Interface, ref.SlotRef.Snap, ref.SlotRef.Name, slot.Interface)
+	}
+	iface, ok := r.ifaces[plug.Interface]
+	if !ok {
+		return nil, fmt.Errorf("internal error: unknown interface %q", plug.Interface)
+	}
+
+	cplug := NewConnectedPlug(plug, nil, nil)
+	cslot := NewConnectedSlot(slot, nil, nil)
+	if i, ok := iface.(plugValidator); ok {
+		if err := i.BeforeConnectPlug(cplug); err != nil {
+			return nil, fmt.Errorf("cannot connect plug %q of snap %q: %s", plug.Name, plug.Snap.InstanceName(), err)
+		}
+	}
+	if i, ok := iface.(slotValidator); ok {
+		if err := i.BeforeConnectSlot(cslot); err != nil {
+			return nil, fmt.Errorf("cannot connect slot %q of snap %q: %s", slot.Name, slot.Snap.InstanceName(), err)
+		}
+	}
+
+	preview := make(map[SecuritySystem]map[string][]string)
+	for _, backend := range r.backends {
+		snippeter, ok := backend.(SecurityBackendSnippets)
+		if !ok {
+			continue
+		}
+		spec := backend.NewSpecification()
+		if err := spec.AddConnectedPlug(iface, cplug, cslot); err != nil {
+			return nil, fmt.Errorf("cannot preview %s side-effects of plug %q of snap %q: %v", backend.Name(), plug.Name, plug.Snap.InstanceName(), err)
+		}
+		if err := spec.AddConnectedSlot(iface, cplug, cslot); err != nil {
+			return nil, fmt.Errorf("cannot preview %s side-effects of slot %q of snap %q: %v", backend.Name(), slot.Name, slot.Snap.InstanceName(), err)
+		}
+		if snippets := snippeter.SpecificationSnippets(spec); len(snippets) > 0 {
+			preview[backend.Name()] = snippets
+		}
+	}
+	return preview, nil
+}
@@ -0,0 +1,158 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces_test
+
+import (
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	. "github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type previewSuite struct {
+	testutil.BaseTest
+	repo  *Repository
+	iface *ifacetest.TestInterface
+}
+
+var _ = Suite(&previewSuite{})
+
+type snippetBackend struct {
+	ifacetest.TestSecurityBackend
+}
+
+func (b *snippetBackend) SpecificationSnippets(spec Specification) map[string][]string {
+	snippets := spec.(*ifacetest.Specification).Snippets
+	if len(snippets) == 0 {
+		return nil
+	}
+	return map[string][]string{"all": snippets}
+}
+
+func (s *previewSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.BaseTest.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	s.repo = NewRepository()
+	s.iface = &ifacetest.TestInterface{
+		InterfaceName: "iface",
+		TestConnectedPlugCallback: func(spec *ifacetest.Specification, plug *ConnectedPlug, slot *ConnectedSlot) error {
+			var path string
+			if err := plug.Attr("path", &path); err != nil {
+				return err
+			}
+			spec.AddSnippet("plug " + path)
+			return nil
+		},
+		TestConnectedSlotCallback: func(spec *ifacetest.Specification, plug *ConnectedPlug, slot *ConnectedSlot) error {
+			spec.AddSnippet("slot")
+			return nil
+		},
+	}
+	c.Assert(s.repo.AddInterface(s.iface), IsNil)
+	c.Assert(s.repo.AddInterface(&ifacetest.TestInterface{InterfaceName: "other"}), IsNil)
+	c.Assert(s.repo.AddBackend(&snippetBackend{TestSecurityBackend: ifacetest.TestSecurityBackend{BackendName: "snippets"}}), IsNil)
+	// backends not providing snippets are ignored
+	c.Assert(s.repo.AddBackend(&ifacetest.TestSecurityBackend{BackendName: "opaque"}), IsNil)
+
+	consumer := snaptest.MockInfo(c, `
+name: consumer
+version: 0
+plugs:
+  plug:
+    interface: iface
+    path: /dev/foo
+  other:
+    interface: other
+`, nil)
+	producer := snaptest.MockInfo(c, `
+name: producer
+version: 0
+slots:
+  slot:
+    interface: iface
+`, nil)
+	c.Assert(s.repo.AddSnap(consumer), IsNil)
+	c.Assert(s.repo.AddSnap(producer), IsNil)
+}
+
+func (s *previewSuite) TearDownTest(c *C) {
+	s.BaseTest.TearDownTest(c)
+}
+
+func (s *previewSuite) TestPreviewConnection(c *C) {
+	connRef := NewConnRef(s.repo.Plug("consumer", "plug"), s.repo.Slot("producer", "slot"))
+	preview, err := s.repo.PreviewConnection(connRef)
+	c.Assert(err, IsNil)
+	c.Check(preview, DeepEquals, map[SecuritySystem]map[string][]string{
+		"snippets": {"all": {"plug /dev/foo", "slot"}},
+	})
+
+	// the repository is left alone
+	conns, err := s.repo.Connections("consumer")
+	c.Assert(err, IsNil)
+	c.Check(conns, HasLen, 0)
+}
+
+func (s *previewSuite) TestPreviewConnectionNoSnippets(c *C) {
+	s.iface.TestConnectedPlugCallback = nil
+	s.iface.TestConnectedSlotCallback = nil
+	connRef := NewConnRef(s.repo.Plug("consumer", "plug"), s.repo.Slot("producer", "slot"))
+	preview, err := s.repo.PreviewConnection(connRef)
+	c.Assert(err, IsNil)
+	c.Check(preview, HasLen, 0)
+}
+
+func (s *previewSuite) TestPreviewConnectionErrors(c *C) {
+	for _, t := range []struct {
+		ref *ConnRef
+		err string
+	}{{
+		ref: &ConnRef{PlugRef: PlugRef{Snap: "consumer", Name: "missing"}, SlotRef: SlotRef{Snap: "producer", Name: "slot"}},
+		err: `snap "consumer" has no plug named "missing"`,
+	}, {
+		ref: &ConnRef{PlugRef: PlugRef{Snap: "consumer", Name: "plug"}, SlotRef: SlotRef{Snap: "producer", Name: "missing"}},
+		err: `snap "producer" has no slot named "missing"`,
+	}, {
+		ref: &ConnRef{PlugRef: PlugRef{Snap: "consumer", Name: "other"}, SlotRef: SlotRef{Snap: "producer", Name: "slot"}},
+		err: `cannot connect plug "consumer:other" \(interface "other"\) to "producer:slot" \(interface "iface"\)`,
+	}} {
+		_, err := s.repo.PreviewConnection(t.ref)
+		c.Check(err, ErrorMatches, t.err)
+	}
+
+	s.iface.BeforeConnectPlugCallback = func(plug *ConnectedPlug) error {
+		return fmt.Errorf("plug is broken")
+	}
+	connRef := NewConnRef(s.repo.Plug("consumer", "plug"), s.repo.Slot("producer", "slot"))
+	_, err := s.repo.PreviewConnection(connRef)
+	c.Check(err, ErrorMatches, `cannot connect plug "plug" of snap "consumer": plug is broken`)
+
+	s.iface.BeforeConnectPlugCallback = nil
+	s.iface.TestConnectedSlotCallback = func(spec *ifacetest.Specification, plug *ConnectedPlug, slot *ConnectedSlot) error {
+		return fmt.Errorf("boom")
+	}
+	_, err = s.repo.PreviewConnection(connRef)
+	c.Check(err, ErrorMatches, `cannot preview snippets side-effects of slot "slot" of snap "producer": boom`)
+}
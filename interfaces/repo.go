@@ -45,8 +45,38 @@ type Repository struct {
 	// given a plug and a slot, are they connected?
 	plugSlots map[*snap.PlugInfo]map[*snap.SlotInfo]*Connection
 	backends  []SecurityBackend
+	// observers notified of changes, see AddObserver
+	observers      []observerEntry
+	lastObserverID int
 }
 
+// ConnectionRepository is the subset of the methods of Repository that
+// consumers use to inspect plugs and slots and to make and break
+// connections. Code that only needs these can accept it instead of a
+// Repository so that its tests can use a recording fake, see
+// ifacetest.RecordingRepository.
+type ConnectionRepository interface {
+	Interface(interfaceName string) Interface
+	Info(opts *InfoOptions) []*Info
+	Interfaces() *Interfaces
+
+	AllPlugs(interfaceName string) []*snap.PlugInfo
+	Plugs(snapName string) []*snap.PlugInfo
+	Plug(snapName, plugName string) *snap.PlugInfo
+	AllSlots(interfaceName string) []*snap.SlotInfo
+	Slots(snapName string) []*snap.SlotInfo
+	Slot(snapName, slotName string) *snap.SlotInfo
+
+	ResolveConnect(plugSnapName, plugName, slotSnapName, slotName string) (*ConnRef, error)
+	PreviewConnection(ref *ConnRef) (map[SecuritySystem]map[string][]string, error)
+	Connect(ref *ConnRef, plugStaticAttrs, plugDynamicAttrs, slotStaticAttrs, slotDynamicAttrs map[string]interface{}, policyCheck PolicyFunc) (*Connection, error)
+	Disconnect(plugSnapName, plugName, slotSnapName, slotName string) error
+	Connected(snapName, plugOrSlotName string) ([]*ConnRef, error)
+	Connections(snapName string) ([]*ConnRef, error)
+}
+
+var _ ConnectionRepository = (*Repository)(nil)
+
 // NewRepository creates an empty plug repository.
 func NewRepository() *Repository {
 	repo := &Repository{
@@ -90,6 +120,62 @@ func (r *Repository) AddInterface(i Interface) error {
 	return nil
 }
 
+// RemoveInterface removes the named interface from the repository along
+// with the plugs and slots using it. Removing an interface whose plugs or
+// slots are connected returns an error.
+func (r *Repository) RemoveInterface(interfaceName string) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if _, ok := r.ifaces[interfaceName]; !ok {
+		return fmt.Errorf("cannot remove interface %q, no such interface", interfaceName)
+	}
+	for snapName, plugs := range r.plugs {
+		for plugName, plug := range plugs {
+			if plug.Interface == interfaceName && len(r.plugSlots[plug]) > 0 {
+				return fmt.Errorf("cannot remove interface %q, plug %q of snap %q is still connected", interfaceName, plugName, snapName)
+			}
+		}
+	}
+	for snapName, slots := range r.slots {
+		for slotName, slot := range slots {
+			if slot.Interface == interfaceName && len(r.slotPlugs[slot]) > 0 {
+				return fmt.Errorf("cannot remove interface %q, slot %q of snap %q is still connected", interfaceName, slotName, snapName)
+			}
+		}
+	}
+
+	for snapName, plugs := range r.plugs {
+		for plugName, plug := range plugs {
+			if plug.Interface != interfaceName {
+				continue
+			}
+			delete(r.plugSlots, plug)
+			delete(plugs, plugName)
+			r.notify(&RepositoryEvent{Kind: PlugRemovedEvent, Snap: snapName, Name: plugName})
+		}
+		if len(plugs) == 0 {
+			delete(r.plugs, snapName)
+		}
+	}
+	for snapName, slots := range r.slots {
+		for slotName, slot := range slots {
+			if slot.Interface != interfaceName {
+				continue
+			}
+			delete(r.slotPlugs, slot)
+			delete(slots, slotName)
+			r.notify(&RepositoryEvent{Kind: SlotRemovedEvent, Snap: snapName, Name: slotName})
+		}
+		if len(slots) == 0 {
+			delete(r.slots, snapName)
+		}
+	}
+	delete(r.ifaces, interfaceName)
+	delete(r.hotplugIfaces, interfaceName)
+	return nil
+}
+
 // AllInterfaces returns all the interfaces added to the repository, ordered by name.
 func (r *Repository) AllInterfaces() []Interface {
 	r.m.Lock()
@@ -118,16 +204,23 @@ func (r *Repository) AllHotplugInterfaces() map[string]Interface {
 // InfoOptions describes options for Info.
 //
 // Names: return just this subset if non-empty.
-// Doc: return documentation.
+// Doc: return documentation and implicit slot information.
 // Plugs: return information about plugs.
 // Slots: return information about slots.
 // Connected: only consider interfaces with at least one connection.
+// After: only return interfaces with names sorting after this one.
+// Limit: return at most this many interfaces if non-zero.
+//
+// After and Limit allow paging through the interfaces, the name of the last
+// returned interface being the cursor for the next page.
 type InfoOptions struct {
 	Names     []string
 	Doc       bool
 	Plugs     bool
 	Slots     bool
 	Connected bool
+	After     string
+	Limit     int
 }
 
 func (r *Repository) interfaceInfo(iface Interface, opts *InfoOptions) *Info {
@@ -141,6 +234,9 @@ func (r *Repository) interfaceInfo(iface Interface, opts *InfoOptions) *Info {
 	if opts != nil && opts.Doc {
 		// Collect documentation URL
 		ii.DocURL = si.DocURL
+		// Collect whether the core snap implicitly gets a slot
+		ii.ImplicitOnCore = si.ImplicitOnCore
+		ii.ImplicitOnClassic = si.ImplicitOnClassic
 	}
 	if opts != nil && opts.Plugs {
 		// Collect all plugs of this interface type.
@@ -215,6 +311,12 @@ func (r *Repository) Info(opts *InfoOptions) []*Info {
 	// Query each interface we are interested in.
 	infos := make([]*Info, 0, len(names))
 	for _, name := range names {
+		if opts != nil && opts.After != "" && name <= opts.After {
+			continue
+		}
+		if opts != nil && opts.Limit > 0 && len(infos) == opts.Limit {
+			break
+		}
 		if iface, ok := r.ifaces[name]; ok {
 			if connected == nil || connected[name] {
 				infos = append(infos, r.interfaceInfo(iface, opts))
@@ -337,6 +439,7 @@ func (r *Repository) AddPlug(plug *snap.PlugInfo) error {
 		r.plugs[snapName] = make(map[string]*snap.PlugInfo)
 	}
 	r.plugs[snapName][plug.Name] = plug
+	r.notify(&RepositoryEvent{Kind: PlugAddedEvent, Snap: snapName, Name: plug.Name})
 	return nil
 }
 
@@ -359,6 +462,7 @@ func (r *Repository) RemovePlug(snapName, plugName string) error {
 	if len(r.plugs[snapName]) == 0 {
 		delete(r.plugs, snapName)
 	}
+	r.notify(&RepositoryEvent{Kind: PlugRemovedEvent, Snap: snapName, Name: plugName})
 	return nil
 }
 
@@ -433,6 +537,7 @@ func (r *Repository) AddSlot(slot *snap.SlotInfo) error {
 		r.slots[snapName] = make(map[string]*snap.SlotInfo)
 	}
 	r.slots[snapName][slot.Name] = slot
+	r.notify(&RepositoryEvent{Kind: SlotAddedEvent, Snap: snapName, Name: slot.Name})
 	return nil
 }
 
@@ -456,6 +561,7 @@ func (r *Repository) RemoveSlot(snapName, slotName string) error {
 	if len(r.slots[snapName]) == 0 {
 		delete(r.slots, snapName)
 	}
+	r.notify(&RepositoryEvent{Kind: SlotRemovedEvent, Snap: snapName, Name: slotName})
 	return nil
 }
 
@@ -613,6 +719,7 @@ func (r *Repository) Connect(ref *ConnRef, plugStaticAttrs, plugDynamicAttrs, sl
 	conn := &Connection{Plug: cplug, Slot: cslot}
 	r.slotPlugs[slot][plug] = conn
 	r.plugSlots[plug][slot] = conn
+	r.notifyConnection(ConnectedEvent, plug, slot)
 	return conn, nil
 }
 
@@ -867,6 +974,7 @@ func (r *Repository) disconnect(plug *snap.PlugInfo, slot *snap.SlotInfo) {
 	if len(r.plugSlots[plug]) == 0 {
 		delete(r.plugSlots, plug)
 	}
+	r.notifyConnection(DisconnectedEvent, plug, slot)
 }
 
 // Backends returns all the security backends.
@@ -1021,6 +1129,7 @@ func (r *Repository) AddSnap(snapInfo *snap.Info) error {
 		}
 		r.slots[snapName][slotName] = slotInfo
 	}
+	r.notify(&RepositoryEvent{Kind: SnapAddedEvent, Snap: snapName})
 	return nil
 }
 
@@ -1047,6 +1156,7 @@ func (r *Repository) RemoveSnap(snapName string) error {
 		}
 	}
 
+	known := r.plugs[snapName] != nil || r.slots[snapName] != nil
 	for _, plug := range r.plugs[snapName] {
 		delete(r.plugSlots, plug)
 	}
@@ -1056,6 +1166,9 @@ func (r *Repository) RemoveSnap(snapName string) error {
 	}
 	delete(r.slots, snapName)
 
+	if known {
+		r.notify(&RepositoryEvent{Kind: SnapRemovedEvent, Snap: snapName})
+	}
 	return nil
 }
 
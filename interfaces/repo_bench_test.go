@@ -0,0 +1,145 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/snap"
+)
+
+var benchRepoSizes = []int{10, 100, 1000}
+
+// benchRepository returns a repository with n consumer and n producer
+// snaps, the plug of consumer-i is connected to the slot of producer-i.
+func benchRepository(b *testing.B, n int) *interfaces.Repository {
+	restore := snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {})
+	defer restore()
+
+	repo := interfaces.NewRepository()
+	iface := &ifacetest.TestInterface{
+		InterfaceName: "bench",
+		TestConnectedPlugCallback: func(spec *ifacetest.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddSnippet("connected plug")
+			return nil
+		},
+		TestPermanentPlugCallback: func(spec *ifacetest.Specification, plug *snap.PlugInfo) error {
+			spec.AddSnippet("permanent plug")
+			return nil
+		},
+	}
+	if err := repo.AddInterface(iface); err != nil {
+		b.Fatal(err)
+	}
+	if err := repo.AddBackend(&ifacetest.TestSecurityBackend{BackendName: "test"}); err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		for _, yaml := range []string{
+			fmt.Sprintf("name: consumer-%d\nversion: 0\napps:\n  app:\nplugs:\n  plug:\n    interface: bench\n", i),
+			fmt.Sprintf("name: producer-%d\nversion: 0\nslots:\n  slot:\n    interface: bench\n", i),
+		} {
+			info, err := snap.InfoFromSnapYaml([]byte(yaml))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := repo.AddSnap(info); err != nil {
+				b.Fatal(err)
+			}
+		}
+		_, err := repo.Connect(benchConnRef(i, i), nil, nil, nil, nil, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func benchConnRef(consumer, producer int) *interfaces.ConnRef {
+	return &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: fmt.Sprintf("consumer-%d", consumer), Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: fmt.Sprintf("producer-%d", producer), Name: "slot"},
+	}
+}
+
+func runRepoBenchmark(b *testing.B, bench func(b *testing.B, repo *interfaces.Repository, n int)) {
+	for _, n := range benchRepoSizes {
+		b.Run(fmt.Sprintf("snaps=%d", n), func(b *testing.B) {
+			repo := benchRepository(b, n)
+			b.ResetTimer()
+			bench(b, repo, n)
+		})
+	}
+}
+
+func BenchmarkRepositoryAllPlugs(b *testing.B) {
+	runRepoBenchmark(b, func(b *testing.B, repo *interfaces.Repository, n int) {
+		for i := 0; i < b.N; i++ {
+			if len(repo.AllPlugs("bench")) != n {
+				b.FailNow()
+			}
+		}
+	})
+}
+
+func BenchmarkRepositoryAllSlots(b *testing.B) {
+	runRepoBenchmark(b, func(b *testing.B, repo *interfaces.Repository, n int) {
+		for i := 0; i < b.N; i++ {
+			if len(repo.AllSlots("bench")) != n {
+				b.FailNow()
+			}
+		}
+	})
+}
+
+func BenchmarkRepositoryConnect(b *testing.B) {
+	runRepoBenchmark(b, func(b *testing.B, repo *interfaces.Repository, n int) {
+		for i := 0; i < b.N; i++ {
+			// connect the plug to a second slot so that the
+			// fixture connections are kept
+			ref := benchConnRef(i%n, (i+1)%n)
+			if _, err := repo.Connect(ref, nil, nil, nil, nil, nil); err != nil {
+				b.Fatal(err)
+			}
+			b.StopTimer()
+			if err := repo.Disconnect(ref.PlugRef.Snap, ref.PlugRef.Name, ref.SlotRef.Snap, ref.SlotRef.Name); err != nil {
+				b.Fatal(err)
+			}
+			b.StartTimer()
+		}
+	})
+}
+
+func BenchmarkRepositorySnapSpecification(b *testing.B) {
+	runRepoBenchmark(b, func(b *testing.B, repo *interfaces.Repository, n int) {
+		for i := 0; i < b.N; i++ {
+			spec, err := repo.SnapSpecification("test", fmt.Sprintf("consumer-%d", i%n))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(spec.(*ifacetest.Specification).Snippets) != 2 {
+				b.FailNow()
+			}
+		}
+	})
+}
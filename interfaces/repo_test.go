@@ -191,6 +191,39 @@ func (s *RepositorySuite) TestAddInterfaceInvalidName(c *C) {
 	c.Assert(s.emptyRepo.Interface(iface.Name()), IsNil)
 }
 
+// Tests for Repository.RemoveInterface()
+
+func (s *RepositorySuite) TestRemoveInterface(c *C) {
+	c.Assert(s.testRepo.AddPlug(s.plug), IsNil)
+	c.Assert(s.testRepo.AddSlot(s.slot), IsNil)
+	other := &ifacetest.TestInterface{InterfaceName: "other"}
+	c.Assert(s.testRepo.AddInterface(other), IsNil)
+
+	err := s.testRepo.RemoveInterface(s.iface.Name())
+	c.Assert(err, IsNil)
+	c.Check(s.testRepo.Interface(s.iface.Name()), IsNil)
+	c.Check(s.testRepo.AllInterfaces(), DeepEquals, []Interface{other})
+	// the plugs and slots of the interface are gone
+	c.Check(s.testRepo.AllPlugs(""), HasLen, 0)
+	c.Check(s.testRepo.AllSlots(""), HasLen, 0)
+}
+
+func (s *RepositorySuite) TestRemoveInterfaceErrors(c *C) {
+	err := s.emptyRepo.RemoveInterface("interface")
+	c.Check(err, ErrorMatches, `cannot remove interface "interface", no such interface`)
+
+	c.Assert(s.testRepo.AddPlug(s.plug), IsNil)
+	c.Assert(s.testRepo.AddSlot(s.slot), IsNil)
+	_, err = s.testRepo.Connect(NewConnRef(s.plug, s.slot), nil, nil, nil, nil, nil)
+	c.Assert(err, IsNil)
+	err = s.testRepo.RemoveInterface(s.iface.Name())
+	c.Check(err, ErrorMatches, `cannot remove interface "interface", (plug "plug" of snap "consumer"|slot "slot" of snap "producer") is still connected`)
+	// nothing was removed
+	c.Check(s.testRepo.Interface(s.iface.Name()), NotNil)
+	c.Check(s.testRepo.Plug(s.plug.Snap.InstanceName(), s.plug.Name), NotNil)
+	c.Check(s.testRepo.Slot(s.slot.Snap.InstanceName(), s.slot.Name), NotNil)
+}
+
 // Tests for Repository.AllInterfaces()
 
 func (s *RepositorySuite) TestAllInterfaces(c *C) {
@@ -1843,7 +1876,7 @@ func (s *RepositorySuite) TestInfo(c *C) {
 	// Add some test interfaces.
 	i1 := &ifacetest.TestInterface{InterfaceName: "i1", InterfaceStaticInfo: StaticInfo{Summary: "i1 summary", DocURL: "http://example.com/i1"}}
 	i2 := &ifacetest.TestInterface{InterfaceName: "i2", InterfaceStaticInfo: StaticInfo{Summary: "i2 summary", DocURL: "http://example.com/i2"}}
-	i3 := &ifacetest.TestInterface{InterfaceName: "i3", InterfaceStaticInfo: StaticInfo{Summary: "i3 summary", DocURL: "http://example.com/i3"}}
+	i3 := &ifacetest.TestInterface{InterfaceName: "i3", InterfaceStaticInfo: StaticInfo{Summary: "i3 summary", DocURL: "http://example.com/i3", ImplicitOnClassic: true}}
 	c.Assert(r.AddInterface(i1), IsNil)
 	c.Assert(r.AddInterface(i2), IsNil)
 	c.Assert(r.AddInterface(i3), IsNil)
@@ -1923,6 +1956,29 @@ apps:
 		{Name: "i2", Summary: "i2 summary", DocURL: "http://example.com/i2"},
 	})
 
+	// Documentation also tells whether slots are implicitly present.
+	infos = r.Info(&InfoOptions{Names: []string{"i3"}, Doc: true})
+	c.Assert(infos, DeepEquals, []*Info{
+		{Name: "i3", Summary: "i3 summary", DocURL: "http://example.com/i3", ImplicitOnClassic: true},
+	})
+
+	// We can page through the interfaces.
+	infos = r.Info(&InfoOptions{Limit: 2})
+	c.Assert(infos, DeepEquals, []*Info{
+		{Name: "i1", Summary: "i1 summary"},
+		{Name: "i2", Summary: "i2 summary"},
+	})
+	infos = r.Info(&InfoOptions{After: "i2", Limit: 2})
+	c.Assert(infos, DeepEquals, []*Info{
+		{Name: "i3", Summary: "i3 summary"},
+	})
+	infos = r.Info(&InfoOptions{Names: []string{"i1", "i3", "i4"}, After: "i1", Limit: 1})
+	c.Assert(infos, DeepEquals, []*Info{
+		{Name: "i3", Summary: "i3 summary"},
+	})
+	infos = r.Info(&InfoOptions{After: "i3"})
+	c.Assert(infos, HasLen, 0)
+
 	// We can ask for a list of plugs.
 	infos = r.Info(&InfoOptions{Names: []string{"i2"}, Plugs: true})
 	c.Assert(infos, DeepEquals, []*Info{
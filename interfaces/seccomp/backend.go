@@ -299,6 +299,16 @@ func (b *Backend) Remove(snapName string) error {
 	return nil
 }
 
+// ProfileGlobs returns the globs matching seccomp profiles of a given snap.
+func (b *Backend) ProfileGlobs(snapName string) []string {
+	return []string{filepath.Join(dirs.SnapSeccompDir, interfaces.SecurityTagGlob(snapName))}
+}
+
+// SpecificationSnippets returns the seccomp snippets of a given specification.
+func (b *Backend) SpecificationSnippets(spec interfaces.Specification) map[string][]string {
+	return spec.(*Specification).Snippets()
+}
+
 // Obtain the privilege dropping snippet
 func uidGidChownSnippet(name string) (string, error) {
 	tmp := strings.Replace(privDropAndChownSyscalls, "###USERNAME###", name, -1)
@@ -968,3 +968,27 @@ func (s *backendSuite) TestParallelCompileRemovesFirst(c *C) {
 	err = seccomp.ParallelCompile(&m, []string{"profile-001"})
 	c.Assert(err, ErrorMatches, "remove .*/profile-001.bin: permission denied")
 }
+
+func (s *backendSuite) TestProfileGlobs(c *C) {
+	globber, ok := s.Backend.(interfaces.SecurityBackendProfileGlobs)
+	c.Assert(ok, Equals, true)
+	c.Check(globber.ProfileGlobs("samba"), DeepEquals, []string{
+		filepath.Join(dirs.SnapSeccompDir, "snap.samba.*"),
+	})
+}
+
+func (s *backendSuite) TestSpecificationSnippets(c *C) {
+	s.Iface.SecCompPermanentSlotCallback = func(spec *seccomp.Specification, slot *snap.SlotInfo) error {
+		spec.AddSnippet("listen")
+		return nil
+	}
+	snapInfo := snaptest.MockInfo(c, ifacetest.SambaYamlV1, nil)
+	spec := s.Backend.NewSpecification()
+	c.Assert(spec.AddPermanentSlot(s.Iface, snapInfo.Slots["slot"]), IsNil)
+
+	snippeter, ok := s.Backend.(interfaces.SecurityBackendSnippets)
+	c.Assert(ok, Equals, true)
+	c.Check(snippeter.SpecificationSnippets(spec), DeepEquals, map[string][]string{
+		"snap.samba.smbd": {"listen"},
+	})
+}
@@ -0,0 +1,114 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/snapcore/snapd/snap"
+)
+
+// PlugsSlotsYaml renders the plugs and slots that the repository currently
+// holds for the given snap as the "plugs" and "slots" sections of a
+// snap.yaml, including their attributes. Plugs and slots that are bound to
+// some but not all of the apps of the snap list those apps in their
+// definition. Bindings to hooks are not rendered. Attributes holding the
+// default value of their interface are left out, as the defaults are set
+// again when the snap.yaml is read.
+func (r *Repository) PlugsSlotsYaml(snapName string) ([]byte, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	doc := make(map[string]interface{}, 2)
+	if len(r.plugs[snapName]) > 0 {
+		plugs := make(map[string]interface{}, len(r.plugs[snapName]))
+		for name, plug := range r.plugs[snapName] {
+			var defaults map[string]interface{}
+			if defaulter, ok := r.ifaces[plug.Interface].(AttrDefaulter); ok {
+				defaults = defaulter.PlugAttrDefaults()
+			}
+			def, err := definitionYaml("plug", name, plug.Interface, plug.Label, appNames(plug.Snap, plug.Apps), plug.Attrs, defaults)
+			if err != nil {
+				return nil, err
+			}
+			plugs[name] = def
+		}
+		doc["plugs"] = plugs
+	}
+	if len(r.slots[snapName]) > 0 {
+		slots := make(map[string]interface{}, len(r.slots[snapName]))
+		for name, slot := range r.slots[snapName] {
+			var defaults map[string]interface{}
+			if defaulter, ok := r.ifaces[slot.Interface].(AttrDefaulter); ok {
+				defaults = defaulter.SlotAttrDefaults()
+			}
+			def, err := definitionYaml("slot", name, slot.Interface, slot.Label, appNames(slot.Snap, slot.Apps), slot.Attrs, defaults)
+			if err != nil {
+				return nil, err
+			}
+			slots[name] = def
+		}
+		doc["slots"] = slots
+	}
+	return yaml.Marshal(doc)
+}
+
+// appNames returns the sorted names of the given apps, or nil if they are
+// all the apps of the snap.
+func appNames(info *snap.Info, apps map[string]*snap.AppInfo) []string {
+	if info == nil || len(apps) == len(info.Apps) {
+		return nil
+	}
+	names := make([]string, 0, len(apps))
+	for name := range apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func definitionYaml(plugOrSlot, name, iface, label string, apps []string, attrs, defaults map[string]interface{}) (map[string]interface{}, error) {
+	def := make(map[string]interface{}, len(attrs)+3)
+	for key, value := range attrs {
+		switch {
+		case key == "interface" || key == "label" || key == "apps":
+			return nil, fmt.Errorf("cannot render %s %q: attribute %q clashes with a definition field", plugOrSlot, name, key)
+		case strings.HasPrefix(key, "$"):
+			return nil, fmt.Errorf("cannot render %s %q: attribute %q is reserved", plugOrSlot, name, key)
+		}
+		if defValue, ok := defaults[key]; ok && reflect.DeepEqual(value, defValue) {
+			continue
+		}
+		def[key] = value
+	}
+	def["interface"] = iface
+	if label != "" {
+		def["label"] = label
+	}
+	if apps != nil {
+		def["apps"] = apps
+	}
+	return def, nil
+}
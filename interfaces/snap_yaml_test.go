@@ -0,0 +1,233 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package interfaces_test
+
+import (
+	"sort"
+
+	. "gopkg.in/check.v1"
+
+	. "github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type snapYamlSuite struct {
+	testutil.BaseTest
+	repo *Repository
+}
+
+var _ = Suite(&snapYamlSuite{})
+
+func (s *snapYamlSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.BaseTest.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	s.repo = NewRepository()
+	c.Assert(s.repo.AddInterface(&ifacetest.TestInterface{InterfaceName: "iface"}), IsNil)
+	c.Assert(s.repo.AddInterface(&ifacetest.TestInterface{InterfaceName: "other"}), IsNil)
+	c.Assert(s.repo.AddInterface(&ifacetest.TestInterface{
+		InterfaceName:         "defaulted",
+		PlugAttrDefaultValues: map[string]interface{}{"mode": "shared"},
+		SlotAttrDefaultValues: map[string]interface{}{"paths": []interface{}{"/run/foo"}},
+	}), IsNil)
+}
+
+func (s *snapYamlSuite) TearDownTest(c *C) {
+	s.BaseTest.TearDownTest(c)
+}
+
+const snapYamlHeader = `name: producer
+version: 0
+apps:
+  app1:
+    command: foo
+  app2:
+    command: bar
+`
+
+const snapYamlProducer = `name: producer
+version: 0
+apps:
+  app1:
+    command: foo
+    slots: [app-slot]
+  app2:
+    command: bar
+plugs:
+  iface:
+  plug:
+    interface: other
+    label: A plug
+    number: 42
+    flag: true
+    list: [a, 1, [b]]
+    nested:
+      key: value
+      ratio: 0.5
+  scoped:
+    interface: iface
+    apps: [app2]
+slots:
+  slot:
+    interface: iface
+    path: $SNAP/foo
+  app-slot: other
+`
+
+func (s *snapYamlSuite) TestRoundTrip(c *C) {
+	info := snaptest.MockInfo(c, snapYamlProducer+`
+hooks:
+  install:
+`, nil)
+	c.Assert(s.repo.AddSnap(info), IsNil)
+
+	out, err := s.repo.PlugsSlotsYaml("producer")
+	c.Assert(err, IsNil)
+
+	parsed, err := snap.InfoFromSnapYaml(append([]byte(snapYamlHeader), out...))
+	c.Assert(err, IsNil)
+
+	c.Assert(parsed.Plugs, HasLen, len(info.Plugs))
+	for name, plug := range info.Plugs {
+		other := parsed.Plugs[name]
+		c.Assert(other, NotNil, Commentf("plug %q", name))
+		c.Check(other.Interface, Equals, plug.Interface)
+		c.Check(other.Label, Equals, plug.Label)
+		c.Check(other.Attrs, DeepEquals, plug.Attrs)
+		c.Check(sortedAppNames(other.Apps), DeepEquals, sortedAppNames(plug.Apps), Commentf("plug %q", name))
+	}
+	c.Assert(parsed.Slots, HasLen, len(info.Slots))
+	for name, slot := range info.Slots {
+		other := parsed.Slots[name]
+		c.Assert(other, NotNil, Commentf("slot %q", name))
+		c.Check(other.Interface, Equals, slot.Interface)
+		c.Check(other.Label, Equals, slot.Label)
+		c.Check(other.Attrs, DeepEquals, slot.Attrs)
+		c.Check(sortedAppNames(other.Apps), DeepEquals, sortedAppNames(slot.Apps), Commentf("slot %q", name))
+	}
+	c.Check(sortedAppNames(parsed.Plugs["scoped"].Apps), DeepEquals, []string{"app2"})
+	c.Check(sortedAppNames(parsed.Plugs["plug"].Apps), DeepEquals, []string{"app1", "app2"})
+	c.Check(sortedAppNames(parsed.Slots["app-slot"].Apps), DeepEquals, []string{"app1"})
+}
+
+func (s *snapYamlSuite) TestRendering(c *C) {
+	info := snaptest.MockInfo(c, snapYamlProducer, nil)
+	c.Assert(s.repo.AddSnap(info), IsNil)
+
+	out, err := s.repo.PlugsSlotsYaml("producer")
+	c.Assert(err, IsNil)
+	c.Check(string(out), Equals, `plugs:
+  iface:
+    interface: iface
+  plug:
+    flag: true
+    interface: other
+    label: A plug
+    list:
+    - a
+    - 1
+    - - b
+    nested:
+      key: value
+      ratio: 0.5
+    number: 42
+  scoped:
+    apps:
+    - app2
+    interface: iface
+slots:
+  app-slot:
+    apps:
+    - app1
+    interface: other
+  slot:
+    interface: iface
+    path: $SNAP/foo
+`)
+}
+
+func (s *snapYamlSuite) TestRenderingDeclaredAttrs(c *C) {
+	info := snaptest.MockInfo(c, `name: producer
+version: 0
+plugs:
+  plug:
+    interface: defaulted
+    schema: v2
+  private:
+    interface: defaulted
+    mode: private
+slots:
+  slot:
+    interface: defaulted
+`, nil)
+	for _, plug := range info.Plugs {
+		c.Assert(BeforePreparePlug(s.repo.Interface("defaulted"), plug), IsNil)
+	}
+	c.Assert(BeforePrepareSlot(s.repo.Interface("defaulted"), info.Slots["slot"]), IsNil)
+	c.Assert(info.Plugs["plug"].Attrs["mode"], Equals, "shared")
+	c.Assert(s.repo.AddSnap(info), IsNil)
+
+	// the defaults are not rendered, "schema" is a regular attribute
+	out, err := s.repo.PlugsSlotsYaml("producer")
+	c.Assert(err, IsNil)
+	c.Check(string(out), Equals, `plugs:
+  plug:
+    interface: defaulted
+    schema: v2
+  private:
+    interface: defaulted
+    mode: private
+slots:
+  slot:
+    interface: defaulted
+`)
+}
+
+func (s *snapYamlSuite) TestUnknownSnap(c *C) {
+	out, err := s.repo.PlugsSlotsYaml("unknown")
+	c.Assert(err, IsNil)
+	c.Check(string(out), Equals, "{}\n")
+}
+
+func (s *snapYamlSuite) TestClashingAttribute(c *C) {
+	info := snaptest.MockInfo(c, snapYamlHeader, nil)
+	plug := &snap.PlugInfo{
+		Snap:      info,
+		Name:      "plug",
+		Interface: "iface",
+		Attrs:     map[string]interface{}{"label": "not a label"},
+	}
+	info.Plugs["plug"] = plug
+	c.Assert(s.repo.AddPlug(plug), IsNil)
+
+	_, err := s.repo.PlugsSlotsYaml("producer")
+	c.Check(err, ErrorMatches, `cannot render plug "plug": attribute "label" clashes with a definition field`)
+}
+
+func sortedAppNames(apps map[string]*snap.AppInfo) []string {
+	names := make([]string, 0, len(apps))
+	for name := range apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
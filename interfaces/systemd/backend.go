@@ -148,6 +148,11 @@ func (b *Backend) Remove(snapName string) error {
 	return errEnsure
 }
 
+// ProfileGlobs returns the globs matching systemd services of a given snap.
+func (b *Backend) ProfileGlobs(snapName string) []string {
+	return []string{filepath.Join(dirs.SnapServicesDir, serviceName(snapName, "*"))}
+}
+
 // NewSpecification returns a new systemd specification.
 func (b *Backend) NewSpecification() interfaces.Specification {
 	return &Specification{}
@@ -206,3 +206,11 @@ func (s *backendSuite) TestRemovingSnapWhenPreseeding(c *C) {
 		})
 	}
 }
+
+func (s *backendSuite) TestProfileGlobs(c *C) {
+	globber, ok := s.Backend.(interfaces.SecurityBackendProfileGlobs)
+	c.Assert(ok, Equals, true)
+	c.Check(globber.ProfileGlobs("samba"), DeepEquals, []string{
+		filepath.Join(dirs.SnapServicesDir, "snap.samba.interface.*.service"),
+	})
+}
@@ -29,6 +29,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/snapcore/snapd/dirs"
@@ -157,6 +158,33 @@ func (b *Backend) Remove(snapName string) error {
 	return b.reloadRules(nil)
 }
 
+// ProfileGlobs returns the path of the udev rules file of a given snap.
+func (b *Backend) ProfileGlobs(snapName string) []string {
+	return []string{snapRulesFilePath(snapName)}
+}
+
+// SpecificationSnippets returns the udev rules of a given specification,
+// grouped by the udev tag they apply to. Rules that are not specific to an
+// application or hook are grouped under the empty tag.
+func (b *Backend) SpecificationSnippets(spec interfaces.Specification) map[string][]string {
+	udevSpec := spec.(*Specification)
+	if udevSpec.ControlsDeviceCgroup() {
+		return nil
+	}
+	entries := make([]entry, len(udevSpec.entries))
+	copy(entries, udevSpec.entries)
+	sort.Sort(byTagAndSnippet(entries))
+
+	var snippets map[string][]string
+	for _, entry := range entries {
+		if snippets == nil {
+			snippets = make(map[string][]string)
+		}
+		snippets[entry.tag] = append(snippets[entry.tag], entry.snippet)
+	}
+	return snippets
+}
+
 func (b *Backend) deriveContent(spec *Specification, snapInfo *snap.Info) (content []string) {
 	content = append(content, spec.Snippets()...)
 	return content
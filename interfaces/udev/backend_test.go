@@ -21,6 +21,7 @@ package udev_test
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/sandbox/cgroup"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/timings"
 )
@@ -533,3 +535,46 @@ func (s *backendSuite) TestSandboxFeatures(c *C) {
 		"tagging",
 	})
 }
+
+func (s *backendSuite) TestProfileGlobs(c *C) {
+	globber, ok := s.Backend.(interfaces.SecurityBackendProfileGlobs)
+	c.Assert(ok, Equals, true)
+	c.Check(globber.ProfileGlobs("samba"), DeepEquals, []string{
+		filepath.Join(dirs.SnapUdevRulesDir, "70-snap.samba.rules"),
+	})
+}
+
+func (s *backendSuite) TestSpecificationSnippets(c *C) {
+	s.Iface.UDevPermanentSlotCallback = func(spec *udev.Specification, slot *snap.SlotInfo) error {
+		spec.AddSnippet(`KERNEL=="foo"`)
+		spec.TagDevice(`KERNEL=="bar"`)
+		return nil
+	}
+	snapInfo := snaptest.MockInfo(c, ifacetest.SambaYamlV1, nil)
+	spec := s.Backend.NewSpecification()
+	c.Assert(spec.AddPermanentSlot(s.Iface, snapInfo.Slots["slot"]), IsNil)
+
+	snippeter, ok := s.Backend.(interfaces.SecurityBackendSnippets)
+	c.Assert(ok, Equals, true)
+	c.Check(snippeter.SpecificationSnippets(spec), DeepEquals, map[string][]string{
+		"": {`KERNEL=="foo"`},
+		"snap_samba_smbd": {
+			"# iface\nKERNEL==\"bar\", TAG+=\"snap_samba_smbd\"",
+			fmt.Sprintf("TAG==\"snap_samba_smbd\", RUN+=\"%s/snap-device-helper $env{ACTION} snap_samba_smbd $devpath $major:$minor\"", dirs.DistroLibExecDir),
+		},
+	})
+}
+
+func (s *backendSuite) TestSpecificationSnippetsControlsDeviceCgroup(c *C) {
+	s.Iface.UDevPermanentSlotCallback = func(spec *udev.Specification, slot *snap.SlotInfo) error {
+		spec.TagDevice(`KERNEL=="bar"`)
+		spec.SetControlsDeviceCgroup()
+		return nil
+	}
+	snapInfo := snaptest.MockInfo(c, ifacetest.SambaYamlV1, nil)
+	spec := s.Backend.NewSpecification()
+	c.Assert(spec.AddPermanentSlot(s.Iface, snapInfo.Slots["slot"]), IsNil)
+
+	snippeter := s.Backend.(interfaces.SecurityBackendSnippets)
+	c.Check(snippeter.SpecificationSnippets(spec), IsNil)
+}
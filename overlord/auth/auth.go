@@ -62,6 +62,9 @@ type UserState struct {
 	Discharges      []string `json:"discharges,omitempty"`
 	StoreMacaroon   string   `json:"store-macaroon,omitempty"`
 	StoreDischarges []string `json:"store-discharges,omitempty"`
+	// Scopes restricts what the user is allowed to do, no scopes
+	// means the user is not restricted.
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 // identificationOnly returns a *UserState with only the
@@ -74,6 +77,20 @@ func (u *UserState) identificationOnly() *UserState {
 	}
 }
 
+// HasScope returns true if the user is allowed the given scope, that is if
+// the user is not restricted to some scopes or the scope is one of them.
+func (u *UserState) HasScope(scope string) bool {
+	if len(u.Scopes) == 0 {
+		return true
+	}
+	for _, s := range u.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // HasStoreAuth returns true if the user has store authorization.
 func (u *UserState) HasStoreAuth() bool {
 	if u == nil {
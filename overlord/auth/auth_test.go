@@ -309,6 +309,17 @@ func (as *authSuite) TestUserByUsername(c *C) {
 	c.Check(err, Equals, auth.ErrInvalidUser)
 }
 
+func (as *authSuite) TestUserHasScope(c *C) {
+	user := &auth.UserState{}
+	c.Check(user.HasScope("read"), Equals, true)
+	c.Check(user.HasScope("manage"), Equals, true)
+
+	user.Scopes = []string{"read", "manage-interfaces"}
+	c.Check(user.HasScope("read"), Equals, true)
+	c.Check(user.HasScope("manage-interfaces"), Equals, true)
+	c.Check(user.HasScope("manage"), Equals, false)
+}
+
 func (as *authSuite) TestUserHasStoreAuth(c *C) {
 	var user0 *auth.UserState
 	// nil user
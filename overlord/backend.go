@@ -20,8 +20,17 @@
 package overlord
 
 import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"time"
 
+	_ "golang.org/x/crypto/sha3"
+
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 )
 
@@ -31,9 +40,117 @@ type overlordStateBackend struct {
 }
 
 func (osb *overlordStateBackend) Checkpoint(data []byte) error {
-	return osutil.AtomicWriteFile(osb.path, data, 0600, 0)
+	return writeStateFile(osb.path, data)
 }
 
 func (osb *overlordStateBackend) EnsureBefore(d time.Duration) {
 	osb.ensureBefore(d)
 }
+
+// The checksum of the state is stored in the state file itself, under an
+// additional top-level key that older snapd versions ignore and drop when
+// they rewrite the file, so that data and checksum are always written
+// together. The previous generation of the state file is kept around and
+// used when the current one fails verification.
+const (
+	stateChecksumPrefix = `{"sha3-384":"`
+	statePreviousSuffix = ".prev"
+)
+
+func stateChecksum(data []byte) string {
+	h := crypto.SHA3_384.New()
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// addStateChecksum returns the JSON object in data with its checksum added
+// as the first key.
+func addStateChecksum(data []byte) []byte {
+	if len(data) < 2 || data[0] != '{' {
+		return data
+	}
+	var buf bytes.Buffer
+	buf.WriteString(stateChecksumPrefix)
+	buf.WriteString(stateChecksum(data))
+	buf.WriteString(`"`)
+	if data[1] != '}' {
+		buf.WriteString(",")
+	}
+	buf.Write(data[1:])
+	return buf.Bytes()
+}
+
+// verifyStateChecksum strips the checksum added by addStateChecksum from
+// data and verifies it. Data without a checksum, as written by older snapd
+// versions, is returned as is.
+func verifyStateChecksum(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(stateChecksumPrefix)) {
+		return data, nil
+	}
+	rest := data[len(stateChecksumPrefix):]
+	end := bytes.IndexByte(rest, '"')
+	if end < 0 {
+		return nil, fmt.Errorf("cannot find the end of the state checksum")
+	}
+	expected := string(rest[:end])
+	rest = rest[end+1:]
+	if len(rest) > 0 && rest[0] == ',' {
+		rest = rest[1:]
+	}
+	orig := append([]byte{'{'}, rest...)
+	if stateChecksum(orig) != expected {
+		return nil, fmt.Errorf("checksum mismatch")
+	}
+	return orig, nil
+}
+
+// linkStateFile atomically replaces dst with a hard link to src, it does
+// nothing if src does not exist.
+func linkStateFile(src, dst string) error {
+	tmp := dst + "~"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Link(src, tmp); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// writeStateFile atomically writes the state file at path, along with the
+// checksum of the data. The current generation of the state file is
+// preserved as the previous one first.
+func writeStateFile(path string, data []byte) error {
+	if err := linkStateFile(path, path+statePreviousSuffix); err != nil {
+		return fmt.Errorf("cannot preserve previous state file: %v", err)
+	}
+	return osutil.AtomicWriteFile(path, addStateChecksum(data), 0600, 0)
+}
+
+// readStateFile reads the state file at path and verifies its checksum. If
+// the verification fails, the previous generation of the state file is used
+// instead, provided it can be verified.
+func readStateFile(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	verified, err := verifyStateChecksum(data)
+	if err == nil {
+		return verified, nil
+	}
+
+	prevPath := path + statePreviousSuffix
+	prev, prevErr := ioutil.ReadFile(prevPath)
+	if prevErr == nil {
+		prev, prevErr = verifyStateChecksum(prev)
+	}
+	if prevErr != nil {
+		return nil, fmt.Errorf("%v, and cannot use the previous generation: %v", err, prevErr)
+	}
+	logger.Noticef("WARNING: cannot use state file %s: %v, using the previous generation from %s", path, err, prevPath)
+	return prev, nil
+}
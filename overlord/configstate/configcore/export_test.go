@@ -48,3 +48,11 @@ func MockChownPath(f func(string, sys.UserID, sys.GroupID) error) func() {
 		sysChownPath = old
 	}
 }
+
+func MockAllowReservedNames(f func([]string)) func() {
+	old := builtinAllowReservedNames
+	builtinAllowReservedNames = f
+	return func() {
+		builtinAllowReservedNames = old
+	}
+}
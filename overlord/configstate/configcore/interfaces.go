@@ -0,0 +1,74 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build !nomanagers
+// +build !nomanagers
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/snap"
+)
+
+const allowReservedNamesOpt = "interfaces.allow-reserved-names"
+
+var builtinAllowReservedNames = builtin.AllowReservedNames
+
+func init() {
+	// add supported configuration of this module
+	supportedConfigurations["core."+allowReservedNamesOpt] = true
+}
+
+// AllowedReservedNames returns the names of built-in interfaces that the
+// interfaces.allow-reserved-names option lets plugs and slots of other
+// interfaces use.
+func AllowedReservedNames(tr config.ConfGetter) ([]string, error) {
+	value, err := coreCfg(tr, allowReservedNamesOpt)
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, nil
+	}
+	names := strings.Split(value, ",")
+	for _, name := range names {
+		if err := snap.ValidateInterfaceName(name); err != nil {
+			return nil, fmt.Errorf("cannot set %q: %v", allowReservedNamesOpt, err)
+		}
+	}
+	return names, nil
+}
+
+func validateAllowReservedNames(tr config.Conf) error {
+	_, err := AllowedReservedNames(tr)
+	return err
+}
+
+func handleAllowReservedNames(tr config.Conf, opts *fsOnlyContext) error {
+	names, err := AllowedReservedNames(tr)
+	if err != nil {
+		return err
+	}
+	builtinAllowReservedNames(names)
+	return nil
+}
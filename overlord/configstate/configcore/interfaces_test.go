@@ -0,0 +1,95 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
+)
+
+type interfacesSuite struct {
+	configcoreSuite
+
+	allowed [][]string
+}
+
+var _ = Suite(&interfacesSuite{})
+
+func (s *interfacesSuite) SetUpTest(c *C) {
+	s.configcoreSuite.SetUpTest(c)
+
+	s.allowed = nil
+	s.AddCleanup(configcore.MockAllowReservedNames(func(names []string) {
+		s.allowed = append(s.allowed, names)
+	}))
+}
+
+func (s *interfacesSuite) TestConfigureAllowReservedNames(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"interfaces.allow-reserved-names": "network,x11",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.allowed, DeepEquals, [][]string{{"network", "x11"}})
+}
+
+func (s *interfacesSuite) TestConfigureAllowReservedNamesUnset(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"interfaces.allow-reserved-names": "network",
+		},
+		changes: map[string]interface{}{
+			"interfaces.allow-reserved-names": "",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(s.allowed, DeepEquals, [][]string{nil})
+}
+
+func (s *interfacesSuite) TestConfigureAllowReservedNamesInvalid(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"interfaces.allow-reserved-names": "network,-bad-",
+		},
+	})
+	c.Assert(err, ErrorMatches, `cannot set "interfaces.allow-reserved-names": invalid interface name: "-bad-"`)
+	c.Check(s.allowed, HasLen, 0)
+}
+
+func (s *interfacesSuite) TestAllowedReservedNames(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	names, err := configcore.AllowedReservedNames(tr)
+	c.Assert(err, IsNil)
+	c.Check(names, HasLen, 0)
+
+	c.Assert(tr.Set("core", "interfaces.allow-reserved-names", "camera"), IsNil)
+	names, err = configcore.AllowedReservedNames(tr)
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"camera"})
+}
@@ -59,6 +59,9 @@ func init() {
 	addWithStateHandler(validateRefreshRateLimit, nil, validateOnly)
 	addWithStateHandler(validateAutomaticSnapshotsExpiration, nil, validateOnly)
 
+	// interfaces.allow-reserved-names
+	addWithStateHandler(validateAllowReservedNames, handleAllowReservedNames, nil)
+
 	// netplan.*
 	addWithStateHandler(validateNetplanSettings, handleNetplanConfiguration, &flags{coreOnlyConfig: true})
 }
@@ -72,6 +72,10 @@ func NewDisconnectOptsWithAutoSet() disconnectOpts {
 	return disconnectOpts{AutoDisconnect: true}
 }
 
+func NewDisconnectOptsWithRetainSet() disconnectOpts {
+	return disconnectOpts{AutoDisconnect: true, RetainForReinstall: true}
+}
+
 func NewDisconnectOptsWithByHotplugSet() disconnectOpts {
 	return disconnectOpts{ByHotplug: true}
 }
@@ -175,3 +179,15 @@ func (m *InterfaceManager) TransitionConnectionsCoreMigration(st *state.State, o
 func (m *InterfaceManager) SetupSecurityByBackend(task *state.Task, snaps []*snap.Info, opts []interfaces.ConfinementOptions, tm timings.Measurer) error {
 	return m.setupSecurityByBackend(task, snaps, opts, tm)
 }
+
+func MockTimeNow(fn func() time.Time) func() {
+	old := timeNow
+	timeNow = fn
+	return func() { timeNow = old }
+}
+
+func RecordConnsHistory(task *state.Task, conns map[string]*connState) error {
+	return recordConnsHistory(task, conns)
+}
+
+var ConnsHistoryLimit = connsHistoryLimit
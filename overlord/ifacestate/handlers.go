@@ -98,6 +98,9 @@ func (m *InterfaceManager) doSetupProfiles(task *state.Task, tomb *tomb.Tomb) er
 	if len(snapInfo.BadInterfaces) > 0 {
 		task.State().Warnf("%s", snap.BadInterfacesSummary(snapInfo))
 	}
+	if len(snapInfo.LegacySecurityNotes) > 0 {
+		task.State().Warnf("%s", snap.LegacySecuritySummary(snapInfo))
+	}
 
 	// We no longer do/need core-phase-2, see
 	//   https://github.com/snapcore/snapd/pull/5301
@@ -158,10 +161,13 @@ func (m *InterfaceManager) setupProfilesForSnap(task *state.Task, _ *tomb.Tomb,
 	// exception that the snap being setup is always first. The affectedSnaps
 	// array may be shorter than the set of affected snaps in case any of the
 	// snaps cannot be found in the state.
-	reconnectedSnaps, err := m.reloadConnections(snapName)
+	reconnectedSnaps, dropped, err := m.reloadConnections(snapName)
 	if err != nil {
 		return err
 	}
+	if len(dropped) > 0 {
+		task.Logf("cannot restore connections of snap %q: %s", snapName, strings.Join(dropped, ", "))
+	}
 	affectedSet := make(map[string]bool)
 	for _, name := range disconnectedSnaps {
 		affectedSet[name] = true
@@ -364,6 +370,101 @@ func (m *InterfaceManager) undoDiscardConns(task *state.Task, _ *tomb.Tomb) erro
 	return nil
 }
 
+// doImportConns records the undesired and pending connections of an
+// imported connections profile. The previous pending connections are kept
+// in the task, so that undo can restore them.
+func (m *InterfaceManager) doImportConns(task *state.Task, _ *tomb.Tomb) error {
+	st := task.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var undesired, pending map[string]string
+	if err := task.Get("undesired", &undesired); err != nil && err != state.ErrNoState {
+		return err
+	}
+	if err := task.Get("pending", &pending); err != nil && err != state.ErrNoState {
+		return err
+	}
+
+	conns, err := getConns(st)
+	if err != nil {
+		return err
+	}
+	var added []string
+	for id, ifaceName := range undesired {
+		if _, ok := conns[id]; ok {
+			continue
+		}
+		conns[id] = &connState{Interface: ifaceName, Undesired: true}
+		added = append(added, id)
+	}
+	setConns(st, conns)
+
+	oldPending, err := getPendingConns(st)
+	if err != nil {
+		return err
+	}
+	newPending := make(map[string]string, len(oldPending)+len(pending))
+	replaced := make(map[string]string, len(pending))
+	for id, ifaceName := range oldPending {
+		newPending[id] = ifaceName
+	}
+	for id, ifaceName := range pending {
+		// an empty interface name means there was no pending connection
+		replaced[id] = oldPending[id]
+		newPending[id] = ifaceName
+	}
+	setPendingConns(st, newPending)
+
+	task.Set("added-undesired", added)
+	task.Set("replaced-pending", replaced)
+	return nil
+}
+
+func (m *InterfaceManager) undoImportConns(task *state.Task, _ *tomb.Tomb) error {
+	st := task.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var added []string
+	var replaced map[string]string
+	if err := task.Get("added-undesired", &added); err != nil && err != state.ErrNoState {
+		return err
+	}
+	if err := task.Get("replaced-pending", &replaced); err != nil && err != state.ErrNoState {
+		return err
+	}
+
+	conns, err := getConns(st)
+	if err != nil {
+		return err
+	}
+	for _, id := range added {
+		// the connection may have been established since
+		if conn, ok := conns[id]; ok && conn.Undesired {
+			delete(conns, id)
+		}
+	}
+	setConns(st, conns)
+
+	pending, err := getPendingConns(st)
+	if err != nil {
+		return err
+	}
+	for id, ifaceName := range replaced {
+		if ifaceName == "" {
+			delete(pending, id)
+		} else {
+			pending[id] = ifaceName
+		}
+	}
+	setPendingConns(st, pending)
+
+	task.Set("added-undesired", nil)
+	task.Set("replaced-pending", nil)
+	return nil
+}
+
 func getDynamicHookAttributes(task *state.Task) (plugAttrs, slotAttrs map[string]interface{}, err error) {
 	if err = task.Get("plug-dynamic", &plugAttrs); err != nil && err != state.ErrNoState {
 		return nil, nil, err
@@ -487,21 +588,28 @@ func (m *InterfaceManager) doConnect(task *state.Task, _ *tomb.Tomb) (err error)
 	if err != nil || conn == nil {
 		return err
 	}
+	slotOpts := confinementOptions(slotSnapst.Flags)
+	plugOpts := confinementOptions(plugSnapst.Flags)
 	defer func() {
 		if err != nil {
 			if err := m.repo.Disconnect(plugRef.Snap, plugRef.Name, slotRef.Snap, slotRef.Name); err != nil {
 				logger.Noticef("cannot undo failed connection: %v", err)
+				return
+			}
+			if !delayedSetupProfiles {
+				// some backends may have already written profiles
+				// that include the connection, bring them back in
+				// sync with the repository
+				m.restoreSecurityByBackend(task, []*snap.Info{slot.Snap, plug.Snap}, []interfaces.ConfinementOptions{slotOpts, plugOpts}, perfTimings)
 			}
 		}
 	}()
 
 	if !delayedSetupProfiles {
-		slotOpts := confinementOptions(slotSnapst.Flags)
 		if err := m.setupSnapSecurity(task, slot.Snap, slotOpts, perfTimings); err != nil {
 			return err
 		}
 
-		plugOpts := confinementOptions(plugSnapst.Flags)
 		if err := m.setupSnapSecurity(task, plug.Snap, plugOpts, perfTimings); err != nil {
 			return err
 		}
@@ -518,6 +626,9 @@ func (m *InterfaceManager) doConnect(task *state.Task, _ *tomb.Tomb) (err error)
 		task.Set("old-conn", old)
 	}
 
+	if err := recordConnsHistory(task, conns); err != nil {
+		return err
+	}
 	conns[connRef.ID()] = &connState{
 		Interface:        conn.Interface(),
 		StaticPlugAttrs:  conn.Plug.StaticAttrs(),
@@ -590,6 +701,9 @@ func (m *InterfaceManager) doDisconnect(task *state.Task, _ *tomb.Tomb) error {
 		_, noPlugOrSlot := err.(*interfaces.NoPlugOrSlotError)
 		// not connected, just forget it.
 		if forget && (notConnected || noPlugOrSlot) {
+			if err := recordConnsHistory(task, conns); err != nil {
+				return err
+			}
 			delete(conns, cref.ID())
 			setConns(st, conns)
 			return nil
@@ -622,6 +736,9 @@ func (m *InterfaceManager) doDisconnect(task *state.Task, _ *tomb.Tomb) error {
 		return fmt.Errorf("internal error: cannot read 'by-hotplug' flag: %s", err)
 	}
 
+	if err := recordConnsHistory(task, conns); err != nil {
+		return err
+	}
 	switch {
 	case forget:
 		delete(conns, cref.ID())
@@ -640,6 +757,22 @@ func (m *InterfaceManager) doDisconnect(task *state.Task, _ *tomb.Tomb) error {
 	}
 	setConns(st, conns)
 
+	// "retain-for-reinstall" flag indicates that a manual connection severed
+	// by the removal of a snap should be restored when the snap is installed
+	// again; automatic connections are simply re-created then.
+	var retain bool
+	if err := task.Get("retain-for-reinstall", &retain); err != nil && err != state.ErrNoState {
+		return fmt.Errorf("internal error: cannot read 'retain-for-reinstall' flag: %s", err)
+	}
+	if autoDisconnect && retain && !conn.Auto {
+		retained, err := getRetainedConns(st)
+		if err != nil {
+			return err
+		}
+		retained[cref.ID()] = conn.Interface
+		setRetainedConns(st, retained)
+	}
+
 	return nil
 }
 
@@ -670,6 +803,19 @@ func (m *InterfaceManager) undoDisconnect(task *state.Task, _ *tomb.Tomb) error
 		return err
 	}
 
+	var retain bool
+	if err := task.Get("retain-for-reinstall", &retain); err != nil && err != state.ErrNoState {
+		return fmt.Errorf("internal error: cannot read 'retain-for-reinstall' flag: %s", err)
+	}
+	if retain {
+		retained, err := getRetainedConns(st)
+		if err != nil {
+			return err
+		}
+		delete(retained, (&interfaces.ConnRef{PlugRef: plugRef, SlotRef: slotRef}).ID())
+		setRetainedConns(st, retained)
+	}
+
 	conns, err := getConns(st)
 	if err != nil {
 		return err
@@ -1193,6 +1339,23 @@ func (m *InterfaceManager) doAutoConnect(task *state.Task, _ *tomb.Tomb) error {
 		}
 	}
 
+	// Restore the manual connections retained when the snap was removed,
+	// they are established as manual connections again.
+	restored, err := addRetainedConnections(st, task, m.repo, snapName, "", deviceCtx, newconns, conns, conflictError)
+	if err != nil {
+		return err
+	}
+	if len(restored) > 0 {
+		if connOpts == nil {
+			connOpts = make(map[string]*connectOpts, len(restored))
+		}
+		for key := range restored {
+			if _, ok := newconns[key]; ok && connOpts[key] == nil {
+				connOpts[key] = &connectOpts{}
+			}
+		}
+	}
+
 	// Auto-connect all the plugs
 	cannotAutoConnectLog := func(plug *snap.PlugInfo, candRefs []string) string {
 		return fmt.Sprintf("cannot auto-connect plug %s, candidates found: %s", plug, strings.Join(candRefs, ", "))
@@ -1220,6 +1383,23 @@ func (m *InterfaceManager) doAutoConnect(task *state.Task, _ *tomb.Tomb) error {
 		return err
 	}
 
+	// the task cannot be retried anymore, the restored connections are
+	// not retained or pending anymore
+	wasPending, err := removeRetainedConns(st, restored)
+	if err != nil {
+		return err
+	}
+	for id := range wasPending {
+		delete(restored, id)
+	}
+	// for undo
+	if len(restored) > 0 {
+		task.Set("restored-conns", restored)
+	}
+	if len(wasPending) > 0 {
+		task.Set("restored-pending-conns", wasPending)
+	}
+
 	// If interface hooks are not present then connects can be executed during
 	// preseeding.
 	// Otherwise we will run all connects, their hooks and setup-profiles after
@@ -1284,6 +1464,14 @@ func (m *InterfaceManager) doAutoDisconnect(task *state.Task, _ *tomb.Tomb) erro
 		return err
 	}
 
+	// "purge" flag indicates the snap is removed with snap remove --purge,
+	// in which case its manual connections are not retained for a later
+	// reinstall and the ones retained earlier are forgotten.
+	var purge bool
+	if err := task.Get("purge", &purge); err != nil && err != state.ErrNoState {
+		return fmt.Errorf("internal error: cannot read 'purge' flag: %s", err)
+	}
+
 	// check for conflicts on all connections first before creating disconnect hooks
 	for _, connRef := range connections {
 		if err := checkDisconnectConflicts(st, snapName, connRef.PlugRef.Snap, connRef.SlotRef.Snap); err != nil {
@@ -1296,6 +1484,12 @@ func (m *InterfaceManager) doAutoDisconnect(task *state.Task, _ *tomb.Tomb) erro
 		}
 	}
 
+	if purge {
+		if err := forgetRetainedConns(st, snapName); err != nil {
+			return err
+		}
+	}
+
 	hookTasks := state.NewTaskSet()
 	for _, connRef := range connections {
 		conn, err := m.repo.Connection(connRef)
@@ -1305,7 +1499,7 @@ func (m *InterfaceManager) doAutoDisconnect(task *state.Task, _ *tomb.Tomb) erro
 		// "auto-disconnect" flag indicates it's a disconnect triggered as part of snap removal, in which
 		// case we want to skip the logic of marking auto-connections as 'undesired' and instead just remove
 		// them so they can be automatically connected if the snap is installed again.
-		ts, err := disconnectTasks(st, conn, disconnectOpts{AutoDisconnect: true})
+		ts, err := disconnectTasks(st, conn, disconnectOpts{AutoDisconnect: true, RetainForReinstall: !purge})
 		if err != nil {
 			return err
 		}
@@ -1322,6 +1516,39 @@ func (m *InterfaceManager) doAutoDisconnect(task *state.Task, _ *tomb.Tomb) erro
 func (m *InterfaceManager) undoAutoConnect(task *state.Task, _ *tomb.Tomb) error {
 	// TODO Introduce disconnection hooks, and run them here as well to give a chance
 	// for the snap to undo whatever it did when the connection was established.
+	st := task.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var restored, restoredPending map[string]string
+	if err := task.Get("restored-conns", &restored); err != nil && err != state.ErrNoState {
+		return err
+	}
+	if err := task.Get("restored-pending-conns", &restoredPending); err != nil && err != state.ErrNoState {
+		return err
+	}
+	if len(restored) > 0 {
+		retained, err := getRetainedConns(st)
+		if err != nil {
+			return err
+		}
+		for id, ifaceName := range restored {
+			retained[id] = ifaceName
+		}
+		setRetainedConns(st, retained)
+		task.Set("restored-conns", nil)
+	}
+	if len(restoredPending) > 0 {
+		pending, err := getPendingConns(st)
+		if err != nil {
+			return err
+		}
+		for id, ifaceName := range restoredPending {
+			pending[id] = ifaceName
+		}
+		setPendingConns(st, pending)
+		task.Set("restored-pending-conns", nil)
+	}
 	return nil
 }
 
@@ -1360,7 +1587,7 @@ func (m *InterfaceManager) transitionConnectionsCoreMigration(st *state.State, o
 	// on disk are rewritten. This is ok because core/ubuntu-core have
 	// exactly the same profiles and nothing in the generated policies
 	// has the core snap-name encoded.
-	if _, err := m.reloadConnections(newName); err != nil {
+	if _, _, err := m.reloadConnections(newName); err != nil {
 		return err
 	}
 
@@ -1483,6 +1710,16 @@ func (m *InterfaceManager) doHotplugConnect(task *state.Task, _ *tomb.Tomb) erro
 		return err
 	}
 
+	// establish the pending connections of the slot as manual connections
+	pending, err := addRetainedConnections(st, task, m.repo, instanceName, slot.Name, deviceCtx, newconns, conns, conflictError)
+	if err != nil {
+		return err
+	}
+
+	if _, err := removeRetainedConns(st, pending); err != nil {
+		return err
+	}
+
 	if len(recreate) == 0 && len(newconns) == 0 {
 		return nil
 	}
@@ -1498,8 +1735,9 @@ func (m *InterfaceManager) doHotplugConnect(task *state.Task, _ *tomb.Tomb) erro
 		connectTs.AddAll(ts)
 	}
 	// Create connect tasks and interface hooks for new auto-connections
-	for _, conn := range newconns {
-		ts, err := connect(st, conn.PlugRef.Snap, conn.PlugRef.Name, conn.SlotRef.Snap, conn.SlotRef.Name, connectOpts{AutoConnect: true})
+	for id, conn := range newconns {
+		_, isPending := pending[id]
+		ts, err := connect(st, conn.PlugRef.Snap, conn.PlugRef.Name, conn.SlotRef.Snap, conn.SlotRef.Name, connectOpts{AutoConnect: !isPending})
 		if err != nil {
 			return fmt.Errorf("internal error: auto-connect of %q failed: %s", conn, err)
 		}
@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -37,6 +38,8 @@ import (
 	"github.com/snapcore/snapd/jsonutil"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/configstate/configcore"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
@@ -52,17 +55,92 @@ func (m *InterfaceManager) selectInterfaceMapper(snaps []*snap.Info) {
 	}
 }
 
+// applyAllowedReservedNames exempts the plug and slot names listed in the
+// interfaces.allow-reserved-names system option from the reserved name
+// check, it must be done before any snap is read from disk.
+func (m *InterfaceManager) applyAllowedReservedNames() error {
+	tr := config.NewTransaction(m.state)
+	names, err := configcore.AllowedReservedNames(tr)
+	if err != nil {
+		return err
+	}
+	builtin.AllowReservedNames(names)
+	return nil
+}
+
+// deviceModel returns the model assertion of the device or nil if it
+// is not known yet.
+func (m *InterfaceManager) deviceModel() (*asserts.Model, error) {
+	deviceCtx, err := snapstate.DeviceCtx(m.state, nil, nil)
+	if err == state.ErrNoState {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return deviceCtx.Model(), nil
+}
+
+// addInterfaces adds the builtin and extra interfaces allowed by the model
+// to the repository. If the model is not known yet, which is the case
+// before the device is seeded, all interfaces are added and the ones that
+// are not allowed are removed by filterInterfacesByModel once the model
+// is set.
 func (m *InterfaceManager) addInterfaces(extra []interfaces.Interface) error {
+	model, err := m.deviceModel()
+	if err != nil {
+		return err
+	}
+	m.interfacesFiltered = model != nil
+	add := func(iface interfaces.Interface) error {
+		if model != nil && !model.InterfaceAllowed(iface.Name()) {
+			logger.Debugf("interface %q is not allowed on this model", iface.Name())
+			return nil
+		}
+		return m.repo.AddInterface(iface)
+	}
 	for _, iface := range builtin.Interfaces() {
-		if err := m.repo.AddInterface(iface); err != nil {
+		if err := add(iface); err != nil {
 			return err
 		}
 	}
 	for _, iface := range extra {
-		if err := m.repo.AddInterface(iface); err != nil {
+		if err := add(iface); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterInterfacesByModel removes the interfaces that are not allowed by
+// the model from the repository, if they were added before the model was
+// known.
+func (m *InterfaceManager) filterInterfacesByModel() error {
+	if m.interfacesFiltered {
+		return nil
+	}
+
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	model, err := m.deviceModel()
+	if err != nil {
+		return err
+	}
+	if model == nil {
+		// too early
+		return nil
+	}
+	for _, iface := range m.repo.AllInterfaces() {
+		if model.InterfaceAllowed(iface.Name()) {
+			continue
+		}
+		if err := m.repo.RemoveInterface(iface.Name()); err != nil {
 			return err
 		}
+		logger.Debugf("interface %q is not allowed on this model", iface.Name())
 	}
+	m.interfacesFiltered = true
 	return nil
 }
 
@@ -238,6 +316,81 @@ var removeStaleConnections = func(st *state.State) error {
 	return nil
 }
 
+// profileSnapNames returns the names of the snaps that have files generated
+// by the given backend. The snap name is the second dot-separated component
+// of the file name, which is checked against the globs of that snap.
+func profileSnapNames(globber interfaces.SecurityBackendProfileGlobs) ([]string, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, glob := range globber.ProfileGlobs("*") {
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range matches {
+			parts := strings.SplitN(filepath.Base(path), ".", 3)
+			if len(parts) < 2 || seen[parts[1]] {
+				continue
+			}
+			name := parts[1]
+			for _, snapGlob := range globber.ProfileGlobs(name) {
+				if ok, _ := filepath.Match(snapGlob, path); ok {
+					seen[name] = true
+					names = append(names, name)
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// removeOrphanedProfiles removes the security profiles left behind for snaps
+// that are not known to the system anymore, e.g. after a crash in the middle
+// of a snap removal or after manual changes to the state. Snaps that are
+// installed or about to be linked (see snapsWithSecurityProfiles) are never
+// considered orphaned. Only backends that can tell where their profiles are
+// stored are considered.
+func (m *InterfaceManager) removeOrphanedProfiles() error {
+	snapStates, err := snapstate.All(m.state)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(snapStates))
+	for name := range snapStates {
+		known[name] = true
+	}
+	infos, err := snapsWithSecurityProfiles(m.state)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		known[info.InstanceName()] = true
+	}
+	for _, backend := range m.repo.Backends() {
+		globber, ok := backend.(interfaces.SecurityBackendProfileGlobs)
+		if !ok {
+			continue
+		}
+		names, err := profileSnapNames(globber)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if known[name] {
+				continue
+			}
+			if err := backend.Remove(name); err != nil {
+				logger.Noticef("cannot remove orphaned %s profiles of snap %q: %s", backend.Name(), name, err)
+				continue
+			}
+			logger.Noticef("removed orphaned %s profiles of snap %q", backend.Name(), name)
+		}
+	}
+	return nil
+}
+
 func isBroken(st *state.State, snapName string) (bool, error) {
 	var snapst snapstate.SnapState
 	err := snapstate.Get(st, snapName, &snapst)
@@ -258,11 +411,13 @@ func isBroken(st *state.State, snapName string) (bool, error) {
 // Using non-empty snapName the operation can be scoped to connections
 // affecting a given snap.
 //
-// The return value is the list of affected snap names.
-func (m *InterfaceManager) reloadConnections(snapName string) ([]string, error) {
+// The return values are the list of affected snap names and the sorted IDs of
+// the connections that could not be restored, either because the reloaded snap
+// no longer has their plug or slot or because the repository refused them.
+func (m *InterfaceManager) reloadConnections(snapName string) (affectedSnaps, dropped []string, err error) {
 	conns, err := getConns(m.state)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	connStateChanged := false
@@ -278,7 +433,7 @@ ConnsLoop:
 		}
 		connRef, err := interfaces.ParseConnRef(connId)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		// Apply filtering, this allows us to reload only a subset of
 		// connections (and similarly, refresh the static attributes of only a
@@ -293,6 +448,12 @@ ConnsLoop:
 		// The connection refers to a plug or slot that doesn't exist anymore, e.g. because of a refresh
 		// to a new snap revision that doesn't have the given plug/slot.
 		if plugInfo == nil || slotInfo == nil {
+			// only report connections whose plug or slot disappeared
+			// from the snap being reloaded, not ones of absent snaps
+			if (plugInfo == nil && (snapName == "" || connRef.PlugRef.Snap == snapName)) ||
+				(slotInfo == nil && (snapName == "" || connRef.SlotRef.Snap == snapName)) {
+				dropped = append(dropped, connId)
+			}
 			// automatic connection can simply be removed (it will be re-created automatically if needed)
 			// as long as it wasn't disconnected manually; note that undesired flag is taken care of at
 			// the beginning of the loop.
@@ -302,7 +463,7 @@ ConnsLoop:
 				for _, snapName := range []string{connRef.PlugRef.Snap, connRef.SlotRef.Snap} {
 					broken, err := isBroken(m.state, snapName)
 					if err != nil {
-						return nil, err
+						return nil, nil, err
 					}
 					if broken {
 						logger.Noticef("Snap %q is broken, ignored by reloadConnections", snapName)
@@ -346,6 +507,7 @@ ConnsLoop:
 		// Note: reloaded connections are not checked against policy again, and also we don't call BeforeConnect* methods on them.
 		if _, err := m.repo.Connect(connRef, staticPlugAttrs, connState.DynamicPlugAttrs, staticSlotAttrs, connState.DynamicSlotAttrs, nil); err != nil {
 			logger.Noticef("%s", err)
+			dropped = append(dropped, connId)
 		} else {
 			// If the connection succeeded update the connection state and keep
 			// track of the snaps that were affected.
@@ -367,7 +529,8 @@ ConnsLoop:
 	for name := range affected {
 		result = append(result, name)
 	}
-	return result, nil
+	sort.Strings(dropped)
+	return result, dropped, nil
 }
 
 // removeConnections disconnects all connections of the snap in the repo. It should only be used if the snap
@@ -427,6 +590,39 @@ func (m *InterfaceManager) setupSecurityByBackend(task *state.Task, snaps []*sna
 	return nil
 }
 
+// restoreSecurityByBackend regenerates the security profiles of the given
+// snaps after an operation failed part way through setting them up, so that
+// backends which already processed the snaps do not keep profiles that no
+// longer match the repository. Unlike setupSecurityByBackend it runs all the
+// backends regardless of errors; those are logged and not returned so that the
+// error of the original operation is the one reported to the user.
+func (m *InterfaceManager) restoreSecurityByBackend(task *state.Task, snaps []*snap.Info, opts []interfaces.ConfinementOptions, tm timings.Measurer) {
+	confOpts := make(map[string]interfaces.ConfinementOptions, len(snaps))
+	for i, snapInfo := range snaps {
+		confOpts[snapInfo.InstanceName()] = opts[i]
+	}
+
+	st := task.State()
+	st.Unlock()
+	var failed []string
+	for _, backend := range m.repo.Backends() {
+		errs := interfaces.SetupMany(m.repo, backend, snaps, func(snapName string) interfaces.ConfinementOptions {
+			return confOpts[snapName]
+		}, tm)
+		for _, err := range errs {
+			logger.Noticef("cannot restore %s security profiles: %v", backend.Name(), err)
+		}
+		if len(errs) > 0 {
+			failed = append(failed, string(backend.Name()))
+		}
+	}
+	st.Lock()
+
+	if len(failed) > 0 {
+		task.Logf("cannot restore security profiles of backends: %s", strings.Join(failed, ", "))
+	}
+}
+
 func (m *InterfaceManager) setupSnapSecurity(task *state.Task, snapInfo *snap.Info, opts interfaces.ConfinementOptions, tm timings.Measurer) error {
 	return m.setupSecurityByBackend(task, []*snap.Info{snapInfo}, []interfaces.ConfinementOptions{opts}, tm)
 }
@@ -574,12 +770,6 @@ func (gc *gadgetConnect) addGadgetConnections(newconns map[string]*interfaces.Co
 				return err
 			}
 		}
-		plug := gc.repo.Plug(plugSnapName, gconn.Plug.Plug)
-		if plug == nil {
-			task.Logf("gadget connections: ignoring missing plug %s:%s", gconn.Plug.SnapID, gconn.Plug.Plug)
-			continue
-		}
-
 		if slotSnapName == "" {
 			var err error
 			slotSnapName, err = resolveSnapIDToName(gc.st, gconn.Slot.SnapID)
@@ -587,8 +777,22 @@ func (gc *gadgetConnect) addGadgetConnections(newconns map[string]*interfaces.Co
 				return err
 			}
 		}
+		plug := gc.repo.Plug(plugSnapName, gconn.Plug.Plug)
 		slot := gc.repo.Slot(slotSnapName, gconn.Slot.Slot)
+
+		if plug == nil {
+			if slot != nil {
+				if err := gc.addPendingConnection(plugSnapName, gconn.Plug.Plug, slotSnapName, gconn.Slot.Slot, plugSnapName, slot.Interface); err != nil {
+					return err
+				}
+			}
+			task.Logf("gadget connections: ignoring missing plug %s:%s", gconn.Plug.SnapID, gconn.Plug.Plug)
+			continue
+		}
 		if slot == nil {
+			if err := gc.addPendingConnection(plugSnapName, gconn.Plug.Plug, slotSnapName, gconn.Slot.Slot, slotSnapName, plug.Interface); err != nil {
+				return err
+			}
 			task.Logf("gadget connections: ignoring missing slot %s:%s", gconn.Slot.SnapID, gconn.Slot.Slot)
 			continue
 		}
@@ -832,6 +1036,53 @@ func newConnectChecker(s *state.State, deviceCtx snapstate.DeviceContext) (*conn
 }
 
 func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) (bool, error) {
+	connRef := &interfaces.ConnRef{PlugRef: *plug.Ref(), SlotRef: *slot.Ref()}
+	if err := checkConnectionNotForbidden(c.st, c.deviceCtx.Model(), connRef); err != nil {
+		return false, err
+	}
+	ic, err := c.candidate(plug, slot)
+	if err != nil {
+		return false, err
+	}
+	// if either of plug or slot snaps don't have a declaration it
+	// means they were installed with "dangerous", so the security
+	// check should be skipped at this point.
+	if ic.PlugSnapDeclaration != nil && ic.SlotSnapDeclaration != nil {
+		if err := ic.Check(); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// checkConnectionNotForbidden checks that none of the connection-profile
+// assertions of the model of the device forbids the given connection.
+func checkConnectionNotForbidden(st *state.State, modelAs *asserts.Model, connRef *interfaces.ConnRef) error {
+	profs, err := assertstate.DB(st).FindMany(asserts.ConnectionProfileType, map[string]string{
+		"brand-id": modelAs.BrandID(),
+		"model":    modelAs.Model(),
+	})
+	if asserts.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	id := connRef.ID()
+	for _, a := range profs {
+		prof := a.(*asserts.ConnectionProfile)
+		for _, entry := range prof.Forbid() {
+			if entry.ID() == id {
+				return fmt.Errorf("connection forbidden by connection profile %q", prof.Name())
+			}
+		}
+	}
+	return nil
+}
+
+// candidate gathers the assertions needed to check the connection of the
+// given plug and slot against the declarations' rules.
+func (c *connectChecker) candidate(plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) (*policy.ConnectCandidate, error) {
 	modelAs := c.deviceCtx.Model()
 
 	var storeAs *asserts.Store
@@ -839,7 +1090,7 @@ func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.
 		var err error
 		storeAs, err = assertstate.Store(c.st, modelAs.Store())
 		if err != nil && !asserts.IsNotFound(err) {
-			return false, err
+			return nil, err
 		}
 	}
 
@@ -848,7 +1099,7 @@ func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.
 		var err error
 		plugDecl, err = assertstate.SnapDeclaration(c.st, plug.Snap().SnapID)
 		if err != nil {
-			return false, fmt.Errorf("cannot find snap declaration for %q: %v", plug.Snap().InstanceName(), err)
+			return nil, fmt.Errorf("cannot find snap declaration for %q: %v", plug.Snap().InstanceName(), err)
 		}
 	}
 
@@ -857,12 +1108,11 @@ func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.
 		var err error
 		slotDecl, err = assertstate.SnapDeclaration(c.st, slot.Snap().SnapID)
 		if err != nil {
-			return false, fmt.Errorf("cannot find snap declaration for %q: %v", slot.Snap().InstanceName(), err)
+			return nil, fmt.Errorf("cannot find snap declaration for %q: %v", slot.Snap().InstanceName(), err)
 		}
 	}
 
-	// check the connection against the declarations' rules
-	ic := policy.ConnectCandidate{
+	return &policy.ConnectCandidate{
 		Plug:                plug,
 		PlugSnapDeclaration: plugDecl,
 		Slot:                slot,
@@ -870,17 +1120,7 @@ func (c *connectChecker) check(plug *interfaces.ConnectedPlug, slot *interfaces.
 		BaseDeclaration:     c.baseDecl,
 		Model:               modelAs,
 		Store:               storeAs,
-	}
-
-	// if either of plug or slot snaps don't have a declaration it
-	// means they were installed with "dangerous", so the security
-	// check should be skipped at this point.
-	if plugDecl != nil && slotDecl != nil {
-		if err := ic.Check(); err != nil {
-			return false, err
-		}
-	}
-	return true, nil
+	}, nil
 }
 
 func getPlugAndSlotRefs(task *state.Task) (interfaces.PlugRef, interfaces.SlotRef, error) {
@@ -948,6 +1188,240 @@ func setConns(st *state.State, conns map[string]*connState) {
 	st.Set("conns", remapped)
 }
 
+// addPendingConnection records the given gadget connection as pending when
+// the side provided by missingSnapName may still show up, that is when the
+// snap is not installed yet or when the side is a hotplug slot of the system
+// snap. The connection is then established once both sides are available.
+func (gc *gadgetConnect) addPendingConnection(plugSnapName, plugName, slotSnapName, slotName, missingSnapName, ifaceName string) error {
+	if missingSnapName == "" || missingSnapName == gc.instanceName {
+		return nil
+	}
+	// only slots of the system snap are hotplugged
+	if missingSnapName != SystemSnapName() || missingSnapName == plugSnapName {
+		var snapst snapstate.SnapState
+		err := snapstate.Get(gc.st, missingSnapName, &snapst)
+		if err != nil && err != state.ErrNoState {
+			return err
+		}
+		if err == nil && snapst.IsInstalled() {
+			return nil
+		}
+	}
+
+	pending, err := getPendingConns(gc.st)
+	if err != nil {
+		return err
+	}
+	connRef := &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: plugSnapName, Name: plugName},
+		SlotRef: interfaces.SlotRef{Snap: slotSnapName, Name: slotName},
+	}
+	pending[connRef.ID()] = ifaceName
+	setPendingConns(gc.st, pending)
+	gc.task.Logf("gadget connections: connection %s is pending", connRef.ID())
+	return nil
+}
+
+// getRetainedConns returns the manual connections of removed snaps that are
+// retained to be restored when the snaps are installed again. The result maps
+// connection IDs to the name of the connected interface.
+//
+// Connections are transparently re-mapped according to remapIncomingConnRef
+func getRetainedConns(st *state.State) (map[string]string, error) {
+	return getConnIntents(st, "retained-conns", "retained")
+}
+
+// setRetainedConns sets the retained connections in the state.
+//
+// Connections are transparently re-mapped according to remapOutgoingConnRef
+func setRetainedConns(st *state.State, retained map[string]string) {
+	setConnIntents(st, "retained-conns", retained)
+}
+
+// getPendingConns returns the pending connections recorded with
+// ConnectPending, that are established once both their plug and slot are
+// available. The result maps connection IDs to the name of the interface.
+//
+// Connections are transparently re-mapped according to remapIncomingConnRef
+func getPendingConns(st *state.State) (map[string]string, error) {
+	return getConnIntents(st, "pending-conns", "pending")
+}
+
+// setPendingConns sets the pending connections in the state.
+//
+// Connections are transparently re-mapped according to remapOutgoingConnRef
+func setPendingConns(st *state.State, pending map[string]string) {
+	setConnIntents(st, "pending-conns", pending)
+}
+
+func getConnIntents(st *state.State, key, what string) (map[string]string, error) {
+	var intents map[string]string
+	err := st.Get(key, &intents)
+	if err != nil && err != state.ErrNoState {
+		return nil, fmt.Errorf("cannot obtain data about %s connections: %s", what, err)
+	}
+	remapped := make(map[string]string, len(intents))
+	for id, ifaceName := range intents {
+		cref, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return nil, err
+		}
+		cref.PlugRef.Snap = RemapSnapFromState(cref.PlugRef.Snap)
+		cref.SlotRef.Snap = RemapSnapFromState(cref.SlotRef.Snap)
+		remapped[cref.ID()] = ifaceName
+	}
+	return remapped, nil
+}
+
+func setConnIntents(st *state.State, key string, intents map[string]string) {
+	if len(intents) == 0 {
+		st.Set(key, nil)
+		return
+	}
+	remapped := make(map[string]string, len(intents))
+	for id, ifaceName := range intents {
+		cref, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			// We cannot fail here
+			panic(err)
+		}
+		cref.PlugRef.Snap = RemapSnapToState(cref.PlugRef.Snap)
+		cref.SlotRef.Snap = RemapSnapToState(cref.SlotRef.Snap)
+		remapped[cref.ID()] = ifaceName
+	}
+	st.Set(key, remapped)
+}
+
+// forgetRetainedConns removes the retained connections of the given snap.
+// Pending connections are intents that do not depend on the snap having been
+// installed before, they are kept.
+func forgetRetainedConns(st *state.State, snapName string) error {
+	retained, err := getRetainedConns(st)
+	if err != nil {
+		return err
+	}
+	for id := range retained {
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return err
+		}
+		if connRef.PlugRef.Snap == snapName || connRef.SlotRef.Snap == snapName {
+			delete(retained, id)
+		}
+	}
+	setRetainedConns(st, retained)
+	return nil
+}
+
+// addRetainedConnections adds to newconns the manual connections of the
+// given snap that were retained when it was removed or recorded as pending.
+// A retained connection is restored when both its plug and slot are present
+// again, of the same interface, and allowed by the policy. If slotName is
+// not empty only the connections of that slot of the snap are considered.
+// Retained connections that were considered are returned, the caller removes
+// them from the state with removeRetainedConns once it cannot be retried
+// anymore.
+// conflictError is called to handle checkAutoconnectConflicts errors.
+func addRetainedConnections(st *state.State, task *state.Task, repo *interfaces.Repository, snapName, slotName string, deviceCtx snapstate.DeviceContext, newconns map[string]*interfaces.ConnRef, conns map[string]*connState, conflictError func(*state.Retry, error) error) (considered map[string]string, err error) {
+	retained, err := getRetainedConns(st)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := getPendingConns(st)
+	if err != nil {
+		return nil, err
+	}
+	intents := make(map[string]string, len(retained)+len(pending))
+	for id, ifaceName := range pending {
+		intents[id] = ifaceName
+	}
+	for id, ifaceName := range retained {
+		intents[id] = ifaceName
+	}
+
+	var checker *connectChecker
+	considered = make(map[string]string)
+	for id, ifaceName := range intents {
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return nil, err
+		}
+		if connRef.PlugRef.Snap != snapName && connRef.SlotRef.Snap != snapName {
+			continue
+		}
+		if slotName != "" && (connRef.SlotRef.Snap != snapName || connRef.SlotRef.Name != slotName) {
+			continue
+		}
+
+		plug := repo.Plug(connRef.PlugRef.Snap, connRef.PlugRef.Name)
+		slot := repo.Slot(connRef.SlotRef.Snap, connRef.SlotRef.Name)
+		// slots of the system snap may be hotplug slots whose device
+		// is not present yet
+		hotplugCandidate := connRef.SlotRef.Snap == SystemSnapName()
+		if (connRef.PlugRef.Snap == snapName && plug == nil) || (connRef.SlotRef.Snap == snapName && slot == nil && !hotplugCandidate) {
+			task.Logf("cannot restore connection %s: no longer provided by snap %q", id, snapName)
+			considered[id] = ifaceName
+			continue
+		}
+		if plug == nil || slot == nil {
+			// the other snap is not installed (anymore), keep the
+			// connection in case it is installed again
+			continue
+		}
+		considered[id] = ifaceName
+		if plug.Interface != ifaceName || slot.Interface != ifaceName {
+			task.Logf("cannot restore connection %s: interface is no longer %q", id, ifaceName)
+			continue
+		}
+
+		if checker == nil {
+			checker, err = newConnectChecker(st, deviceCtx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if ok, err := checker.check(interfaces.NewConnectedPlug(plug, nil, nil), interfaces.NewConnectedSlot(slot, nil, nil)); !ok {
+			task.Logf("cannot restore connection %s: %v", id, err)
+			continue
+		}
+
+		if err := addNewConnection(st, task, newconns, conns, plug, slot, conflictError); err != nil {
+			return nil, err
+		}
+	}
+
+	return considered, nil
+}
+
+// removeRetainedConns removes the given connections from the retained and
+// the pending connections. The ones that were pending are returned.
+func removeRetainedConns(st *state.State, ids map[string]string) (wasPending map[string]string, err error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	retained, err := getRetainedConns(st)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := getPendingConns(st)
+	if err != nil {
+		return nil, err
+	}
+	for id := range ids {
+		if ifaceName, ok := pending[id]; ok {
+			if wasPending == nil {
+				wasPending = make(map[string]string)
+			}
+			wasPending[id] = ifaceName
+			delete(pending, id)
+		}
+		delete(retained, id)
+	}
+	setRetainedConns(st, retained)
+	setPendingConns(st, pending)
+	return wasPending, nil
+}
+
 // snapsWithSecurityProfiles returns all snaps that have active
 // security profiles: these are either snaps that are active, or about
 // to be active (pending link-snap) with a done setup-profiles
@@ -37,25 +37,31 @@ import (
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/timings"
 )
 
 type helpersSuite struct {
+	testutil.BaseTest
 	st *state.State
 }
 
 var _ = Suite(&helpersSuite{})
 
 func (s *helpersSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
 	s.st = state.New(nil)
 	dirs.SetRootDir(c.MkDir())
+	s.AddCleanup(snapstatetest.MockDeviceModel(nil))
 }
 
 func (s *helpersSuite) TearDownTest(c *C) {
 	dirs.SetRootDir("")
+	s.BaseTest.TearDownTest(c)
 }
 
 func (s *helpersSuite) TestIdentityMapper(c *C) {
@@ -0,0 +1,272 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// connsHistoryLimit is the number of snapshots of the connection set kept
+// in the state.
+const connsHistoryLimit = 16
+
+var timeNow = time.Now
+
+// ErrNothingToRevert is returned by RevertConnections when the connections
+// are already as they were at the requested point in time.
+var ErrNothingToRevert = errors.New("connections are already in the requested state")
+
+type historyConn struct {
+	Interface string `json:"interface"`
+	Auto      bool   `json:"auto,omitempty"`
+	ByGadget  bool   `json:"by-gadget,omitempty"`
+}
+
+// connsSnapshot is the set of active connections as it was before a change
+// first modified it.
+type connsSnapshot struct {
+	Time     time.Time              `json:"time"`
+	ChangeID string                 `json:"change-id"`
+	Conns    map[string]historyConn `json:"conns"`
+}
+
+// ConnectionsSnapshot describes the set of active connections as it was
+// before a change first modified it.
+type ConnectionsSnapshot struct {
+	// Time is when the change first modified the connections.
+	Time time.Time `json:"time"`
+	// ChangeID is the ID of the change.
+	ChangeID string `json:"change-id"`
+	// Connections are the sorted IDs of the connections.
+	Connections []string `json:"connections"`
+}
+
+func remapHistoryConns(conns map[string]historyConn, remap func(string) string) map[string]historyConn {
+	remapped := make(map[string]historyConn, len(conns))
+	for id, conn := range conns {
+		cref, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			// We cannot fail here
+			panic(err)
+		}
+		cref.PlugRef.Snap = remap(cref.PlugRef.Snap)
+		cref.SlotRef.Snap = remap(cref.SlotRef.Snap)
+		remapped[cref.ID()] = conn
+	}
+	return remapped
+}
+
+func getConnsHistory(st *state.State) ([]*connsSnapshot, error) {
+	var history []*connsSnapshot
+	if err := st.Get("conns-history", &history); err != nil && err != state.ErrNoState {
+		return nil, fmt.Errorf("cannot obtain the history of connections: %s", err)
+	}
+	for _, snapshot := range history {
+		snapshot.Conns = remapHistoryConns(snapshot.Conns, RemapSnapFromState)
+	}
+	return history, nil
+}
+
+func setConnsHistory(st *state.State, history []*connsSnapshot) {
+	remapped := make([]*connsSnapshot, len(history))
+	for i, snapshot := range history {
+		remapped[i] = &connsSnapshot{
+			Time:     snapshot.Time,
+			ChangeID: snapshot.ChangeID,
+			Conns:    remapHistoryConns(snapshot.Conns, RemapSnapToState),
+		}
+	}
+	st.Set("conns-history", remapped)
+}
+
+// recordConnsHistory adds a snapshot of the given connections to the history
+// unless the change of the task already recorded one. It must be called
+// before the task modifies the connections.
+func recordConnsHistory(task *state.Task, conns map[string]*connState) error {
+	chg := task.Change()
+	if chg == nil {
+		return nil
+	}
+	st := task.State()
+	history, err := getConnsHistory(st)
+	if err != nil {
+		return err
+	}
+	if len(history) > 0 && history[len(history)-1].ChangeID == chg.ID() {
+		return nil
+	}
+
+	snapshot := &connsSnapshot{
+		Time:     timeNow(),
+		ChangeID: chg.ID(),
+		Conns:    make(map[string]historyConn, len(conns)),
+	}
+	for id, conn := range conns {
+		if conn.Undesired || conn.HotplugGone {
+			continue
+		}
+		snapshot.Conns[id] = historyConn{Interface: conn.Interface, Auto: conn.Auto, ByGadget: conn.ByGadget}
+	}
+	history = append(history, snapshot)
+	if len(history) > connsHistoryLimit {
+		history = history[len(history)-connsHistoryLimit:]
+	}
+	setConnsHistory(st, history)
+	return nil
+}
+
+// ConnectionsHistory returns the recorded snapshots of the set of active
+// connections, oldest first.
+func ConnectionsHistory(st *state.State) ([]ConnectionsSnapshot, error) {
+	history, err := getConnsHistory(st)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ConnectionsSnapshot, len(history))
+	for i, snapshot := range history {
+		ids := make([]string, 0, len(snapshot.Conns))
+		for id := range snapshot.Conns {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		result[i] = ConnectionsSnapshot{
+			Time:        snapshot.Time,
+			ChangeID:    snapshot.ChangeID,
+			Connections: ids,
+		}
+	}
+	return result, nil
+}
+
+// RevertConnections returns a set of tasks restoring the connections to what
+// they were at the given time, or before the last change that modified them
+// if the time is zero. Connections whose plug or slot no longer exists are
+// not restored.
+func RevertConnections(st *state.State, repo *interfaces.Repository, at time.Time) (*state.TaskSet, error) {
+	history, err := getConnsHistory(st)
+	if err != nil {
+		return nil, err
+	}
+	var target *connsSnapshot
+	if at.IsZero() {
+		if len(history) > 0 {
+			target = history[len(history)-1]
+		}
+	} else {
+		// the connections at the given time are the ones recorded
+		// before the first change after that time
+		for _, snapshot := range history {
+			if snapshot.Time.After(at) {
+				target = snapshot
+				break
+			}
+		}
+	}
+	if target == nil {
+		return nil, ErrNothingToRevert
+	}
+
+	conns, err := getConns(st)
+	if err != nil {
+		return nil, err
+	}
+
+	var toDisconnect, toConnect []*interfaces.ConnRef
+	for id, conn := range conns {
+		if conn.Undesired || conn.HotplugGone {
+			continue
+		}
+		if _, ok := target.Conns[id]; ok {
+			continue
+		}
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return nil, err
+		}
+		toDisconnect = append(toDisconnect, connRef)
+	}
+	for id, old := range target.Conns {
+		if conn, ok := conns[id]; ok && !conn.Undesired && !conn.HotplugGone {
+			continue
+		}
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return nil, err
+		}
+		plug := repo.Plug(connRef.PlugRef.Snap, connRef.PlugRef.Name)
+		slot := repo.Slot(connRef.SlotRef.Snap, connRef.SlotRef.Name)
+		if plug == nil || slot == nil || plug.Interface != old.Interface || slot.Interface != old.Interface {
+			continue
+		}
+		toConnect = append(toConnect, connRef)
+	}
+	if len(toDisconnect) == 0 && len(toConnect) == 0 {
+		return nil, ErrNothingToRevert
+	}
+	sort.Sort(byConnRefID(toDisconnect))
+	sort.Sort(byConnRefID(toConnect))
+
+	var snapNames []string
+	for _, connRef := range append(toDisconnect, toConnect...) {
+		snapNames = append(snapNames, connRef.PlugRef.Snap, connRef.SlotRef.Snap)
+	}
+	if err := snapstate.CheckChangeConflictMany(st, snapNames, ""); err != nil {
+		return nil, err
+	}
+
+	ts := state.NewTaskSet()
+	for _, connRef := range toDisconnect {
+		conn, err := repo.Connection(connRef)
+		if err != nil {
+			// the connection is in the state but not active, e.g.
+			// because its plug or slot is gone
+			forgetTs := forgetTasks(st, connRef)
+			ts.AddAll(forgetTs)
+			continue
+		}
+		disconnectTs, err := disconnectTasks(st, conn, disconnectOpts{})
+		if err != nil {
+			return nil, err
+		}
+		ts.AddAll(disconnectTs)
+	}
+	for _, connRef := range toConnect {
+		old := target.Conns[connRef.ID()]
+		connectTs, err := connect(st, connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name, connectOpts{AutoConnect: old.Auto, ByGadget: old.ByGadget})
+		if err != nil {
+			return nil, err
+		}
+		ts.AddAll(connectTs)
+	}
+	return ts, nil
+}
+
+type byConnRefID []*interfaces.ConnRef
+
+func (c byConnRefID) Len() int           { return len(c) }
+func (c byConnRefID) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c byConnRefID) Less(i, j int) bool { return c[i].ID() < c[j].ID() }
@@ -0,0 +1,218 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	"fmt"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+func (s *interfaceManagerSuite) mockConnsHistoryTime() time.Time {
+	now := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	s.AddCleanup(ifacestate.MockTimeNow(func() time.Time { return now }))
+	return now
+}
+
+func (s *interfaceManagerSuite) TestConnectionsHistoryRecordedOncePerChange(c *C) {
+	now := s.mockConnsHistoryTime()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	conns := ifacestate.UpperCaseConnState()
+	chg := s.state.NewChange("connect", "")
+	t1 := s.state.NewTask("connect", "")
+	t2 := s.state.NewTask("connect", "")
+	chg.AddTask(t1)
+	chg.AddTask(t2)
+	c.Assert(ifacestate.RecordConnsHistory(t1, conns), IsNil)
+	c.Assert(ifacestate.RecordConnsHistory(t2, nil), IsNil)
+
+	history, err := ifacestate.ConnectionsHistory(s.state)
+	c.Assert(err, IsNil)
+	c.Check(history, DeepEquals, []ifacestate.ConnectionsSnapshot{{
+		Time:        now,
+		ChangeID:    chg.ID(),
+		Connections: []string{"APP:network CORE:network"},
+	}})
+
+	// tasks without a change are not recorded
+	c.Assert(ifacestate.RecordConnsHistory(s.state.NewTask("connect", ""), nil), IsNil)
+	history, err = ifacestate.ConnectionsHistory(s.state)
+	c.Assert(err, IsNil)
+	c.Check(history, HasLen, 1)
+}
+
+func (s *interfaceManagerSuite) TestConnectionsHistoryIsBounded(c *C) {
+	s.mockConnsHistoryTime()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	var last *state.Change
+	for i := 0; i < ifacestate.ConnsHistoryLimit+3; i++ {
+		last = s.state.NewChange("connect", "")
+		t := s.state.NewTask("connect", "")
+		last.AddTask(t)
+		c.Assert(ifacestate.RecordConnsHistory(t, nil), IsNil)
+	}
+
+	history, err := ifacestate.ConnectionsHistory(s.state)
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, ifacestate.ConnsHistoryLimit)
+	c.Check(history[len(history)-1].ChangeID, Equals, last.ID())
+}
+
+func (s *interfaceManagerSuite) TestRevertConnectionsAfterDisconnect(c *C) {
+	s.mockConnsHistoryTime()
+	s.MockModel(c, nil)
+	s.testDisconnect(c, "consumer", "plug", "producer", "slot")
+
+	s.state.Lock()
+	history, err := ifacestate.ConnectionsHistory(s.state)
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 1)
+	c.Check(history[0].Connections, DeepEquals, []string{"consumer:plug producer:slot"})
+
+	repo := s.manager(c).Repository()
+	ts, err := ifacestate.RevertConnections(s.state, repo, time.Time{})
+	c.Assert(err, IsNil)
+	chg := s.state.NewChange("revert-connections", "")
+	chg.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(chg.Err(), IsNil)
+	c.Check(chg.Status(), Equals, state.DoneStatus)
+
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{
+			"interface":   "test",
+			"plug-static": map[string]interface{}{"attr1": "value1"},
+			"slot-static": map[string]interface{}{"attr2": "value2"},
+		},
+	})
+	_, err = repo.Connection(&interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	})
+	c.Check(err, IsNil)
+
+	// the revert is recorded as well
+	history, err = ifacestate.ConnectionsHistory(s.state)
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 2)
+	c.Check(history[1].ChangeID, Equals, chg.ID())
+	c.Check(history[1].Connections, HasLen, 0)
+}
+
+func connectionTasksSummary(c *C, ts *state.TaskSet) []string {
+	var summary []string
+	for _, t := range ts.Tasks() {
+		if t.Kind() != "connect" && t.Kind() != "disconnect" {
+			continue
+		}
+		var plugRef interfaces.PlugRef
+		var slotRef interfaces.SlotRef
+		c.Assert(t.Get("plug", &plugRef), IsNil)
+		c.Assert(t.Get("slot", &slotRef), IsNil)
+		var auto bool
+		if err := t.Get("auto", &auto); err != nil && err != state.ErrNoState {
+			c.Fatal(err)
+		}
+		summary = append(summary, fmt.Sprintf("%s %s %s auto:%v", t.Kind(), plugRef, slotRef, auto))
+	}
+	return summary
+}
+
+func (s *interfaceManagerSuite) TestRevertConnectionsAtTime(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	s.mockSnap(c, producer2Yaml)
+
+	t0 := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Set("conns-history", []interface{}{
+		map[string]interface{}{
+			"time":      t0,
+			"change-id": "1",
+			"conns": map[string]interface{}{
+				"consumer:plug producer2:slot": map[string]interface{}{"interface": "test", "auto": true},
+			},
+		},
+		map[string]interface{}{
+			"time":      t0.Add(time.Hour),
+			"change-id": "2",
+			"conns":     map[string]interface{}{},
+		},
+	})
+	s.state.Unlock()
+
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// before any recorded change
+	ts, err := ifacestate.RevertConnections(s.state, repo, t0.Add(-time.Minute))
+	c.Assert(err, IsNil)
+	c.Check(connectionTasksSummary(c, ts), DeepEquals, []string{
+		"disconnect consumer:plug producer:slot auto:false",
+		"connect consumer:plug producer2:slot auto:true",
+	})
+
+	// between the two changes, when nothing was connected
+	ts, err = ifacestate.RevertConnections(s.state, repo, t0.Add(time.Minute))
+	c.Assert(err, IsNil)
+	c.Check(connectionTasksSummary(c, ts), DeepEquals, []string{
+		"disconnect consumer:plug producer:slot auto:false",
+	})
+
+	// after the last change
+	_, err = ifacestate.RevertConnections(s.state, repo, t0.Add(2*time.Hour))
+	c.Check(err, Equals, ifacestate.ErrNothingToRevert)
+}
+
+func (s *interfaceManagerSuite) TestRevertConnectionsNoHistory(c *C) {
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := ifacestate.RevertConnections(s.state, repo, time.Time{})
+	c.Check(err, Equals, ifacestate.ErrNothingToRevert)
+}
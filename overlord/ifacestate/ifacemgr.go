@@ -59,6 +59,16 @@ type InterfaceManager struct {
 	// maps sysfs path -> [(interface name, device key)...]
 	hotplugDevicePaths map[string][]deviceData
 
+	// next time the connections are checked against the policy, and
+	// the revisions of the snap declarations used by the last check,
+	// indexed by snap ID
+	policyRecheckTime time.Time
+	declRevisions     map[string]int
+
+	// whether the interfaces not allowed by the model were left out
+	// of the repository, false until the model is known
+	interfacesFiltered bool
+
 	// extras
 	extraInterfaces []interfaces.Interface
 	extraBackends   []interfaces.SecurityBackend
@@ -100,6 +110,7 @@ func Manager(s *state.State, hookManager *hookstate.HookManager, runner *state.T
 	addHandler("setup-profiles", m.doSetupProfiles, m.undoSetupProfiles)
 	addHandler("remove-profiles", m.doRemoveProfiles, m.doSetupProfiles)
 	addHandler("discard-conns", m.doDiscardConns, m.undoDiscardConns)
+	addHandler("import-conns", m.doImportConns, m.undoImportConns)
 	addHandler("auto-connect", m.doAutoConnect, m.undoAutoConnect)
 	addHandler("auto-disconnect", m.doAutoDisconnect, nil)
 	addHandler("hotplug-add-slot", m.doHotplugAddSlot, nil)
@@ -140,6 +151,9 @@ func (m *InterfaceManager) StartUp() error {
 	s.Lock()
 	defer s.Unlock()
 
+	if err := m.applyAllowedReservedNames(); err != nil {
+		return err
+	}
 	snaps, err := snapsWithSecurityProfiles(m.state)
 	if err != nil {
 		return err
@@ -166,7 +180,10 @@ func (m *InterfaceManager) StartUp() error {
 	if err := removeStaleConnections(m.state); err != nil {
 		return err
 	}
-	if _, err := m.reloadConnections(""); err != nil {
+	if _, _, err := m.reloadConnections(""); err != nil {
+		return err
+	}
+	if err := m.removeOrphanedProfiles(); err != nil {
 		return err
 	}
 	if profilesNeedRegeneration() {
@@ -187,11 +204,19 @@ func (m *InterfaceManager) StartUp() error {
 
 // Ensure implements StateManager.Ensure.
 func (m *InterfaceManager) Ensure() error {
+	if err := m.filterInterfacesByModel(); err != nil {
+		return err
+	}
+
 	// do not worry about udev monitor in preseeding mode
 	if m.preseed {
 		return nil
 	}
 
+	if err := m.reevaluateConnectionsPolicy(); err != nil {
+		logger.Noticef("cannot check connections against the policy: %v", err)
+	}
+
 	if m.udevMonitorDisabled {
 		return nil
 	}
@@ -437,6 +462,22 @@ func (m *InterfaceManager) DisableUDevMonitor() {
 	m.udevMonitorDisabled = true
 }
 
+// HotplugMonitorStatus returns the status of the udev monitor used for
+// hotplug support, one of "disabled", "running" or "not-running". The monitor
+// is not running until a system snap is installed or after it failed to
+// initialize, in which case initialization is retried periodically.
+func (m *InterfaceManager) HotplugMonitorStatus() string {
+	if m.preseed || m.udevMonitorDisabled {
+		return "disabled"
+	}
+	m.udevMonMu.Lock()
+	defer m.udevMonMu.Unlock()
+	if m.udevMon != nil {
+		return "running"
+	}
+	return "not-running"
+}
+
 var (
 	udevInitRetryTimeout = time.Minute * 5
 	createUDevMonitor    = udevmonitor.New
@@ -39,3 +39,9 @@ func Get(st *state.State) *interfaces.Repository {
 	}
 	return repo.(*interfaces.Repository)
 }
+
+// Available returns whether the interface repository used by the managers
+// was set up.
+func Available(st *state.State) bool {
+	return st.Cached(interfacesRepoKey{}) != nil
+}
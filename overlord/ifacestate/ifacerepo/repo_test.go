@@ -54,6 +54,16 @@ func (s *ifaceRepoSuite) TestHappy(c *C) {
 	c.Check(s.repo, DeepEquals, repo)
 }
 
+func (s *ifaceRepoSuite) TestAvailable(c *C) {
+	st := s.o.State()
+	st.Lock()
+	defer st.Unlock()
+
+	c.Check(ifacerepo.Available(st), Equals, false)
+	ifacerepo.Replace(st, s.repo)
+	c.Check(ifacerepo.Available(st), Equals, true)
+}
+
 func (s *ifaceRepoSuite) TestGetPanics(c *C) {
 	st := s.o.State()
 	st.Lock()
@@ -29,6 +29,7 @@ import (
 	"github.com/snapcore/snapd/asserts"
 	"github.com/snapcore/snapd/i18n"
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/builtin"
 	"github.com/snapcore/snapd/interfaces/policy"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/hookstate"
@@ -53,6 +54,17 @@ func (e ErrAlreadyConnected) Error() string {
 	return fmt.Sprintf("already connected: %q", e.Connection.ID())
 }
 
+// ErrConnectNotAllowed describes the error that occurs when the policy
+// rules of the snap declarations do not allow a connection.
+type ErrConnectNotAllowed struct {
+	Connection interfaces.ConnRef
+	Err        error
+}
+
+func (e *ErrConnectNotAllowed) Error() string {
+	return fmt.Sprintf("cannot connect %s: %v", e.Connection.ID(), e.Err)
+}
+
 // findSymmetricAutoconnectTask checks if there is another auto-connect task affecting same snap because of plug/slot.
 func findSymmetricAutoconnectTask(st *state.State, plugSnap, slotSnap string, installTask *state.Task) (bool, error) {
 	snapsup, err := snapstate.TaskSnapSetup(installTask)
@@ -96,6 +108,79 @@ func Connect(st *state.State, plugSnap, plugName, slotSnap, slotName string) (*s
 	return connect(st, plugSnap, plugName, slotSnap, slotName, connectOpts{})
 }
 
+// CheckConnectPolicy checks whether the policy rules and the
+// connection-profile assertions of the model allow a manual connection
+// of the given plug and slot, so that a denial can be reported
+// before any change is made. The check uses the static attributes of the
+// plug and slot, the connect task checks the policy again once the interface
+// hooks provided their dynamic attributes.
+func CheckConnectPolicy(st *state.State, repo *interfaces.Repository, connRef *interfaces.ConnRef) error {
+	plug := repo.Plug(connRef.PlugRef.Snap, connRef.PlugRef.Name)
+	if plug == nil {
+		return fmt.Errorf("snap %q has no %q plug", connRef.PlugRef.Snap, connRef.PlugRef.Name)
+	}
+	slot := repo.Slot(connRef.SlotRef.Snap, connRef.SlotRef.Name)
+	if slot == nil {
+		return fmt.Errorf("snap %q has no %q slot", connRef.SlotRef.Snap, connRef.SlotRef.Name)
+	}
+
+	deviceCtx, err := snapstate.DeviceCtx(st, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := checkConnectionNotForbidden(st, deviceCtx.Model(), connRef); err != nil {
+		return &ErrConnectNotAllowed{Connection: *connRef, Err: err}
+	}
+	// if either of plug or slot snaps don't have a declaration it
+	// means they were installed with "dangerous", so there is no
+	// further policy to check
+	if plug.Snap.SnapID == "" || slot.Snap.SnapID == "" {
+		return nil
+	}
+
+	checker, err := newConnectChecker(st, deviceCtx)
+	if err != nil {
+		return err
+	}
+	ic, err := checker.candidate(interfaces.NewConnectedPlug(plug, nil, nil), interfaces.NewConnectedSlot(slot, nil, nil))
+	if err != nil {
+		return err
+	}
+	if err := ic.Check(); err != nil {
+		return &ErrConnectNotAllowed{Connection: *connRef, Err: err}
+	}
+	return nil
+}
+
+// ConnectPending records the intent to connect the given plug and slot of
+// the given interface once both of them are available, that is when the
+// missing snap is installed or when the hotplug device providing the slot
+// shows up. The connection is then established as a manual connection.
+func ConnectPending(st *state.State, repo *interfaces.Repository, connRef *interfaces.ConnRef, ifaceName string) error {
+	if repo.Interface(ifaceName) == nil {
+		return fmt.Errorf("unknown interface %q", ifaceName)
+	}
+	plug := repo.Plug(connRef.PlugRef.Snap, connRef.PlugRef.Name)
+	if plug != nil && plug.Interface != ifaceName {
+		return fmt.Errorf("plug %s is not of interface %q", connRef.PlugRef, ifaceName)
+	}
+	slot := repo.Slot(connRef.SlotRef.Snap, connRef.SlotRef.Name)
+	if slot != nil && slot.Interface != ifaceName {
+		return fmt.Errorf("slot %s is not of interface %q", connRef.SlotRef, ifaceName)
+	}
+	if plug != nil && slot != nil {
+		return fmt.Errorf("cannot record pending connection %s: plug and slot are already available", connRef.ID())
+	}
+
+	pending, err := getPendingConns(st)
+	if err != nil {
+		return err
+	}
+	pending[connRef.ID()] = ifaceName
+	setPendingConns(st, pending)
+	return nil
+}
+
 func connect(st *state.State, plugSnap, plugName, slotSnap, slotName string, flags connectOpts) (*state.TaskSet, error) {
 	// TODO: Store the intent-to-connect in the state so that we automatically
 	// try to reconnect on reboot (reconnection can fail or can connect with
@@ -348,6 +433,10 @@ type disconnectOpts struct {
 	AutoDisconnect bool
 	ByHotplug      bool
 	Forget         bool
+	// RetainForReinstall keeps a manual connection severed by the
+	// removal of a snap so that it is restored when the snap is
+	// installed again.
+	RetainForReinstall bool
 }
 
 // forgetTasks creates a set of tasks for forgetting an inactive connection
@@ -397,6 +486,9 @@ func disconnectTasks(st *state.State, conn *interfaces.Connection, flags disconn
 	if flags.ByHotplug {
 		disconnectTask.Set("by-hotplug", true)
 	}
+	if flags.RetainForReinstall {
+		disconnectTask.Set("retain-for-reinstall", true)
+	}
 
 	ts := state.NewTaskSet()
 	var prev *state.Task
@@ -472,15 +564,39 @@ func disconnectTasks(st *state.State, conn *interfaces.Connection, flags disconn
 	return ts, nil
 }
 
+// checkModelAllowsInterfaces checks that the plugs and slots declared by
+// the snap only use interfaces permitted by the model.
+func checkModelAllowsInterfaces(modelAs *asserts.Model, snapInfo *snap.Info) error {
+	for _, plug := range snapInfo.Plugs {
+		if !modelAs.InterfaceAllowed(plug.Interface) {
+			return fmt.Errorf("cannot use plug %q of snap %q: interface %q is not allowed on this model", plug.Name, snapInfo.InstanceName(), plug.Interface)
+		}
+	}
+	for _, slot := range snapInfo.Slots {
+		if !modelAs.InterfaceAllowed(slot.Interface) {
+			return fmt.Errorf("cannot use slot %q of snap %q: interface %q is not allowed on this model", slot.Name, snapInfo.InstanceName(), slot.Interface)
+		}
+	}
+	return nil
+}
+
 // CheckInterfaces checks whether plugs and slots of snap are allowed for installation.
 func CheckInterfaces(st *state.State, snapInfo *snap.Info, deviceCtx snapstate.DeviceContext) error {
+	modelAs := deviceCtx.Model()
+
+	if err := builtin.CheckReservedNames(snapInfo); err != nil {
+		return err
+	}
+
+	if err := checkModelAllowsInterfaces(modelAs, snapInfo); err != nil {
+		return err
+	}
+
 	// XXX: addImplicitSlots is really a brittle interface
 	if err := addImplicitSlots(st, snapInfo); err != nil {
 		return err
 	}
 
-	modelAs := deviceCtx.Model()
-
 	var storeAs *asserts.Store
 	if modelAs.Store() != "" {
 		var err error
@@ -37,12 +37,14 @@ import (
 	"github.com/snapcore/snapd/asserts/assertstest"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/builtin"
 	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/ifacetest"
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
@@ -117,6 +119,25 @@ func (am *AssertsMock) TrivialDeviceContext(c *C, extraHeaders map[string]interf
 	return &snapstatetest.TrivialDeviceContext{DeviceModel: model}
 }
 
+// MockConnectionProfile adds a connection-profile assertion for the mocked
+// model, signed by its brand, that forbids the given connections.
+func (am *AssertsMock) MockConnectionProfile(c *C, forbid []interface{}) {
+	brandPrivKey, _ := assertstest.GenerateKey(752)
+	brands := assertstest.NewSigningAccounts(am.storeSigning)
+	brands.Register("my-brand", brandPrivKey, nil)
+	assertstest.AddMany(am.Db, brands.AccountsAndKeys("my-brand")...)
+
+	prof, err := brands.Signing("my-brand").Sign(asserts.ConnectionProfileType, map[string]interface{}{
+		"brand-id":  "my-brand",
+		"model":     "my-model",
+		"name":      "default",
+		"forbid":    forbid,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	c.Assert(am.Db.Add(prof), IsNil)
+}
+
 func (am *AssertsMock) MockSnapDecl(c *C, name, publisher string, extraHeaders map[string]interface{}) {
 	_, err := am.Db.Find(asserts.AccountType, map[string]string{
 		"account-id": publisher,
@@ -227,6 +248,9 @@ func (s *interfaceManagerSuite) SetUpTest(c *C) {
 	// needed for system key generation
 	s.AddCleanup(osutil.MockMountInfo(""))
 
+	// no model by default, tests that need one use MockModel
+	s.AddCleanup(snapstatetest.MockDeviceModel(nil))
+
 	s.o = overlord.Mock()
 	s.state = s.o.State()
 	s.se = s.o.StateEngine()
@@ -1385,6 +1409,115 @@ func (s *interfaceManagerSuite) TestConnectTaskCheckAllowed(c *C) {
 	})
 }
 
+func (s *interfaceManagerSuite) TestConnectTaskCheckForbiddenByProfile(c *C) {
+	s.MockModel(c, nil)
+	s.MockConnectionProfile(c, []interface{}{
+		map[string]interface{}{"plug": "consumer:plug", "slot": "producer:slot", "interface": "test"},
+	})
+
+	s.testConnectTaskCheck(c, func() {
+		s.mockSnap(c, consumerYaml)
+		s.mockSnap(c, producerYaml)
+	}, func(change *state.Change) {
+		c.Check(change.Err(), ErrorMatches, `(?s).*connection forbidden by connection profile "default".*`)
+		c.Check(change.Status(), Equals, state.ErrorStatus)
+
+		repo := s.manager(c).Repository()
+		ifaces := repo.Interfaces()
+		c.Check(ifaces.Connections, HasLen, 0)
+	})
+}
+
+func (s *interfaceManagerSuite) testCheckConnectPolicy(c *C, setup func()) error {
+	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-connection:
+      plug-publisher-id:
+        - $SLOT_PUBLISHER_ID
+`))
+	defer restore()
+	s.MockModel(c, nil)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+
+	setup()
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	return ifacestate.CheckConnectPolicy(s.state, repo, &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	})
+}
+
+func (s *interfaceManagerSuite) TestCheckConnectPolicyNotAllowed(c *C) {
+	err := s.testCheckConnectPolicy(c, func() {
+		s.MockSnapDecl(c, "consumer", "consumer-publisher", nil)
+		s.mockSnap(c, consumerYaml)
+		s.MockSnapDecl(c, "producer", "producer-publisher", nil)
+		s.mockSnap(c, producerYaml)
+	})
+	c.Assert(err, ErrorMatches, `cannot connect consumer:plug producer:slot: connection not allowed by slot rule of interface "test"`)
+	notAllowed, ok := err.(*ifacestate.ErrConnectNotAllowed)
+	c.Assert(ok, Equals, true)
+	c.Check(notAllowed.Connection, DeepEquals, interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	})
+}
+
+func (s *interfaceManagerSuite) TestCheckConnectPolicyAllowed(c *C) {
+	err := s.testCheckConnectPolicy(c, func() {
+		s.MockSnapDecl(c, "consumer", "one-publisher", nil)
+		s.mockSnap(c, consumerYaml)
+		s.MockSnapDecl(c, "producer", "one-publisher", nil)
+		s.mockSnap(c, producerYaml)
+	})
+	c.Check(err, IsNil)
+}
+
+func (s *interfaceManagerSuite) TestCheckConnectPolicyNoDecl(c *C) {
+	err := s.testCheckConnectPolicy(c, func() {
+		s.mockSnap(c, consumerYaml)
+		s.mockSnap(c, producerYaml)
+	})
+	c.Check(err, IsNil)
+}
+
+func (s *interfaceManagerSuite) TestCheckConnectPolicyForbiddenByProfile(c *C) {
+	err := s.testCheckConnectPolicy(c, func() {
+		s.MockConnectionProfile(c, []interface{}{
+			map[string]interface{}{"plug": "consumer:plug", "slot": "producer:slot", "interface": "test"},
+		})
+		s.mockSnap(c, consumerYaml)
+		s.mockSnap(c, producerYaml)
+	})
+	c.Assert(err, ErrorMatches, `cannot connect consumer:plug producer:slot: connection forbidden by connection profile "default"`)
+	c.Check(err, FitsTypeOf, &ifacestate.ErrConnectNotAllowed{})
+}
+
+func (s *interfaceManagerSuite) TestCheckConnectPolicyNotForbiddenByProfile(c *C) {
+	err := s.testCheckConnectPolicy(c, func() {
+		s.MockConnectionProfile(c, []interface{}{
+			map[string]interface{}{"plug": "consumer:otherplug", "slot": "producer:slot", "interface": "test"},
+		})
+		s.mockSnap(c, consumerYaml)
+		s.mockSnap(c, producerYaml)
+	})
+	c.Check(err, IsNil)
+}
+
+func (s *interfaceManagerSuite) TestCheckConnectPolicyNoSuchPlug(c *C) {
+	err := s.testCheckConnectPolicy(c, func() {
+		s.mockSnap(c, producerYaml)
+	})
+	c.Check(err, ErrorMatches, `snap "consumer" has no "plug" plug`)
+}
+
 func (s *interfaceManagerSuite) testConnectTaskCheck(c *C, setup func(), check func(*state.Change)) {
 	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
 type: base-declaration
@@ -1957,6 +2090,83 @@ func (s *interfaceManagerSuite) TestStaleConnectionsRemoved(c *C) {
 	c.Assert(ifaces.Connections, HasLen, 0)
 }
 
+// globbingSecurityBackend is a test security backend that can tell where the
+// profiles of a snap are stored.
+type globbingSecurityBackend struct {
+	ifacetest.TestSecurityBackend
+	dir string
+}
+
+func (b *globbingSecurityBackend) ProfileGlobs(snapName string) []string {
+	return []string{filepath.Join(b.dir, fmt.Sprintf("snap.%s.*", snapName))}
+}
+
+func (s *interfaceManagerSuite) TestOrphanedProfilesRemoved(c *C) {
+	backend := &globbingSecurityBackend{
+		TestSecurityBackend: ifacetest.TestSecurityBackend{BackendName: "globbing"},
+		dir:                 c.MkDir(),
+	}
+	s.mockSecBackend(backend)
+	s.mockSnap(c, fmt.Sprintf(consumerYaml3, ""))
+	for _, name := range []string{"snap.consumer.app", "snap.gone.app", "snap.gone.hook.configure", "snap.other_foo.app", "unrelated"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(backend.dir, name), nil, 0644), IsNil)
+	}
+
+	s.manager(c)
+
+	c.Check(backend.RemoveCalls, DeepEquals, []string{"gone", "other_foo"})
+	c.Check(s.log.String(), testutil.Contains, `removed orphaned globbing profiles of snap "gone"`)
+}
+
+func (s *interfaceManagerSuite) TestOrphanedProfilesRemoveError(c *C) {
+	backend := &globbingSecurityBackend{
+		TestSecurityBackend: ifacetest.TestSecurityBackend{
+			BackendName:    "globbing",
+			RemoveCallback: func(snapName string) error { return fmt.Errorf("boom") },
+		},
+		dir: c.MkDir(),
+	}
+	s.mockSecBackend(backend)
+	c.Assert(ioutil.WriteFile(filepath.Join(backend.dir, "snap.gone.app"), nil, 0644), IsNil)
+
+	// failing to remove the profiles does not prevent the startup
+	s.manager(c)
+
+	c.Check(backend.RemoveCalls, DeepEquals, []string{"gone"})
+	c.Check(s.log.String(), testutil.Contains, `cannot remove orphaned globbing profiles of snap "gone": boom`)
+}
+
+func (s *interfaceManagerSuite) TestOrphanedProfilesKeptForSnapBeingInstalled(c *C) {
+	backend := &globbingSecurityBackend{
+		TestSecurityBackend: ifacetest.TestSecurityBackend{BackendName: "globbing"},
+		dir:                 c.MkDir(),
+	}
+	s.mockSecBackend(backend)
+	for _, name := range []string{"snap.installing.app", "snap.gone.app"} {
+		c.Assert(ioutil.WriteFile(filepath.Join(backend.dir, name), nil, 0644), IsNil)
+	}
+
+	// the snap is not in the state yet but its profiles were already set up
+	s.state.Lock()
+	si := &snap.SideInfo{RealName: "installing", Revision: snap.R(1)}
+	snaptest.MockSnap(c, "name: installing", si)
+	chg := s.state.NewChange("install", "install a snap")
+	t1 := s.state.NewTask("setup-profiles", "setup profiles")
+	t1.Set("snap-setup", &snapstate.SnapSetup{SideInfo: si})
+	t1.SetStatus(state.DoneStatus)
+	t2 := s.state.NewTask("link-snap", "link snap")
+	t2.Set("snap-setup", &snapstate.SnapSetup{SideInfo: si})
+	t2.WaitFor(t1)
+	chg.AddTask(t1)
+	chg.AddTask(t2)
+	s.state.Unlock()
+
+	s.manager(c)
+
+	c.Check(backend.RemoveCalls, DeepEquals, []string{"gone"})
+	c.Check(s.log.String(), Not(testutil.Contains), `orphaned globbing profiles of snap "installing"`)
+}
+
 func (s *interfaceManagerSuite) testForget(c *C, plugSnap, plugName, slotSnap, slotName string) {
 	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
 	s.mockSnap(c, consumerYaml)
@@ -2471,6 +2681,92 @@ func (s *interfaceManagerSuite) TestBadInterfacesWarning(c *C) {
 	c.Check(strings.Join(task.Log(), ""), Matches, `.* snap "snap" has bad plugs or slots: plug-name \(reason-for-bad\)`)
 }
 
+func (s *interfaceManagerSuite) TestLegacySecurityWarning(c *C) {
+	s.MockModel(c, nil)
+
+	_ = s.manager(c)
+
+	snapInfo := s.mockSnap(c, `name: snap
+version: 1
+apps:
+  app:
+    command: foo
+    caps: [networking, unknown]
+`)
+
+	// Run the setup-snap-security task and let it finish.
+	change := s.addSetupSnapSecurityChange(&snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: snapInfo.SnapName(),
+			Revision: snapInfo.Revision,
+		},
+	})
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Status(), Equals, state.DoneStatus)
+
+	warns := s.state.AllWarnings()
+	c.Assert(warns, HasLen, 1)
+	c.Check(warns[0].String(), Equals, `snap "snap" uses legacy security declarations: app "app": cap "networking" replaced by plug "network"; app "app": cap "unknown" cannot be expressed with interfaces`)
+}
+
+func (s *interfaceManagerSuite) TestStartupKeepsReservedNamesOfInstalledSnaps(c *C) {
+	s.AddCleanup(snap.MockSanitizePlugsSlots(builtin.SanitizePlugsSlots))
+
+	s.mockSnap(c, `name: snap
+version: 1
+plugs:
+  x11:
+    interface: home
+`)
+
+	// reserved names are only checked on install, snaps that are already
+	// installed keep their plugs and slots
+	mgr := s.manager(c)
+	repo := mgr.Repository()
+	c.Check(repo.Plug("snap", "x11"), NotNil)
+}
+
+const reservedNamesSnapYaml = `name: snap
+version: 1
+plugs:
+  network:
+    interface: home
+`
+
+func (s *interfaceManagerSuite) TestCheckInterfacesReservedNames(c *C) {
+	deviceCtx := s.TrivialDeviceContext(c, nil)
+	s.manager(c)
+	snapInfo := s.mockSnap(c, reservedNamesSnapYaml)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(ifacestate.CheckInterfaces(s.state, snapInfo, deviceCtx), ErrorMatches,
+		`snap "snap" has bad plugs or slots: network \(plug name "network" is reserved for the "network" interface, .*\)`)
+}
+
+func (s *interfaceManagerSuite) TestStartupAllowsReservedNames(c *C) {
+	s.AddCleanup(func() { builtin.AllowReservedNames(nil) })
+	deviceCtx := s.TrivialDeviceContext(c, nil)
+
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	c.Assert(tr.Set("core", "interfaces.allow-reserved-names", "network"), IsNil)
+	tr.Commit()
+	s.state.Unlock()
+
+	// the exemption is applied when the manager starts
+	s.manager(c)
+	snapInfo := s.mockSnap(c, reservedNamesSnapYaml)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(ifacestate.CheckInterfaces(s.state, snapInfo, deviceCtx), IsNil)
+}
+
 // The auto-connect task will auto-connect plugs with viable candidates.
 func (s *interfaceManagerSuite) TestDoSetupSnapSecurityAutoConnectsPlugs(c *C) {
 	s.MockModel(c, nil)
@@ -3687,6 +3983,18 @@ func (s *interfaceManagerSuite) testAutoconnectionsRemovedForMissingPlugs(c *C,
 	var conns map[string]interface{}
 	c.Assert(s.state.Get("conns", &conns), IsNil)
 	c.Check(conns, DeepEquals, expectedConns)
+
+	// The connection kept in the state but not restored is reported. The
+	// refreshed revision is already in place when the manager starts up,
+	// so the other auto-connection got removed at that point already.
+	setupProfiles := change.Tasks()[0]
+	c.Assert(setupProfiles.Kind(), Equals, "setup-profiles")
+	if byGadget {
+		c.Assert(setupProfiles.Log(), HasLen, 1)
+		c.Check(setupProfiles.Log()[0], Matches, `.* cannot restore connections of snap "snap": snap:test1 ubuntu-core:test1`)
+	} else {
+		c.Check(setupProfiles.Log(), HasLen, 0)
+	}
 }
 
 func (s *interfaceManagerSuite) TestSetupProfilesRemovesMissingAutoconnectedSlots(c *C) {
@@ -4615,6 +4923,80 @@ slots:
 	c.Check(ifacestate.CheckInterfaces(s.state, snapInfo, deviceCtx), ErrorMatches, "installation denied.*")
 }
 
+func (s *interfaceManagerSuite) TestCheckInterfacesDisallowedByModel(c *C) {
+	deviceCtx := s.TrivialDeviceContext(c, map[string]interface{}{
+		"disallowed-interfaces": []interface{}{"test"},
+	})
+	s.mockIface(&ifacetest.TestInterface{InterfaceName: "test"})
+	snapInfo := s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(ifacestate.CheckInterfaces(s.state, snapInfo, deviceCtx), ErrorMatches, `cannot use slot "slot" of snap "producer": interface "test" is not allowed on this model`)
+}
+
+func (s *interfaceManagerSuite) TestCheckInterfacesNotAllowedByModel(c *C) {
+	deviceCtx := s.TrivialDeviceContext(c, map[string]interface{}{
+		"allowed-interfaces": []interface{}{"network", "test2"},
+	})
+	s.mockIface(&ifacetest.TestInterface{InterfaceName: "test"})
+	snapInfo := s.mockSnap(c, consumerYaml)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(ifacestate.CheckInterfaces(s.state, snapInfo, deviceCtx), ErrorMatches, `cannot use plug "plug" of snap "consumer": interface "test" is not allowed on this model`)
+}
+
+func (s *interfaceManagerSuite) TestStartupSkipsInterfacesDisallowedByModel(c *C) {
+	s.MockModel(c, map[string]interface{}{
+		"disallowed-interfaces": []interface{}{"test", "network"},
+	})
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+
+	repo := s.manager(c).Repository()
+	c.Check(repo.Interface("test"), IsNil)
+	c.Check(repo.Interface("network"), IsNil)
+	c.Check(repo.Interface("test2"), NotNil)
+	c.Check(repo.Interface("home"), NotNil)
+}
+
+func (s *interfaceManagerSuite) TestStartupOnlyAddsInterfacesAllowedByModel(c *C) {
+	s.MockModel(c, map[string]interface{}{
+		"allowed-interfaces": []interface{}{"test", "network"},
+	})
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+
+	repo := s.manager(c).Repository()
+	var names []string
+	for _, iface := range repo.AllInterfaces() {
+		names = append(names, iface.Name())
+	}
+	c.Check(names, DeepEquals, []string{"network", "test"})
+}
+
+func (s *interfaceManagerSuite) TestEnsureFiltersInterfacesOnceModelIsSet(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+
+	// without a model, as before seeding, all interfaces are added
+	mgr := s.manager(c)
+	repo := mgr.Repository()
+	c.Assert(mgr.Ensure(), IsNil)
+	c.Check(repo.Interface("test"), NotNil)
+	c.Check(repo.Interface("network"), NotNil)
+	c.Check(repo.Plug("consumer", "plug"), NotNil)
+
+	s.MockModel(c, map[string]interface{}{
+		"disallowed-interfaces": []interface{}{"test", "network"},
+	})
+	c.Assert(mgr.Ensure(), IsNil)
+	c.Check(repo.Interface("test"), IsNil)
+	c.Check(repo.Interface("network"), IsNil)
+	c.Check(repo.Interface("test2"), NotNil)
+	c.Check(repo.Plug("consumer", "plug"), IsNil)
+	c.Check(repo.Plug("consumer", "otherplug"), NotNil)
+}
+
 func (s *interfaceManagerSuite) TestCheckInterfacesNoDenyIfNoDecl(c *C) {
 	deviceCtx := s.TrivialDeviceContext(c, nil)
 	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
@@ -6045,21 +6427,389 @@ func (s *interfaceManagerSuite) TestDisconnectInterfaces(c *C) {
 	}
 }
 
-func (s *interfaceManagerSuite) testDisconnectInterfacesRetry(c *C, conflictingKind string) {
-	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
-	_ = s.manager(c)
+func (s *interfaceManagerSuite) testAutoDisconnectRetainedConns(c *C, purge bool) map[string]interface{} {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, fmt.Sprintf(consumerYaml3, ""))
+	s.mockSnap(c, fmt.Sprintf(producerYaml3, ""))
+	s.mockSnap(c, `name: other
+version: 1
+slots:
+ slot:
+  interface: test
+`)
 
-	consumerInfo := s.mockSnap(c, consumerYaml)
-	producerInfo := s.mockSnap(c, producerYaml)
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+		"consumer:plug other:slot":    map[string]interface{}{"interface": "test", "auto": true},
+	})
+	s.state.Set("retained-conns", map[string]interface{}{
+		"consumer:plug gone:slot": "test",
+		"another:plug gone:slot":  "test",
+	})
+	s.state.Set("pending-conns", map[string]interface{}{
+		"consumer:plug pending:slot": "test",
+	})
+	s.state.Unlock()
 
-	supprod := &snapstate.SnapSetup{
+	_ = s.manager(c)
+
+	s.state.Lock()
+	chg := s.state.NewChange("remove", "")
+	t := s.state.NewTask("auto-disconnect", "")
+	t.Set("snap-setup", &snapstate.SnapSetup{
 		SideInfo: &snap.SideInfo{
-			RealName: "producer"},
+			RealName: "consumer"},
+	})
+	if purge {
+		t.Set("purge", true)
 	}
+	chg.AddTask(t)
+	s.state.Unlock()
+
+	s.settle(c)
 
 	s.state.Lock()
+	defer s.state.Unlock()
 
-	repo := s.manager(c).Repository()
+	c.Assert(chg.Status(), Equals, state.DoneStatus)
+
+	var conns map[string]interface{}
+	err := s.state.Get("conns", &conns)
+	c.Assert(err, IsNil)
+	c.Check(conns, HasLen, 0)
+
+	var retained map[string]interface{}
+	err = s.state.Get("retained-conns", &retained)
+	if err == state.ErrNoState {
+		return nil
+	}
+	c.Assert(err, IsNil)
+	return retained
+}
+
+func (s *interfaceManagerSuite) TestAutoDisconnectRetainsManualConnections(c *C) {
+	retained := s.testAutoDisconnectRetainedConns(c, false)
+	// the automatic connection is not retained, it is re-created on
+	// reinstall anyway
+	c.Check(retained, DeepEquals, map[string]interface{}{
+		"consumer:plug producer:slot": "test",
+		"consumer:plug gone:slot":     "test",
+		"another:plug gone:slot":      "test",
+	})
+}
+
+func (s *interfaceManagerSuite) TestAutoDisconnectPurgeForgetsConnections(c *C) {
+	retained := s.testAutoDisconnectRetainedConns(c, true)
+	c.Check(retained, DeepEquals, map[string]interface{}{
+		"another:plug gone:slot": "test",
+	})
+
+	// pending connections are intents that are kept
+	s.state.Lock()
+	defer s.state.Unlock()
+	var pending map[string]interface{}
+	c.Assert(s.state.Get("pending-conns", &pending), IsNil)
+	c.Check(pending, DeepEquals, map[string]interface{}{
+		"consumer:plug pending:slot": "test",
+	})
+}
+
+func (s *interfaceManagerSuite) TestUndoDisconnectDropsRetainedConnection(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	consumerInfo := s.mockSnap(c, fmt.Sprintf(consumerYaml3, ""))
+	producerInfo := s.mockSnap(c, fmt.Sprintf(producerYaml3, ""))
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Unlock()
+
+	_ = s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	conn := &interfaces.Connection{
+		Plug: interfaces.NewConnectedPlug(consumerInfo.Plugs["plug"], nil, nil),
+		Slot: interfaces.NewConnectedSlot(producerInfo.Slots["slot"], nil, nil),
+	}
+	ts, err := ifacestate.DisconnectPriv(s.state, conn, ifacestate.NewDisconnectOptsWithRetainSet())
+	c.Assert(err, IsNil)
+
+	chg := s.state.NewChange("remove", "")
+	chg.AddAll(ts)
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitAll(ts)
+	chg.AddTask(terr)
+
+	s.state.Unlock()
+	s.settle(c)
+	s.state.Lock()
+
+	c.Assert(chg.Status(), Equals, state.ErrorStatus)
+
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	var retained map[string]interface{}
+	c.Check(s.state.Get("retained-conns", &retained), Equals, state.ErrNoState)
+}
+
+func (s *interfaceManagerSuite) testAutoConnectRetainedConns(c *C, retained map[string]interface{}) (*state.Change, map[string]interface{}) {
+	s.MockModel(c, nil)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, fmt.Sprintf(producerYaml3, ""))
+
+	s.state.Lock()
+	s.state.Set("retained-conns", retained)
+	s.state.Unlock()
+
+	_ = s.manager(c)
+
+	snapInfo := s.mockSnap(c, fmt.Sprintf(consumerYaml3, ""))
+	change := s.addSetupSnapSecurityChange(&snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: snapInfo.SnapName(),
+			Revision: snapInfo.Revision,
+		},
+	})
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	var conns map[string]interface{}
+	err := s.state.Get("conns", &conns)
+	c.Assert(err, IsNil)
+	return change, conns
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectRestoresRetainedConnections(c *C) {
+	change, conns := s.testAutoConnectRetainedConns(c, map[string]interface{}{
+		"consumer:plug producer:slot": "test",
+		"consumer:plug gone:slot":     "test",
+		"another:plug producer:slot":  "test",
+	})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Status(), Equals, state.DoneStatus)
+	// restored as a manual connection
+	c.Check(conns, DeepEquals, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{
+			"interface": "test",
+		},
+	})
+	var retained map[string]interface{}
+	c.Assert(s.state.Get("retained-conns", &retained), IsNil)
+	c.Check(retained, DeepEquals, map[string]interface{}{
+		"consumer:plug gone:slot":    "test",
+		"another:plug producer:slot": "test",
+	})
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectDropsStaleRetainedConnections(c *C) {
+	change, conns := s.testAutoConnectRetainedConns(c, map[string]interface{}{
+		"consumer:plug producer:slot":    "other",
+		"consumer:missing producer:slot": "test",
+	})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Status(), Equals, state.DoneStatus)
+	// only the regular auto-connection
+	c.Check(conns, DeepEquals, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{
+			"interface": "test", "auto": true,
+		},
+	})
+	var retained map[string]interface{}
+	c.Check(s.state.Get("retained-conns", &retained), Equals, state.ErrNoState)
+
+	autoConnect := change.Tasks()[1]
+	c.Assert(autoConnect.Kind(), Equals, "auto-connect")
+	log := strings.Join(autoConnect.Log(), "\n")
+	c.Check(log, Matches, `(?s).*cannot restore connection consumer:plug producer:slot: interface is no longer "other".*`)
+	c.Check(log, Matches, `(?s).*cannot restore connection consumer:missing producer:slot: no longer provided by snap "consumer".*`)
+}
+
+func (s *interfaceManagerSuite) TestUndoAutoConnectRestoresRetainedAndPendingConnections(c *C) {
+	_ = s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	chg := s.state.NewChange("install", "")
+	t := s.state.NewTask("auto-connect", "")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "consumer"},
+	})
+	t.Set("restored-conns", map[string]string{"consumer:plug producer:slot": "test"})
+	t.Set("restored-pending-conns", map[string]string{"consumer:plug other:slot": "test"})
+	t.SetStatus(state.UndoStatus)
+	chg.AddTask(t)
+
+	s.state.Unlock()
+	s.settle(c)
+	s.state.Lock()
+
+	c.Assert(chg.Status(), Equals, state.UndoneStatus)
+	var retained, pending map[string]interface{}
+	c.Assert(s.state.Get("retained-conns", &retained), IsNil)
+	c.Check(retained, DeepEquals, map[string]interface{}{"consumer:plug producer:slot": "test"})
+	c.Assert(s.state.Get("pending-conns", &pending), IsNil)
+	c.Check(pending, DeepEquals, map[string]interface{}{"consumer:plug other:slot": "test"})
+}
+
+func (s *interfaceManagerSuite) TestAutoConnectRetainedConnectionsConflictRetry(c *C) {
+	s.MockModel(c, nil)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, fmt.Sprintf(producerYaml3, ""))
+	s.mockSnap(c, `name: other
+version: 1
+slots:
+ slot:
+  interface: test2
+`)
+	retained := map[string]interface{}{
+		"consumer:plug producer:slot": "test",
+	}
+
+	s.state.Lock()
+	s.state.Set("retained-conns", retained)
+	s.state.Unlock()
+
+	s.manager(c)
+	snapInfo := s.mockSnap(c, consumerYaml)
+
+	// the snap providing the slot for the auto-connection of the other
+	// plug is being refreshed, the retained connection is not affected
+	s.state.Lock()
+	otherChg := s.state.NewChange("other-chg", "...")
+	t := s.state.NewTask("link-snap", "...")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "other"},
+	})
+	otherChg.AddTask(t)
+	s.state.Unlock()
+
+	chg := s.addSetupSnapSecurityChange(&snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: snapInfo.SnapName(),
+			Revision: snapInfo.Revision,
+		},
+	})
+	for i := 0; i < 3; i++ {
+		s.se.Ensure()
+		s.se.Wait()
+	}
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	t = chg.Tasks()[1]
+	c.Assert(t.Kind(), Equals, "auto-connect")
+	c.Assert(chg.Err(), IsNil)
+	c.Check(t.Status(), Equals, state.DoingStatus)
+	c.Check(strings.Join(t.Log(), ""), Matches, `.*Waiting for conflicting change in progress: conflicting snap other.*`)
+
+	// the retained connection is kept for when the task is retried
+	var stillRetained map[string]interface{}
+	c.Assert(s.state.Get("retained-conns", &stillRetained), IsNil)
+	c.Check(stillRetained, DeepEquals, retained)
+	var restored map[string]interface{}
+	c.Check(t.Get("restored-conns", &restored), Equals, state.ErrNoState)
+}
+
+func (s *interfaceManagerSuite) TestConnectPendingEstablishedOnInstall(c *C) {
+	s.MockModel(c, nil)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test", AutoConnectCallback: func(*snap.PlugInfo, *snap.SlotInfo) bool { return false }})
+	s.mockSnap(c, fmt.Sprintf(producerYaml3, ""))
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	connRef := &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}
+	c.Assert(ifacestate.ConnectPending(s.state, repo, connRef, "test"), IsNil)
+	var pending map[string]interface{}
+	c.Assert(s.state.Get("pending-conns", &pending), IsNil)
+	c.Check(pending, DeepEquals, map[string]interface{}{"consumer:plug producer:slot": "test"})
+	s.state.Unlock()
+
+	snapInfo := s.mockSnap(c, fmt.Sprintf(consumerYaml3, ""))
+	change := s.addSetupSnapSecurityChange(&snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: snapInfo.SnapName(),
+			Revision: snapInfo.Revision,
+		},
+	})
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(change.Status(), Equals, state.DoneStatus)
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{
+			"interface": "test",
+		},
+	})
+	c.Check(s.state.Get("pending-conns", &pending), Equals, state.ErrNoState)
+}
+
+func (s *interfaceManagerSuite) TestConnectPendingErrors(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, t := range []struct {
+		plug, slot, iface string
+		err               string
+	}{
+		{"consumer:plug", "gone:slot", "unknown", `unknown interface "unknown"`},
+		{"consumer:plug", "gone:slot", "test2", `plug consumer:plug is not of interface "test2"`},
+		{"gone:plug", "producer:slot", "test2", `slot producer:slot is not of interface "test2"`},
+		{"consumer:plug", "producer:slot", "test", `cannot record pending connection consumer:plug producer:slot: plug and slot are already available`},
+	} {
+		connRef, err := interfaces.ParseConnRef(t.plug + " " + t.slot)
+		c.Assert(err, IsNil)
+		c.Check(ifacestate.ConnectPending(s.state, repo, connRef, t.iface), ErrorMatches, t.err)
+	}
+	var pending map[string]interface{}
+	c.Check(s.state.Get("pending-conns", &pending), Equals, state.ErrNoState)
+}
+
+func (s *interfaceManagerSuite) testDisconnectInterfacesRetry(c *C, conflictingKind string) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	_ = s.manager(c)
+
+	consumerInfo := s.mockSnap(c, consumerYaml)
+	producerInfo := s.mockSnap(c, producerYaml)
+
+	supprod := &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "producer"},
+	}
+
+	s.state.Lock()
+
+	repo := s.manager(c).Repository()
 	c.Assert(repo.AddSnap(consumerInfo), IsNil)
 	c.Assert(repo.AddSnap(producerInfo), IsNil)
 
@@ -6184,6 +6934,59 @@ func checkAutoConnectGadgetTasks(c *C, tasks []*state.Task) {
 	c.Assert(gotConnect, Equals, true)
 }
 
+func (s *interfaceManagerSuite) TestAutoConnectGadgetRecordsPendingConnections(c *C) {
+	r1 := release.MockOnClassic(false)
+	defer r1()
+
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.MockSnapDecl(c, "consumer", "publisher1", nil)
+	s.mockSnap(c, consumerYaml)
+	// the producer is not installed yet
+	s.MockSnapDecl(c, "producer", "publisher2", nil)
+
+	gadgetInfo := s.mockSnap(c, `name: gadget
+type: gadget
+`)
+	gadgetYaml := []byte(`
+connections:
+   - plug: consumeridididididididididididid:plug
+     slot: produceridididididididididididid:slot
+
+volumes:
+    volume-id:
+        bootloader: grub
+`)
+	err := ioutil.WriteFile(filepath.Join(gadgetInfo.MountDir(), "meta", "gadget.yaml"), gadgetYaml, 0644)
+	c.Assert(err, IsNil)
+
+	s.MockModel(c, nil)
+	s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	chg := s.state.NewChange("setting-up", "...")
+	t := s.state.NewTask("auto-connect", "gadget connections")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "consumer"},
+	})
+	chg.AddTask(t)
+
+	s.state.Unlock()
+	s.settle(c)
+	s.state.Lock()
+
+	c.Assert(chg.Err(), IsNil)
+	// nothing to connect yet
+	c.Check(chg.Tasks(), HasLen, 1)
+	var pending map[string]interface{}
+	c.Assert(s.state.Get("pending-conns", &pending), IsNil)
+	c.Check(pending, DeepEquals, map[string]interface{}{
+		"consumer:plug producer:slot": "test",
+	})
+}
+
 func (s *interfaceManagerSuite) TestAutoConnectGadget(c *C) {
 	r1 := release.MockOnClassic(false)
 	defer r1()
@@ -6621,15 +7424,26 @@ func (s *interfaceManagerSuite) TestUDevMonitorInit(c *C) {
 	s.o.AddManager(mgr)
 	c.Assert(s.o.StartUp(), IsNil)
 
+	c.Check(mgr.HotplugMonitorStatus(), Equals, "not-running")
+
 	// succesfull initialization should result in exactly 1 connect and run call
 	for i := 0; i < 5; i++ {
 		c.Assert(s.se.Ensure(), IsNil)
 	}
+	c.Check(mgr.HotplugMonitorStatus(), Equals, "running")
 	s.se.Stop()
 
 	c.Assert(u.ConnectCalls, Equals, 1)
 	c.Assert(u.RunCalls, Equals, 1)
 	c.Assert(u.StopCalls, Equals, 1)
+	c.Check(mgr.HotplugMonitorStatus(), Equals, "not-running")
+}
+
+func (s *interfaceManagerSuite) TestHotplugMonitorStatusDisabled(c *C) {
+	mgr, err := ifacestate.Manager(s.state, nil, s.o.TaskRunner(), nil, nil)
+	c.Assert(err, IsNil)
+	mgr.DisableUDevMonitor()
+	c.Check(mgr.HotplugMonitorStatus(), Equals, "disabled")
 }
 
 func (s *interfaceManagerSuite) TestUDevMonitorInitErrors(c *C) {
@@ -6666,6 +7480,7 @@ func (s *interfaceManagerSuite) TestUDevMonitorInitErrors(c *C) {
 	c.Assert(u.ConnectCalls, Equals, 1)
 	c.Assert(u.RunCalls, Equals, 0)
 	c.Assert(u.StopCalls, Equals, 0)
+	c.Check(mgr.HotplugMonitorStatus(), Equals, "not-running")
 
 	u.ConnectError = nil
 	u.RunError = fmt.Errorf("Run failed")
@@ -6840,6 +7655,43 @@ func (s *interfaceManagerSuite) TestHotplugConnect(c *C) {
 		}})
 }
 
+func (s *interfaceManagerSuite) TestHotplugConnectPendingConnection(c *C) {
+	s.MockModel(c, nil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	chg := s.setupHotplugConnectTestData(c)
+
+	s.state.Set("pending-conns", map[string]interface{}{
+		"consumer:plug core:hotplugslot":   "test",
+		"consumer:plug core:otherslot":     "test",
+		"consumer:otherplug producer:slot": "test2",
+	})
+
+	s.state.Unlock()
+	s.settle(c)
+	s.state.Lock()
+
+	c.Assert(chg.Err(), IsNil)
+
+	// established as a manual connection
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Assert(conns, DeepEquals, map[string]interface{}{
+		"consumer:plug core:hotplugslot": map[string]interface{}{
+			"interface":   "test",
+			"hotplug-key": "1234",
+			"plug-static": map[string]interface{}{"attr1": "value1"},
+		}})
+	// pending connections of other slots are kept
+	var pending map[string]interface{}
+	c.Assert(s.state.Get("pending-conns", &pending), IsNil)
+	c.Check(pending, DeepEquals, map[string]interface{}{
+		"consumer:plug core:otherslot":     "test",
+		"consumer:otherplug producer:slot": "test2",
+	})
+}
+
 func (s *interfaceManagerSuite) TestHotplugConnectIgnoresUndesired(c *C) {
 	s.MockModel(c, nil)
 
@@ -9024,3 +9876,65 @@ func (s *interfaceManagerSuite) TestConnectSetsUpSecurityFails(c *C) {
 	ifaces := repo.Interfaces()
 	c.Check(ifaces.Connections, HasLen, 0)
 }
+
+func (s *interfaceManagerSuite) TestConnectSetsUpSecurityFailsRestoresProfiles(c *C) {
+	s.MockModel(c, nil)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	_ = s.manager(c)
+
+	s.secBackend.BackendName = "test"
+	var connectedDuringSetup []bool
+	s.secBackend.SetupCallback = func(snapInfo *snap.Info, opts interfaces.ConfinementOptions, repo *interfaces.Repository) error {
+		conns, err := repo.Connected(snapInfo.InstanceName(), map[string]string{"consumer": "plug", "producer": "slot"}[snapInfo.InstanceName()])
+		c.Assert(err, IsNil)
+		connectedDuringSetup = append(connectedDuringSetup, len(conns) > 0)
+		if snapInfo.InstanceName() == "consumer" {
+			return fmt.Errorf("setup-callback failed")
+		}
+		return nil
+	}
+
+	s.state.Lock()
+	ts, err := ifacestate.Connect(s.state, "consumer", "plug", "producer", "slot")
+	c.Assert(err, IsNil)
+	ts.Tasks()[0].Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "consumer",
+		},
+	})
+	change := s.state.NewChange("connect", "")
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(change.Err(), ErrorMatches, `(?ms).*\(setup-callback failed\)`)
+	c.Check(change.Status(), Equals, state.ErrorStatus)
+
+	// the producer was set up with the connection, then the consumer
+	// failed and both were set up again without it
+	c.Assert(s.secBackend.SetupCalls, HasLen, 4)
+	c.Check(s.secBackend.SetupCalls[0].SnapInfo.InstanceName(), Equals, "producer")
+	c.Check(s.secBackend.SetupCalls[1].SnapInfo.InstanceName(), Equals, "consumer")
+	c.Check(s.secBackend.SetupCalls[2].SnapInfo.InstanceName(), Equals, "producer")
+	c.Check(s.secBackend.SetupCalls[3].SnapInfo.InstanceName(), Equals, "consumer")
+	c.Check(connectedDuringSetup, DeepEquals, []bool{true, true, false, false})
+
+	var connectTask *state.Task
+	for _, t := range change.Tasks() {
+		if t.Kind() == "connect" {
+			connectTask = t
+		}
+	}
+	c.Assert(connectTask, NotNil)
+	c.Check(strings.Join(connectTask.Log(), "\n"), Matches, `(?ms).*cannot restore security profiles of backends: test.*`)
+
+	repo := s.manager(c).Repository()
+	ifaces := repo.Interfaces()
+	c.Check(ifaces.Connections, HasLen, 0)
+}
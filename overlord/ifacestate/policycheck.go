@@ -0,0 +1,202 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// policyRecheckInterval is how often the declarations of the snaps with
+// connections are checked for updates.
+var policyRecheckInterval = 10 * time.Minute
+
+// PolicyViolation describes an established connection that is no longer
+// allowed by the policy.
+type PolicyViolation struct {
+	Interface string `json:"interface"`
+	Reason    string `json:"reason"`
+}
+
+func getPolicyViolations(st *state.State) (map[string]*PolicyViolation, error) {
+	var violations map[string]*PolicyViolation
+	err := st.Get("conns-policy-violations", &violations)
+	if err != nil && err != state.ErrNoState {
+		return nil, fmt.Errorf("cannot obtain policy violations: %s", err)
+	}
+	if violations == nil {
+		violations = make(map[string]*PolicyViolation)
+	}
+	return violations, nil
+}
+
+// ConnectionPolicyViolations returns the established connections that are
+// no longer allowed by the policy, keyed by connection ID. Such connections
+// are reported to the administrator with a warning and are left as they
+// are, until they are disconnected.
+func ConnectionPolicyViolations(st *state.State) (map[string]*PolicyViolation, error) {
+	return getPolicyViolations(st)
+}
+
+// snapDeclarationRevision returns the revision of the declaration of the
+// snap with the given ID.
+func snapDeclarationRevision(st *state.State, snapID string) (int, error) {
+	decl, err := assertstate.SnapDeclaration(st, snapID)
+	if err != nil {
+		return 0, err
+	}
+	return decl.Revision(), nil
+}
+
+// reevaluateConnectionsPolicy checks the established connections against
+// the policy when the declarations of the snaps involved were updated. The
+// connections that are no longer allowed are recorded and reported with a
+// warning, all connections are checked on the first run.
+func (m *InterfaceManager) reevaluateConnectionsPolicy() error {
+	now := timeNow()
+	if now.Before(m.policyRecheckTime) {
+		return nil
+	}
+	m.policyRecheckTime = now.Add(policyRecheckInterval)
+
+	st := m.state
+	st.Lock()
+	defer st.Unlock()
+
+	deviceCtx, err := snapstate.DeviceCtx(st, nil, nil)
+	if err == state.ErrNoState {
+		// too early
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	conns, err := getConns(st)
+	if err != nil {
+		return err
+	}
+	violations, err := getPolicyViolations(st)
+	if err != nil {
+		return err
+	}
+	checker, err := newConnectChecker(st, deviceCtx)
+	if err != nil {
+		return err
+	}
+
+	revisions := make(map[string]int)
+	// updated returns whether the declaration of the given snap changed
+	// since the last check
+	updated := func(snapID string) (bool, error) {
+		rev, ok := revisions[snapID]
+		if !ok {
+			var err error
+			rev, err = snapDeclarationRevision(st, snapID)
+			if err != nil {
+				return false, err
+			}
+			revisions[snapID] = rev
+		}
+		prev, ok := m.declRevisions[snapID]
+		return m.declRevisions == nil || !ok || prev != rev, nil
+	}
+
+	current := make(map[string]*PolicyViolation)
+	var reported []string
+	for id, cs := range conns {
+		// connections made by the gadget are not subject to the
+		// snap declarations
+		if cs.Undesired || cs.HotplugGone || cs.ByGadget {
+			continue
+		}
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return err
+		}
+		conn, err := m.repo.Connection(connRef)
+		if err != nil {
+			continue
+		}
+		plugSnapID := conn.Plug.Snap().SnapID
+		slotSnapID := conn.Slot.Snap().SnapID
+		// snaps without a declaration were installed with
+		// --dangerous, there is no policy to check
+		if plugSnapID == "" || slotSnapID == "" {
+			continue
+		}
+		changed, err := updated(plugSnapID)
+		if err == nil {
+			var slotChanged bool
+			slotChanged, err = updated(slotSnapID)
+			changed = changed || slotChanged
+		}
+		if asserts.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !changed {
+			if v := violations[id]; v != nil {
+				current[id] = v
+			}
+			continue
+		}
+
+		ic, err := checker.candidate(conn.Plug, conn.Slot)
+		if err != nil {
+			return err
+		}
+		// an established connection, automatic or not, violates the
+		// policy only if it would not be allowed anymore, no longer
+		// being auto-connectable does not make it one
+		if err = ic.Check(); err == nil {
+			continue
+		}
+		current[id] = &PolicyViolation{Interface: cs.Interface, Reason: err.Error()}
+		if violations[id] == nil {
+			reported = append(reported, id)
+		}
+	}
+	m.declRevisions = revisions
+
+	if len(current) == 0 {
+		st.Set("conns-policy-violations", nil)
+	} else {
+		st.Set("conns-policy-violations", current)
+	}
+	if len(reported) > 0 {
+		sort.Strings(reported)
+		lines := make([]string, len(reported))
+		for i, id := range reported {
+			lines[i] = fmt.Sprintf("- %s: %s", id, current[id].Reason)
+		}
+		st.Warnf("the following connections are no longer allowed by the policy, use \"snap disconnect\" to remove them:\n%s", strings.Join(lines, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+func (s *interfaceManagerSuite) TestReevaluateConnectionsPolicy(c *C) {
+	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-connection:
+      plug-publisher-id:
+        - $SLOT_PUBLISHER_ID
+`))
+	defer restore()
+	now := time.Now()
+	restore = ifacestate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	s.MockModel(c, nil)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.MockSnapDecl(c, "consumer", "one-publisher", nil)
+	s.MockSnapDecl(c, "producer", "one-publisher", nil)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+	mgr.Ensure()
+
+	s.state.Lock()
+	violations, err := ifacestate.ConnectionPolicyViolations(s.state)
+	c.Assert(err, IsNil)
+	c.Check(violations, HasLen, 0)
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+	s.state.Unlock()
+
+	// the declaration of the consumer now denies the connection
+	s.MockSnapDecl(c, "consumer", "one-publisher", map[string]interface{}{
+		"format":   "1",
+		"revision": "1",
+		"plugs": map[string]interface{}{
+			"test": map[string]interface{}{
+				"deny-connection": "true",
+			},
+		},
+	})
+
+	// the declarations are not checked again right away
+	mgr.Ensure()
+	s.state.Lock()
+	violations, err = ifacestate.ConnectionPolicyViolations(s.state)
+	c.Assert(err, IsNil)
+	c.Check(violations, HasLen, 0)
+	s.state.Unlock()
+
+	now = now.Add(time.Hour)
+	mgr.Ensure()
+
+	s.state.Lock()
+	violations, err = ifacestate.ConnectionPolicyViolations(s.state)
+	c.Assert(err, IsNil)
+	c.Check(violations, DeepEquals, map[string]*ifacestate.PolicyViolation{
+		"consumer:plug producer:slot": {
+			Interface: "test",
+			Reason:    `connection denied by plug rule of interface "test" for "consumer" snap`,
+		},
+	})
+	warnings := s.state.AllWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Equals, `the following connections are no longer allowed by the policy, use "snap disconnect" to remove them:
+- consumer:plug producer:slot: connection denied by plug rule of interface "test" for "consumer" snap`)
+	// the connection is left alone
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, HasLen, 1)
+	s.state.Unlock()
+
+	// the violation is reported once
+	now = now.Add(time.Hour)
+	mgr.Ensure()
+
+	s.state.Lock()
+	violations, err = ifacestate.ConnectionPolicyViolations(s.state)
+	c.Assert(err, IsNil)
+	c.Check(violations, HasLen, 1)
+	c.Check(s.state.AllWarnings(), HasLen, 1)
+	s.state.Unlock()
+
+	// a new revision allowing the connection again clears the violation
+	s.MockSnapDecl(c, "consumer", "one-publisher", map[string]interface{}{
+		"revision": "2",
+	})
+	now = now.Add(time.Hour)
+	mgr.Ensure()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	violations, err = ifacestate.ConnectionPolicyViolations(s.state)
+	c.Assert(err, IsNil)
+	c.Check(violations, HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestReevaluateConnectionsPolicyAutoConnectionNotAutoConnectable(c *C) {
+	// the connection is allowed but no longer auto-connectable
+	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    deny-auto-connection: true
+`))
+	defer restore()
+
+	s.MockModel(c, nil)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.MockSnapDecl(c, "consumer", "one-publisher", nil)
+	s.MockSnapDecl(c, "producer", "one-publisher", nil)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test", "auto": true},
+	})
+	s.state.Unlock()
+
+	mgr := s.manager(c)
+	mgr.Ensure()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	violations, err := ifacestate.ConnectionPolicyViolations(s.state)
+	c.Assert(err, IsNil)
+	c.Check(violations, HasLen, 0)
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestReevaluateConnectionsPolicyTooEarly(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.MockSnapDecl(c, "consumer", "one-publisher", nil)
+	s.MockSnapDecl(c, "producer", "one-publisher", nil)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Unlock()
+
+	// no model yet
+	s.manager(c).Ensure()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	var violations map[string]interface{}
+	c.Check(s.state.Get("conns-policy-violations", &violations), Equals, state.ErrNoState)
+}
@@ -0,0 +1,255 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	_ "golang.org/x/crypto/sha3"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// ProfileConnection describes a connection recorded in a connections
+// profile. Auto and ByGadget record how the connection was established on
+// the exporting device, they are informational and ignored on import.
+type ProfileConnection struct {
+	Interface string `json:"interface"`
+	Auto      bool   `json:"auto,omitempty"`
+	ByGadget  bool   `json:"by-gadget,omitempty"`
+	Undesired bool   `json:"undesired,omitempty"`
+}
+
+// ConnectionsProfile holds the connections of a device, so that they can be
+// restored after the device was reflashed. Connections are keyed by their
+// ID. The profile carries a SHA3-384 checksum of its connections to detect
+// a corrupted file, a profile whose checksum does not match is refused. The
+// checksum does not authenticate the profile.
+type ConnectionsProfile struct {
+	Connections map[string]*ProfileConnection `json:"connections"`
+	Checksum    string                        `json:"checksum"`
+}
+
+func (p *ConnectionsProfile) checksum() (string, error) {
+	data, err := json.Marshal(p.Connections)
+	if err != nil {
+		return "", err
+	}
+	h := crypto.SHA3_384.New()
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ExportConnections returns the profile of the connections of the device,
+// including the automatic connections that were disconnected by the user.
+// Connections of hotplug devices that are not present are not exported.
+func ExportConnections(st *state.State) (*ConnectionsProfile, error) {
+	conns, err := getConns(st)
+	if err != nil {
+		return nil, err
+	}
+	profile := &ConnectionsProfile{
+		Connections: make(map[string]*ProfileConnection, len(conns)),
+	}
+	for id, conn := range conns {
+		if conn.HotplugGone {
+			continue
+		}
+		profile.Connections[id] = &ProfileConnection{
+			Interface: conn.Interface,
+			Auto:      conn.Auto,
+			ByGadget:  conn.ByGadget,
+			Undesired: conn.Undesired,
+		}
+	}
+	profile.Checksum, err = profile.checksum()
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// ImportStrategy controls how a connections profile is merged with the
+// connections of the device when they disagree.
+type ImportStrategy int
+
+const (
+	// ImportMerge adds the connections of the profile and keeps the
+	// connections of the device, a connection disconnected on one side
+	// and connected on the other is left as it is on the device.
+	ImportMerge ImportStrategy = iota
+	// ImportReplace makes the connections of the device match the
+	// profile, connections that are not in the profile are disconnected.
+	ImportReplace
+	// ImportEnforce makes the connections of the device that the profile
+	// mentions match the profile and keeps the other connections of the
+	// device.
+	ImportEnforce
+)
+
+// ImportConnections returns the tasks restoring the connections of the
+// given profile. Connections whose plug or slot is not available are
+// recorded as pending and established once both are available, see
+// ConnectPending. Automatic connections disconnected by the user are
+// recorded as such, so that they are not connected again. Both are
+// recorded by an import-conns task, so that they are forgotten again if
+// the change is undone. As the profile
+// is not authenticated, its connections are established as manual ones,
+// subject to the usual policy checks.
+func ImportConnections(st *state.State, repo *interfaces.Repository, profile *ConnectionsProfile, strategy ImportStrategy) (*state.TaskSet, error) {
+	checksum, err := profile.checksum()
+	if err != nil {
+		return nil, err
+	}
+	if checksum != profile.Checksum {
+		return nil, fmt.Errorf("cannot import connections profile: checksum mismatch")
+	}
+
+	conns, err := getConns(st)
+	if err != nil {
+		return nil, err
+	}
+
+	var toDisconnect, toConnect []*interfaces.ConnRef
+	undesired := make(map[string]string)
+	pending := make(map[string]string)
+	for id, pconn := range profile.Connections {
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return nil, err
+		}
+		conn, ok := conns[id]
+		active := ok && !conn.Undesired && !conn.HotplugGone
+		if pconn.Undesired {
+			switch {
+			case !ok:
+				undesired[id] = pconn.Interface
+			case active && strategy != ImportMerge:
+				toDisconnect = append(toDisconnect, connRef)
+			}
+			continue
+		}
+		if active || (ok && conn.Undesired && strategy == ImportMerge) {
+			continue
+		}
+		plug := repo.Plug(connRef.PlugRef.Snap, connRef.PlugRef.Name)
+		slot := repo.Slot(connRef.SlotRef.Snap, connRef.SlotRef.Name)
+		if plug == nil || slot == nil {
+			pending[id] = pconn.Interface
+			continue
+		}
+		if plug.Interface != pconn.Interface || slot.Interface != pconn.Interface {
+			return nil, fmt.Errorf("cannot import connection %s: interface is not %q", id, pconn.Interface)
+		}
+		toConnect = append(toConnect, connRef)
+	}
+	if strategy == ImportReplace {
+		for id, conn := range conns {
+			if conn.Undesired || conn.HotplugGone {
+				continue
+			}
+			if _, ok := profile.Connections[id]; ok {
+				continue
+			}
+			connRef, err := interfaces.ParseConnRef(id)
+			if err != nil {
+				return nil, err
+			}
+			toDisconnect = append(toDisconnect, connRef)
+		}
+	}
+	sort.Sort(byConnRefID(toDisconnect))
+	sort.Sort(byConnRefID(toConnect))
+
+	var snapNames []string
+	for _, connRef := range append(toDisconnect, toConnect...) {
+		snapNames = append(snapNames, connRef.PlugRef.Snap, connRef.SlotRef.Snap)
+	}
+	if err := snapstate.CheckChangeConflictMany(st, snapNames, ""); err != nil {
+		return nil, err
+	}
+
+	ts := state.NewTaskSet()
+	if len(undesired) > 0 || len(pending) > 0 {
+		importConns := st.NewTask("import-conns", i18n.G("Record undesired and pending connections of the profile"))
+		importConns.Set("undesired", undesired)
+		importConns.Set("pending", pending)
+		ts.AddTask(importConns)
+	}
+	for _, connRef := range toDisconnect {
+		conn, err := repo.Connection(connRef)
+		if err != nil {
+			ts.AddAll(forgetTasks(st, connRef))
+			continue
+		}
+		disconnectTs, err := disconnectTasks(st, conn, disconnectOpts{})
+		if err != nil {
+			return nil, err
+		}
+		ts.AddAll(disconnectTs)
+	}
+	for _, connRef := range toConnect {
+		connectTs, err := connect(st, connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name, connectOpts{})
+		if err != nil {
+			return nil, err
+		}
+		ts.AddAll(connectTs)
+	}
+
+	return ts, nil
+}
+
+// ApplyConnectionProfile returns the tasks establishing the connections
+// listed by the given connection-profile assertion and disconnecting the
+// connections it forbids. The assertion must be for the model of the
+// device, connections it does not mention are kept as they are. The
+// connections are established as manual ones, subject to the usual
+// policy checks. Once the assertion is in the assertion database, the
+// connections it forbids cannot be connected manually either.
+func ApplyConnectionProfile(st *state.State, repo *interfaces.Repository, prof *asserts.ConnectionProfile, deviceCtx snapstate.DeviceContext) (*state.TaskSet, error) {
+	model := deviceCtx.Model()
+	if prof.BrandID() != model.BrandID() || prof.Model() != model.Model() {
+		return nil, fmt.Errorf("cannot apply connection profile %q for model %s/%s to a device of model %s/%s", prof.Name(), prof.BrandID(), prof.Model(), model.BrandID(), model.Model())
+	}
+
+	profile := &ConnectionsProfile{
+		Connections: make(map[string]*ProfileConnection, len(prof.Connect())+len(prof.Forbid())),
+	}
+	for _, entry := range prof.Connect() {
+		profile.Connections[entry.ID()] = &ProfileConnection{Interface: entry.Interface}
+	}
+	for _, entry := range prof.Forbid() {
+		profile.Connections[entry.ID()] = &ProfileConnection{Interface: entry.Interface, Undesired: true}
+	}
+	var err error
+	profile.Checksum, err = profile.checksum()
+	if err != nil {
+		return nil, err
+	}
+	return ImportConnections(st, repo, profile, ImportEnforce)
+}
@@ -0,0 +1,360 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+var profileConns = map[string]interface{}{
+	"consumer:plug producer:slot":      map[string]interface{}{"interface": "test"},
+	"consumer:otherplug gone:slot":     map[string]interface{}{"interface": "test2"},
+	"another:plug producer:slot":       map[string]interface{}{"interface": "test", "auto": true, "undesired": true},
+	"consumer:plug core:hotplugslot":   map[string]interface{}{"interface": "test", "hotplug-key": "1234", "hotplug-gone": true},
+	"another:otherplug producer:slot2": map[string]interface{}{"interface": "test", "auto": true},
+}
+
+// mockConnectionsProfile exports a profile of the given connections and
+// replaces them with the local ones.
+func (s *interfaceManagerSuite) mockConnectionsProfile(c *C, conns, local map[string]interface{}) *ifacestate.ConnectionsProfile {
+	s.state.Set("conns", conns)
+	profile, err := ifacestate.ExportConnections(s.state)
+	c.Assert(err, IsNil)
+	s.state.Set("conns", local)
+	return profile
+}
+
+func (s *interfaceManagerSuite) TestExportConnections(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.state.Set("conns", profileConns)
+	profile, err := ifacestate.ExportConnections(s.state)
+	c.Assert(err, IsNil)
+	c.Check(profile.Connections, DeepEquals, map[string]*ifacestate.ProfileConnection{
+		"consumer:plug producer:slot":      {Interface: "test"},
+		"consumer:otherplug gone:slot":     {Interface: "test2"},
+		"another:plug producer:slot":       {Interface: "test", Auto: true, Undesired: true},
+		"another:otherplug producer:slot2": {Interface: "test", Auto: true},
+	})
+	c.Check(profile.Checksum, HasLen, 96)
+}
+
+func (s *interfaceManagerSuite) TestImportConnectionsMerge(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	profile := s.mockConnectionsProfile(c, profileConns, nil)
+	ts, err := ifacestate.ImportConnections(s.state, repo, profile, ifacestate.ImportMerge)
+	c.Assert(err, IsNil)
+	c.Check(connectionTasksSummary(c, ts), DeepEquals, []string{
+		"connect consumer:plug producer:slot auto:false",
+	})
+
+	// nothing is recorded until the change runs
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, HasLen, 0)
+	var pending map[string]interface{}
+	c.Assert(s.state.Get("pending-conns", &pending), Equals, state.ErrNoState)
+
+	chg := s.state.NewChange("import", "")
+	chg.AddTask(importConnsTask(c, ts))
+	s.state.Unlock()
+	s.settle(c)
+	s.state.Lock()
+	c.Assert(chg.Status(), Equals, state.DoneStatus)
+
+	// the disconnected automatic connection is remembered
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]interface{}{
+		"another:plug producer:slot": map[string]interface{}{"interface": "test", "undesired": true},
+	})
+	// the connections of missing snaps are pending
+	c.Assert(s.state.Get("pending-conns", &pending), IsNil)
+	c.Check(pending, DeepEquals, map[string]interface{}{
+		"consumer:otherplug gone:slot":     "test2",
+		"another:otherplug producer:slot2": "test",
+	})
+}
+
+// importConnsTask returns the import-conns task of the given task set.
+func importConnsTask(c *C, ts *state.TaskSet) *state.Task {
+	for _, t := range ts.Tasks() {
+		if t.Kind() == "import-conns" {
+			return t
+		}
+	}
+	c.Fatalf("no import-conns task")
+	return nil
+}
+
+func (s *interfaceManagerSuite) TestImportConnectionsUndo(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	profile := s.mockConnectionsProfile(c, map[string]interface{}{
+		"another:plug producer:slot":   map[string]interface{}{"interface": "test", "undesired": true},
+		"consumer:otherplug gone:slot": map[string]interface{}{"interface": "test2"},
+		"another:plug gone:slot":       map[string]interface{}{"interface": "test"},
+	}, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Set("pending-conns", map[string]interface{}{
+		"consumer:otherplug gone:slot": "test",
+		"another:plug other:slot":      "test",
+	})
+
+	ts, err := ifacestate.ImportConnections(s.state, repo, profile, ifacestate.ImportMerge)
+	c.Assert(err, IsNil)
+	c.Assert(ts.Tasks(), HasLen, 1)
+	t := importConnsTask(c, ts)
+
+	chg := s.state.NewChange("import", "")
+	chg.AddAll(ts)
+	terr := s.state.NewTask("error-trigger", "provoking undo")
+	terr.WaitFor(t)
+	chg.AddTask(terr)
+	s.state.Unlock()
+	s.settle(c)
+	s.state.Lock()
+	c.Assert(chg.Status(), Equals, state.ErrorStatus)
+	c.Assert(t.Status(), Equals, state.UndoneStatus)
+
+	// the connections of the device are unchanged
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, DeepEquals, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	var pending map[string]interface{}
+	c.Assert(s.state.Get("pending-conns", &pending), IsNil)
+	c.Check(pending, DeepEquals, map[string]interface{}{
+		"consumer:otherplug gone:slot": "test",
+		"another:plug other:slot":      "test",
+	})
+}
+
+func (s *interfaceManagerSuite) TestImportConnectionsIgnoresProvenance(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	profile := s.mockConnectionsProfile(c, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test", "auto": true, "by-gadget": true},
+	}, nil)
+	ts, err := ifacestate.ImportConnections(s.state, repo, profile, ifacestate.ImportMerge)
+	c.Assert(err, IsNil)
+	// established as a manual connection
+	c.Check(connectionTasksSummary(c, ts), DeepEquals, []string{
+		"connect consumer:plug producer:slot auto:false",
+	})
+	for _, t := range ts.Tasks() {
+		if t.Kind() != "connect" {
+			continue
+		}
+		var byGadget bool
+		c.Check(t.Get("by-gadget", &byGadget), Equals, state.ErrNoState)
+	}
+}
+
+func (s *interfaceManagerSuite) TestImportConnectionsConflicts(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	s.mockSnap(c, producer2Yaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test", "auto": true},
+	})
+	s.state.Unlock()
+
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	profile := s.mockConnectionsProfile(c, map[string]interface{}{
+		"consumer:plug producer:slot":  map[string]interface{}{"interface": "test", "auto": true, "undesired": true},
+		"consumer:plug producer2:slot": map[string]interface{}{"interface": "test"},
+	}, map[string]interface{}{
+		"consumer:plug producer:slot":  map[string]interface{}{"interface": "test", "auto": true},
+		"consumer:plug producer2:slot": map[string]interface{}{"interface": "test", "auto": true, "undesired": true},
+	})
+
+	// the device wins when merging
+	ts, err := ifacestate.ImportConnections(s.state, repo, profile, ifacestate.ImportMerge)
+	c.Assert(err, IsNil)
+	c.Check(ts.Tasks(), HasLen, 0)
+
+	// the profile wins when replacing
+	ts, err = ifacestate.ImportConnections(s.state, repo, profile, ifacestate.ImportReplace)
+	c.Assert(err, IsNil)
+	c.Check(connectionTasksSummary(c, ts), DeepEquals, []string{
+		"disconnect consumer:plug producer:slot auto:false",
+		"connect consumer:plug producer2:slot auto:false",
+	})
+}
+
+func (s *interfaceManagerSuite) TestImportConnectionsReplaceDisconnectsOthers(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+	s.state.Unlock()
+
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	profile := s.mockConnectionsProfile(c, nil, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test"},
+	})
+
+	ts, err := ifacestate.ImportConnections(s.state, repo, profile, ifacestate.ImportMerge)
+	c.Assert(err, IsNil)
+	c.Check(ts.Tasks(), HasLen, 0)
+
+	ts, err = ifacestate.ImportConnections(s.state, repo, profile, ifacestate.ImportReplace)
+	c.Assert(err, IsNil)
+	c.Check(connectionTasksSummary(c, ts), DeepEquals, []string{
+		"disconnect consumer:plug producer:slot auto:false",
+	})
+}
+
+func (s *interfaceManagerSuite) TestImportConnectionsErrors(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	repo := s.manager(c).Repository()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	profile := s.mockConnectionsProfile(c, map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{"interface": "test2"},
+	}, nil)
+	_, err := ifacestate.ImportConnections(s.state, repo, profile, ifacestate.ImportMerge)
+	c.Check(err, ErrorMatches, `cannot import connection consumer:plug producer:slot: interface is not "test2"`)
+
+	profile.Connections["consumer:plug producer:slot"].Interface = "test"
+	_, err = ifacestate.ImportConnections(s.state, repo, profile, ifacestate.ImportMerge)
+	c.Check(err, ErrorMatches, `cannot import connections profile: checksum mismatch`)
+
+	// nothing was changed
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, HasLen, 0)
+	var pending map[string]interface{}
+	c.Check(s.state.Get("pending-conns", &pending), Equals, state.ErrNoState)
+}
+
+func mockConnectionProfile(model string, connect, forbid []interface{}) *asserts.ConnectionProfile {
+	return assertstest.FakeAssertion(map[string]interface{}{
+		"type":         "connection-profile",
+		"authority-id": "my-brand",
+		"brand-id":     "my-brand",
+		"model":        model,
+		"name":         "default",
+		"connect":      connect,
+		"forbid":       forbid,
+		"timestamp":    time.Now().Format(time.RFC3339),
+	}).(*asserts.ConnectionProfile)
+}
+
+func (s *interfaceManagerSuite) TestApplyConnectionProfile(c *C) {
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	s.mockSnap(c, producer2Yaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot":   map[string]interface{}{"interface": "test"},
+		"another:plug producer:slot":    map[string]interface{}{"interface": "test"},
+		"consumer:plug producer2:slot2": map[string]interface{}{"interface": "test", "auto": true, "undesired": true},
+	})
+	s.state.Unlock()
+
+	repo := s.manager(c).Repository()
+	deviceCtx := s.TrivialDeviceContext(c, nil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	prof := mockConnectionProfile("my-model", []interface{}{
+		map[string]interface{}{"plug": "consumer:plug", "slot": "producer2:slot", "interface": "test"},
+		map[string]interface{}{"plug": "consumer:otherplug", "slot": "gone:slot", "interface": "test2"},
+	}, []interface{}{
+		map[string]interface{}{"plug": "consumer:plug", "slot": "producer:slot", "interface": "test"},
+	})
+	ts, err := ifacestate.ApplyConnectionProfile(s.state, repo, prof, deviceCtx)
+	c.Assert(err, IsNil)
+	// connections the profile does not mention are kept
+	c.Check(connectionTasksSummary(c, ts), DeepEquals, []string{
+		"disconnect consumer:plug producer:slot auto:false",
+		"connect consumer:plug producer2:slot auto:false",
+	})
+	var pending map[string]interface{}
+	c.Assert(importConnsTask(c, ts).Get("pending", &pending), IsNil)
+	c.Check(pending, DeepEquals, map[string]interface{}{
+		"consumer:otherplug gone:slot": "test2",
+	})
+}
+
+func (s *interfaceManagerSuite) TestApplyConnectionProfileWrongModel(c *C) {
+	repo := s.manager(c).Repository()
+	deviceCtx := s.TrivialDeviceContext(c, nil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	prof := mockConnectionProfile("other-model", nil, nil)
+	_, err := ifacestate.ApplyConnectionProfile(s.state, repo, prof, deviceCtx)
+	c.Check(err, ErrorMatches, `cannot apply connection profile "default" for model my-brand/other-model to a device of model my-brand/my-model`)
+}
@@ -21,6 +21,7 @@
 package overlord
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http"
@@ -287,11 +288,11 @@ func (o *Overlord) loadState(backend state.Backend, restartHandler restart.Handl
 		return s, nil
 	}
 
-	r, err := os.Open(dirs.SnapStateFile)
+	data, err := readStateFile(dirs.SnapStateFile)
 	if err != nil {
 		return nil, fmt.Errorf("cannot read the state file: %s", err)
 	}
-	defer r.Close()
+	r := bytes.NewReader(data)
 
 	var s *state.State
 	timings.Run(perfTimings, "read-state", "read snapd state from disk", func(tm timings.Measurer) {
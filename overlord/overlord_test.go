@@ -20,6 +20,9 @@
 package overlord_test
 
 import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -37,6 +40,7 @@ import (
 	"gopkg.in/tomb.v2"
 
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/auth"
@@ -865,6 +869,112 @@ func (ovs *overlordSuite) TestCheckpoint(c *C) {
 	c.Check(dirs.SnapStateFile, testutil.FileContains, `"mark":1`)
 }
 
+func (ovs *overlordSuite) TestCheckpointKeepsPreviousGeneration(c *C) {
+	o, err := overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	s := o.State()
+	s.Lock()
+	s.Set("mark", 1)
+	s.Unlock()
+	s.Lock()
+	s.Set("mark", 2)
+	s.Unlock()
+
+	c.Check(dirs.SnapStateFile, testutil.FileContains, `"mark":2`)
+	c.Check(dirs.SnapStateFile+".prev", testutil.FileContains, `"mark":1`)
+
+	// the checksum is stored with the data
+	data, err := ioutil.ReadFile(dirs.SnapStateFile)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Matches, `\{"sha3-384":"[0-9a-f]{96}",.*`)
+	orig := append([]byte("{"), data[len(`{"sha3-384":"`)+96+2:]...)
+	h := crypto.SHA3_384.New()
+	h.Write(orig)
+	c.Check(string(data[len(`{"sha3-384":"`):len(`{"sha3-384":"`)+96]), Equals, hex.EncodeToString(h.Sum(nil)))
+	c.Check(json.Valid(data), Equals, true)
+}
+
+func (ovs *overlordSuite) TestNewWithCorruptStateUsesPreviousGeneration(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	o, err := overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	s := o.State()
+	s.Lock()
+	s.Set("mark", 1)
+	s.Unlock()
+	s.Lock()
+	s.Set("mark", 2)
+	s.Unlock()
+	o.Stop()
+
+	// the data no longer matches its checksum
+	data, err := ioutil.ReadFile(dirs.SnapStateFile)
+	c.Assert(err, IsNil)
+	data = bytes.Replace(data, []byte(`"mark":2`), []byte(`"mark":3`), 1)
+	c.Assert(ioutil.WriteFile(dirs.SnapStateFile, data, 0600), IsNil)
+
+	o, err = overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	s = o.State()
+	s.Lock()
+	var mark int
+	c.Check(s.Get("mark", &mark), IsNil)
+	s.Unlock()
+	c.Check(mark, Equals, 1)
+	c.Check(logbuf.String(), Matches, `(?s).*WARNING: cannot use state file .*: checksum mismatch, using the previous generation from .*\.prev\n.*`)
+}
+
+func (ovs *overlordSuite) TestNewWithCorruptStateAndPreviousFails(c *C) {
+	o, err := overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	s := o.State()
+	s.Lock()
+	s.Set("mark", 1)
+	s.Unlock()
+	s.Lock()
+	s.Set("mark", 2)
+	s.Unlock()
+	o.Stop()
+
+	for _, path := range []string{dirs.SnapStateFile, dirs.SnapStateFile + ".prev"} {
+		data, err := ioutil.ReadFile(path)
+		c.Assert(err, IsNil)
+		data = bytes.Replace(data, []byte(`"mark":`), []byte(`"mark":1`), 1)
+		c.Assert(ioutil.WriteFile(path, data, 0600), IsNil)
+	}
+
+	_, err = overlord.New(nil)
+	c.Assert(err, ErrorMatches, `cannot read the state file: checksum mismatch, and cannot use the previous generation: checksum mismatch`)
+}
+
+func (ovs *overlordSuite) TestNewWithCorruptStateWithoutPreviousFails(c *C) {
+	c.Assert(ioutil.WriteFile(dirs.SnapStateFile, []byte(`{"sha3-384":"0123","data":{"mark":5}}`), 0600), IsNil)
+
+	_, err := overlord.New(nil)
+	c.Assert(err, ErrorMatches, `cannot read the state file: checksum mismatch, and cannot use the previous generation: open .*\.prev: no such file or directory`)
+}
+
+func (ovs *overlordSuite) TestNewWithStateWithoutChecksum(c *C) {
+	// as written by older snapd versions
+	c.Assert(ioutil.WriteFile(dirs.SnapStateFile, []byte(`{"data":{"mark":5}}`), 0600), IsNil)
+
+	o, err := overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	s := o.State()
+	s.Lock()
+	defer s.Unlock()
+	var mark int
+	c.Check(s.Get("mark", &mark), IsNil)
+	c.Check(mark, Equals, 5)
+}
+
 type sampleManager struct {
 	ensureCallback func()
 }
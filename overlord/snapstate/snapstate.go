@@ -2705,7 +2705,8 @@ func canRemove(st *state.State, si *snap.Info, snapst *SnapState, removeAll bool
 
 // RemoveFlags are used to pass additional flags to the Remove operation.
 type RemoveFlags struct {
-	// Remove the snap without creating snapshot data
+	// Remove the snap without creating snapshot data and without
+	// retaining its manual connections for a later reinstall
 	Purge bool
 }
 
@@ -2832,6 +2833,9 @@ func removeTasks(st *state.State, name string, revision snap.Revision, flags *Re
 		// run disconnect hooks
 		disconnect := st.NewTask("auto-disconnect", fmt.Sprintf(i18n.G("Disconnect interfaces of snap %q"), snapsup.InstanceName()))
 		disconnect.Set("snap-setup", snapsup)
+		if flags != nil && flags.Purge {
+			disconnect.Set("purge", true)
+		}
 		if prev != nil {
 			disconnect.WaitFor(prev)
 		}
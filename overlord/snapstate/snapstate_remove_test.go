@@ -89,6 +89,9 @@ func (s *snapmgrTestSuite) TestRemoveTasksAutoSnapshotDisabled(c *C) {
 		"clear-snap",
 		"discard-snap",
 	})
+
+	var purge bool
+	c.Check(ts.Tasks()[2].Get("purge", &purge), Equals, state.ErrNoState)
 }
 
 func (s *snapmgrTestSuite) TestRemoveTasksAutoSnapshotDisabledByPurgeFlag(c *C) {
@@ -117,6 +120,11 @@ func (s *snapmgrTestSuite) TestRemoveTasksAutoSnapshotDisabledByPurgeFlag(c *C)
 		"clear-snap",
 		"discard-snap",
 	})
+
+	// manual connections are not retained for a reinstall either
+	var purge bool
+	c.Assert(ts.Tasks()[2].Get("purge", &purge), IsNil)
+	c.Check(purge, Equals, true)
 }
 
 func (s *snapmgrTestSuite) TestRemoveHookNotExecutedIfNotLastRevison(c *C) {
@@ -0,0 +1,63 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build gofuzz
+// +build gofuzz
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snap
+
+// The functions in this file are entry points for go-fuzz, they are only
+// built with the gofuzz tag. Seed corpora live in testdata/fuzz, e.g.:
+//
+//   go-fuzz-build -func FuzzValidateName github.com/snapcore/snapd/snap
+//   go-fuzz -bin snap-fuzz.zip -workdir testdata/fuzz/name
+//
+// Following the go-fuzz convention the functions return 1 when the input
+// was accepted, to make the fuzzer prefer it, and 0 otherwise.
+
+func fuzzResult(err error) int {
+	if err != nil {
+		return 0
+	}
+	return 1
+}
+
+// FuzzValidateName exercises the snap name validation.
+func FuzzValidateName(data []byte) int {
+	return fuzzResult(ValidateName(string(data)))
+}
+
+// FuzzValidateInstanceName exercises the snap instance name validation.
+func FuzzValidateInstanceName(data []byte) int {
+	return fuzzResult(ValidateInstanceName(string(data)))
+}
+
+// FuzzValidatePlugSlotName exercises the validation of plug, slot and
+// interface names, which share most of their rules.
+func FuzzValidatePlugSlotName(data []byte) int {
+	name := string(data)
+	res := fuzzResult(ValidatePlugName(name))
+	res |= fuzzResult(ValidateSlotName(name))
+	res |= fuzzResult(ValidateInterfaceName(name))
+	return res
+}
+
+// FuzzValidateVersion exercises the snap version validation.
+func FuzzValidateVersion(data []byte) int {
+	return fuzzResult(ValidateVersion(string(data)))
+}
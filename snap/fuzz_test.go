@@ -0,0 +1,62 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//go:build gofuzz
+// +build gofuzz
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snap_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/snap"
+)
+
+type fuzzSuite struct{}
+
+var _ = Suite(&fuzzSuite{})
+
+func runCorpus(c *C, dir string, fuzz func([]byte) int) (accepted int) {
+	seeds, err := filepath.Glob(filepath.Join("testdata/fuzz", dir, "corpus/*"))
+	c.Assert(err, IsNil)
+	c.Assert(seeds, Not(HasLen), 0)
+	for _, seed := range seeds {
+		data, err := ioutil.ReadFile(seed)
+		c.Assert(err, IsNil)
+		accepted += fuzz(data)
+	}
+	return accepted
+}
+
+func (s *fuzzSuite) TestCorpora(c *C) {
+	// the seeds mix valid and invalid input
+	for dir, fuzz := range map[string]func([]byte) int{
+		"name":           snap.FuzzValidateName,
+		"instance-name":  snap.FuzzValidateInstanceName,
+		"plug-slot-name": snap.FuzzValidatePlugSlotName,
+		"version":        snap.FuzzValidateVersion,
+	} {
+		accepted := runCorpus(c, dir, fuzz)
+		c.Check(accepted > 0, Equals, true, Commentf("%s", dir))
+	}
+	c.Check(snap.FuzzValidateName([]byte("hello-world")), Equals, 1)
+	c.Check(snap.FuzzValidateName([]byte("hello--world")), Equals, 0)
+}
@@ -295,6 +295,10 @@ type Info struct {
 	// Plugs or slots with issues (they are not included in Plugs or Slots)
 	BadInterfaces map[string]string // slot or plug => message
 
+	// Notes on the migration of legacy caps and security-template
+	// declarations of apps to plugs
+	LegacySecurityNotes []string
+
 	// The information in all the remaining fields is not sourced from the snap
 	// blob itself.
 	SideInfo
@@ -1137,7 +1141,7 @@ func (hook *HookInfo) EnvChain() []osutil.ExpandableEnv {
 }
 
 func infoFromSnapYamlWithSideInfo(meta []byte, si *SideInfo, strk *scopedTracker) (*Info, error) {
-	info, err := infoFromSnapYaml(meta, strk)
+	info, err := infoFromSnapYaml(meta, strk, SanitizePlugsSlots)
 	if err != nil {
 		return nil, err
 	}
@@ -27,6 +27,7 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
 
 	"github.com/snapcore/snapd/metautil"
 	"github.com/snapcore/snapd/strutil"
@@ -106,6 +107,12 @@ type appYaml struct {
 	Timer string `yaml:"timer,omitempty"`
 
 	Autostart string `yaml:"autostart,omitempty"`
+
+	// legacy security declarations, migrated to plugs
+	LegacyCaps             interface{} `yaml:"caps,omitempty"`
+	LegacySecurityTemplate interface{} `yaml:"security-template,omitempty"`
+	LegacySecurityPolicy   interface{} `yaml:"security-policy,omitempty"`
+	LegacySecurityOverride interface{} `yaml:"security-override,omitempty"`
 }
 
 type hookYaml struct {
@@ -132,7 +139,13 @@ type socketsYaml struct {
 
 // InfoFromSnapYaml creates a new info based on the given snap.yaml data
 func InfoFromSnapYaml(yamlData []byte) (*Info, error) {
-	return infoFromSnapYaml(yamlData, new(scopedTracker))
+	return infoFromSnapYaml(yamlData, new(scopedTracker), SanitizePlugsSlots)
+}
+
+// InfoFromSnapYamlWithSanitizer is like InfoFromSnapYaml but sanitizes the
+// plugs and slots with the given function instead of SanitizePlugsSlots.
+func InfoFromSnapYamlWithSanitizer(yamlData []byte, sanitize func(snapInfo *Info)) (*Info, error) {
+	return infoFromSnapYaml(yamlData, new(scopedTracker), sanitize)
 }
 
 // scopedTracker helps keeping track of which slots/plugs are scoped
@@ -163,7 +176,7 @@ func (strk *scopedTracker) slot(slot *SlotInfo) bool {
 	return strk.slots[slot]
 }
 
-func infoFromSnapYaml(yamlData []byte, strk *scopedTracker) (*Info, error) {
+func infoFromSnapYaml(yamlData []byte, strk *scopedTracker, sanitize func(snapInfo *Info)) (*Info, error) {
 	var y snapYaml
 	// Customize hints for the typo detector.
 	y.TypoLayouts.Hint = `use singular "layout" instead of plural "layouts"`
@@ -175,11 +188,12 @@ func infoFromSnapYaml(yamlData []byte, strk *scopedTracker) (*Info, error) {
 	snap := infoSkeletonFromSnapYaml(y)
 
 	// Collect top-level definitions of plugs and slots
-	if err := setPlugsFromSnapYaml(y, snap); err != nil {
-		return nil, err
+	var binds appBindings
+	if err := setPlugsFromSnapYaml(y, snap, &binds); err != nil {
+		return nil, withDefinitionLine(yamlData, err)
 	}
-	if err := setSlotsFromSnapYaml(y, snap); err != nil {
-		return nil, err
+	if err := setSlotsFromSnapYaml(y, snap, &binds); err != nil {
+		return nil, withDefinitionLine(yamlData, err)
 	}
 
 	strk.init(len(y.Apps) + len(y.Hooks))
@@ -188,6 +202,10 @@ func infoFromSnapYaml(yamlData []byte, strk *scopedTracker) (*Info, error) {
 	if err := setAppsFromSnapYaml(y, snap, strk); err != nil {
 		return nil, err
 	}
+	// Bind plugs and slots to the apps listed in their definitions.
+	if err := bindAppsFromDefinitions(snap, &binds, strk); err != nil {
+		return nil, withDefinitionLine(yamlData, err)
+	}
 	setHooksFromSnapYaml(y, snap, strk)
 
 	// Bind plugs and slots that are not scoped to all known apps and hooks.
@@ -226,7 +244,7 @@ func infoFromSnapYaml(yamlData []byte, strk *scopedTracker) (*Info, error) {
 	snap.renameClashingCorePlugs()
 
 	snap.BadInterfaces = make(map[string]string)
-	SanitizePlugsSlots(snap)
+	sanitize(snap)
 
 	// Collect system usernames
 	if err := setSystemUsernamesFromSnapYaml(y, snap); err != nil {
@@ -299,11 +317,81 @@ func infoSkeletonFromSnapYaml(y snapYaml) *Info {
 	return snap
 }
 
-func setPlugsFromSnapYaml(y snapYaml, snap *Info) error {
+// definitionError is an error in the definition of a top-level plug or
+// slot.
+type definitionError struct {
+	// section is either "plugs" or "slots"
+	section string
+	name    string
+	err     error
+}
+
+func (e *definitionError) Error() string {
+	return e.err.Error()
+}
+
+// withDefinitionLine prefixes the error in the definition of a plug or slot
+// with the line of snap.yaml the plug or slot is defined on, when it can be
+// found.
+func withDefinitionLine(yamlData []byte, err error) error {
+	defErr, ok := err.(*definitionError)
+	if !ok {
+		return err
+	}
+	line := definitionLine(yamlData, defErr.section, defErr.name)
+	if line == 0 {
+		return defErr.err
+	}
+	return fmt.Errorf("line %d: %v", line, defErr.err)
+}
+
+// definitionLine returns the line of the key naming the given entry of a
+// top-level section of snap.yaml, or 0 if it cannot be found.
+func definitionLine(yamlData []byte, section, name string) int {
+	// yaml.v2 does not keep track of positions, use yaml.v3 to find them
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal(yamlData, &doc); err != nil || len(doc.Content) == 0 {
+		return 0
+	}
+	top := doc.Content[0]
+	if top.Kind != yaml3.MappingNode {
+		return 0
+	}
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		if top.Content[i].Value != section {
+			continue
+		}
+		entries := top.Content[i+1]
+		if entries.Kind != yaml3.MappingNode {
+			return 0
+		}
+		for j := 0; j+1 < len(entries.Content); j += 2 {
+			if entries.Content[j].Value == name {
+				return entries.Content[j].Line
+			}
+		}
+	}
+	return 0
+}
+
+// appBindings records the apps listed in the "apps" attribute of top-level
+// plug and slot definitions.
+type appBindings struct {
+	plugs map[string][]string
+	slots map[string][]string
+}
+
+func setPlugsFromSnapYaml(y snapYaml, snap *Info, binds *appBindings) error {
 	for name, data := range y.Plugs {
-		iface, label, attrs, err := convertToSlotOrPlugData("plug", name, data)
+		iface, label, apps, attrs, err := convertToSlotOrPlugData("plug", name, data)
 		if err != nil {
-			return err
+			return &definitionError{section: "plugs", name: name, err: err}
+		}
+		if apps != nil {
+			if binds.plugs == nil {
+				binds.plugs = make(map[string][]string)
+			}
+			binds.plugs[name] = apps
 		}
 		snap.Plugs[name] = &PlugInfo{
 			Snap:      snap,
@@ -323,11 +411,17 @@ func setPlugsFromSnapYaml(y snapYaml, snap *Info) error {
 	return nil
 }
 
-func setSlotsFromSnapYaml(y snapYaml, snap *Info) error {
+func setSlotsFromSnapYaml(y snapYaml, snap *Info, binds *appBindings) error {
 	for name, data := range y.Slots {
-		iface, label, attrs, err := convertToSlotOrPlugData("slot", name, data)
+		iface, label, apps, attrs, err := convertToSlotOrPlugData("slot", name, data)
 		if err != nil {
-			return err
+			return &definitionError{section: "slots", name: name, err: err}
+		}
+		if apps != nil {
+			if binds.slots == nil {
+				binds.slots = make(map[string][]string)
+			}
+			binds.slots[name] = apps
 		}
 		snap.Slots[name] = &SlotInfo{
 			Snap:      snap,
@@ -377,7 +471,15 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 			Autostart:       yApp.Autostart,
 			WatchdogTimeout: yApp.WatchdogTimeout,
 		}
-		if len(y.Plugs) > 0 || len(yApp.PlugNames) > 0 {
+		plugNames := yApp.PlugNames
+		legacyPlugNames, notes := migrateLegacySecurity(appName, &yApp)
+		for _, plugName := range legacyPlugNames {
+			if !strutil.ListContains(plugNames, plugName) {
+				plugNames = append(plugNames, plugName)
+			}
+		}
+		snap.LegacySecurityNotes = append(snap.LegacySecurityNotes, notes...)
+		if len(y.Plugs) > 0 || len(plugNames) > 0 {
 			app.Plugs = make(map[string]*PlugInfo)
 		}
 		if len(y.Slots) > 0 || len(yApp.SlotNames) > 0 {
@@ -402,7 +504,7 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 			snap.LegacyAliases[alias] = app
 		}
 		// Bind all plugs/slots listed in this app
-		for _, plugName := range yApp.PlugNames {
+		for _, plugName := range plugNames {
 			plug, ok := snap.Plugs[plugName]
 			if !ok {
 				// Create implicit plug definitions if required
@@ -419,6 +521,19 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 			app.Plugs[plugName] = plug
 			plug.Apps[appName] = app
 		}
+		policyPlug, note := legacySecurityPolicyPlug(snap, appName, &yApp)
+		if note != "" {
+			snap.LegacySecurityNotes = append(snap.LegacySecurityNotes, note)
+		}
+		if policyPlug != nil {
+			if app.Plugs == nil {
+				app.Plugs = make(map[string]*PlugInfo)
+			}
+			snap.Plugs[policyPlug.Name] = policyPlug
+			strk.markPlug(policyPlug)
+			app.Plugs[policyPlug.Name] = policyPlug
+			policyPlug.Apps[appName] = app
+		}
 		for _, slotName := range yApp.SlotNames {
 			slot, ok := snap.Slots[slotName]
 			if !ok {
@@ -569,6 +684,37 @@ func setLinksFromSnapYaml(y snapYaml, snap *Info) error {
 	return nil
 }
 
+// bindAppsFromDefinitions binds plugs and slots to the apps listed in their
+// top-level definitions. Plugs and slots bound this way are scoped to those
+// apps only.
+func bindAppsFromDefinitions(snap *Info, binds *appBindings, strk *scopedTracker) error {
+	for plugName, appNames := range binds.plugs {
+		plug := snap.Plugs[plugName]
+		strk.markPlug(plug)
+		for _, appName := range appNames {
+			app, ok := snap.Apps[appName]
+			if !ok {
+				return &definitionError{section: "plugs", name: plugName, err: fmt.Errorf("plug %q is bound to unknown app %q", plugName, appName)}
+			}
+			app.Plugs[plugName] = plug
+			plug.Apps[appName] = app
+		}
+	}
+	for slotName, appNames := range binds.slots {
+		slot := snap.Slots[slotName]
+		strk.markSlot(slot)
+		for _, appName := range appNames {
+			app, ok := snap.Apps[appName]
+			if !ok {
+				return &definitionError{section: "slots", name: slotName, err: fmt.Errorf("slot %q is bound to unknown app %q", slotName, appName)}
+			}
+			app.Slots[slotName] = slot
+			slot.Apps[appName] = app
+		}
+	}
+	return nil
+}
+
 func bindUnscopedPlugs(snap *Info, strk *scopedTracker) {
 	for plugName, plug := range snap.Plugs {
 		if strk.plug(plug) {
@@ -637,60 +783,148 @@ func bindImplicitHooks(snap *Info, strk *scopedTracker) {
 	}
 }
 
-func convertToSlotOrPlugData(plugOrSlot, name string, data interface{}) (iface, label string, attrs map[string]interface{}, err error) {
+// InterfaceSchemaVersion is the most recent version of the schema of plug
+// and slot definitions understood by this snapd. Definitions declare the
+// version they were written for with the "$schema" key, the default is 1.
+// The key is "$"-prefixed like the other reserved keys, so that "schema"
+// remains available as an attribute.
+const InterfaceSchemaVersion = 1
+
+// definitionSchema returns the schema version declared by a plug or slot
+// definition.
+func definitionSchema(plugOrSlot, name string, data map[interface{}]interface{}) (int, error) {
+	schemaData, ok := data["$schema"]
+	if !ok {
+		return 1, nil
+	}
+	schema, ok := schemaData.(int)
+	if !ok || schema < 1 {
+		return 0, fmt.Errorf("schema of %s %q is not a positive integer (found %v)", plugOrSlot, name, schemaData)
+	}
+	return schema, nil
+}
+
+func convertToSlotOrPlugData(plugOrSlot, name string, data interface{}) (iface, label string, apps []string, attrs map[string]interface{}, err error) {
 	iface = name
 	switch data.(type) {
 	case string:
-		return data.(string), "", nil, nil
+		return data.(string), "", nil, nil, nil
 	case nil:
-		return name, "", nil, nil
+		return name, "", nil, nil, nil
 	case map[interface{}]interface{}:
+		schema, err := definitionSchema(plugOrSlot, name, data.(map[interface{}]interface{}))
+		if err != nil {
+			return "", "", nil, nil, err
+		}
+		// Definitions written for a newer schema are parsed leniently so
+		// that snaps using it still install: fields this snapd does not
+		// understand are preserved as attributes when they can be, and
+		// dropped otherwise.
+		lenient := schema > InterfaceSchemaVersion
+		addAttr := func(key string, valueData interface{}) error {
+			value, err := metautil.NormalizeValue(valueData)
+			if err != nil {
+				if lenient {
+					return nil
+				}
+				return fmt.Errorf("attribute %q of %s %q: %v", key, plugOrSlot, name, err)
+			}
+			if attrs == nil {
+				attrs = make(map[string]interface{})
+			}
+			attrs[key] = value
+			return nil
+		}
 		for keyData, valueData := range data.(map[interface{}]interface{}) {
 			key, ok := keyData.(string)
 			if !ok {
+				if lenient {
+					continue
+				}
 				err := fmt.Errorf("%s %q has attribute key that is not a string (found %T)",
 					plugOrSlot, name, keyData)
-				return "", "", nil, err
+				return "", "", nil, nil, err
+			}
+			if key == "$schema" {
+				// already handled
+				continue
 			}
 			if strings.HasPrefix(key, "$") {
+				if lenient {
+					continue
+				}
 				err := fmt.Errorf("%s %q uses reserved attribute %q", plugOrSlot, name, key)
-				return "", "", nil, err
+				return "", "", nil, nil, err
 			}
 			switch key {
 			case "":
-				return "", "", nil, fmt.Errorf("%s %q has an empty attribute key", plugOrSlot, name)
+				if lenient {
+					continue
+				}
+				return "", "", nil, nil, fmt.Errorf("%s %q has an empty attribute key", plugOrSlot, name)
 			case "interface":
 				value, ok := valueData.(string)
 				if !ok {
 					err := fmt.Errorf("interface name on %s %q is not a string (found %T)",
 						plugOrSlot, name, valueData)
-					return "", "", nil, err
+					return "", "", nil, nil, err
 				}
 				iface = value
 			case "label":
 				value, ok := valueData.(string)
 				if !ok {
+					if lenient {
+						if err := addAttr(key, valueData); err != nil {
+							return "", "", nil, nil, err
+						}
+						continue
+					}
 					err := fmt.Errorf("label of %s %q is not a string (found %T)",
 						plugOrSlot, name, valueData)
-					return "", "", nil, err
+					return "", "", nil, nil, err
 				}
 				label = value
-			default:
-				if attrs == nil {
-					attrs = make(map[string]interface{})
-				}
-				value, err := metautil.NormalizeValue(valueData)
+			case "apps":
+				appNames, err := definitionApps(plugOrSlot, name, valueData)
 				if err != nil {
-					return "", "", nil, fmt.Errorf("attribute %q of %s %q: %v", key, plugOrSlot, name, err)
+					if lenient {
+						if err := addAttr(key, valueData); err != nil {
+							return "", "", nil, nil, err
+						}
+						continue
+					}
+					return "", "", nil, nil, err
+				}
+				apps = appNames
+			default:
+				if err := addAttr(key, valueData); err != nil {
+					return "", "", nil, nil, err
 				}
-				attrs[key] = value
 			}
 		}
-		return iface, label, attrs, nil
+		return iface, label, apps, attrs, nil
 	default:
 		err := fmt.Errorf("%s %q has malformed definition (found %T)", plugOrSlot, name, data)
-		return "", "", nil, err
+		return "", "", nil, nil, err
+	}
+}
+
+// definitionApps returns the names of the apps listed in the "apps" field of
+// a plug or slot definition.
+func definitionApps(plugOrSlot, name string, data interface{}) ([]string, error) {
+	values, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("apps of %s %q is not a list of strings (found %T)", plugOrSlot, name, data)
+	}
+	apps := make([]string, 0, len(values))
+	for _, v := range values {
+		appName, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("apps of %s %q is not a list of strings (found %T)", plugOrSlot, name, v)
+		}
+		apps = append(apps, appName)
 	}
+	return apps, nil
 }
 
 // Short form:
@@ -65,6 +65,21 @@ func (s *InfoSnapYamlTestSuite) TestSimple(c *C) {
 	c.Assert(info.Epoch, DeepEquals, snap.E("0"))
 }
 
+func (s *InfoSnapYamlTestSuite) TestInfoFromSnapYamlWithSanitizer(c *C) {
+	restore := snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {
+		c.Errorf("unexpected call to snap.SanitizePlugsSlots")
+	})
+	defer restore()
+
+	var sanitized *snap.Info
+	info, err := snap.InfoFromSnapYamlWithSanitizer(mockYaml, func(snapInfo *snap.Info) {
+		c.Check(snapInfo.BadInterfaces, NotNil)
+		sanitized = snapInfo
+	})
+	c.Assert(err, IsNil)
+	c.Check(sanitized, Equals, info)
+}
+
 func (s *InfoSnapYamlTestSuite) TestSnapdTypeAddedByMagic(c *C) {
 	info, err := snap.InfoFromSnapYaml([]byte(`name: snapd
 version: 1.0`))
@@ -419,7 +434,7 @@ plugs:
         interface: 1.0
         ipv6-aware: true
 `))
-	c.Assert(err, ErrorMatches, `interface name on plug "net" is not a string \(found float64\)`)
+	c.Assert(err, ErrorMatches, `line 4: interface name on plug "net" is not a string \(found float64\)`)
 }
 
 func (s *YamlSuite) TestUnmarshalCorruptedPlugWithNonStringLabel(c *C) {
@@ -430,7 +445,7 @@ plugs:
     bool-file:
         label: 1.0
 `))
-	c.Assert(err, ErrorMatches, `label of plug "bool-file" is not a string \(found float64\)`)
+	c.Assert(err, ErrorMatches, `line 4: label of plug "bool-file" is not a string \(found float64\)`)
 }
 
 func (s *YamlSuite) TestUnmarshalCorruptedPlugWithNonStringAttributes(c *C) {
@@ -441,7 +456,7 @@ plugs:
     net:
         1: ok
 `))
-	c.Assert(err, ErrorMatches, `plug "net" has attribute key that is not a string \(found int\)`)
+	c.Assert(err, ErrorMatches, `line 4: plug "net" has attribute key that is not a string \(found int\)`)
 }
 
 func (s *YamlSuite) TestUnmarshalCorruptedPlugWithEmptyAttributeKey(c *C) {
@@ -452,7 +467,7 @@ plugs:
     net:
         "": ok
 `))
-	c.Assert(err, ErrorMatches, `plug "net" has an empty attribute key`)
+	c.Assert(err, ErrorMatches, `line 4: plug "net" has an empty attribute key`)
 }
 
 func (s *YamlSuite) TestUnmarshalCorruptedPlugWithUnexpectedType(c *C) {
@@ -462,7 +477,7 @@ name: snap
 plugs:
     net: 5
 `))
-	c.Assert(err, ErrorMatches, `plug "net" has malformed definition \(found int\)`)
+	c.Assert(err, ErrorMatches, `line 4: plug "net" has malformed definition \(found int\)`)
 }
 
 func (s *YamlSuite) TestUnmarshalReservedPlugAttribute(c *C) {
@@ -474,7 +489,7 @@ plugs:
         interface: serial-port
         $baud-rate: [9600]
 `))
-	c.Assert(err, ErrorMatches, `plug "serial" uses reserved attribute "\$baud-rate"`)
+	c.Assert(err, ErrorMatches, `line 4: plug "serial" uses reserved attribute "\$baud-rate"`)
 }
 
 func (s *YamlSuite) TestUnmarshalInvalidPlugAttribute(c *C) {
@@ -486,7 +501,7 @@ plugs:
         interface: serial-port
         foo: null
 `))
-	c.Assert(err, ErrorMatches, `attribute "foo" of plug \"serial\": invalid scalar:.*`)
+	c.Assert(err, ErrorMatches, `line 4: attribute "foo" of plug \"serial\": invalid scalar:.*`)
 }
 
 func (s *YamlSuite) TestUnmarshalInvalidAttributeMapKey(c *C) {
@@ -500,7 +515,7 @@ plugs:
           baz:
           - 1: A
 `))
-	c.Assert(err, ErrorMatches, `attribute "bar" of plug \"serial\": non-string key: 1`)
+	c.Assert(err, ErrorMatches, `line 4: attribute "bar" of plug \"serial\": non-string key: 1`)
 }
 
 // Tests focusing on slots
@@ -843,7 +858,7 @@ slots:
         interface: 1.0
         ipv6-aware: true
 `))
-	c.Assert(err, ErrorMatches, `interface name on slot "net" is not a string \(found float64\)`)
+	c.Assert(err, ErrorMatches, `line 4: interface name on slot "net" is not a string \(found float64\)`)
 }
 
 func (s *YamlSuite) TestUnmarshalCorruptedSlotWithNonStringLabel(c *C) {
@@ -854,7 +869,7 @@ slots:
     bool-file:
         label: 1.0
 `))
-	c.Assert(err, ErrorMatches, `label of slot "bool-file" is not a string \(found float64\)`)
+	c.Assert(err, ErrorMatches, `line 4: label of slot "bool-file" is not a string \(found float64\)`)
 }
 
 func (s *YamlSuite) TestUnmarshalCorruptedSlotWithNonStringAttributes(c *C) {
@@ -865,7 +880,7 @@ slots:
     net:
         1: ok
 `))
-	c.Assert(err, ErrorMatches, `slot "net" has attribute key that is not a string \(found int\)`)
+	c.Assert(err, ErrorMatches, `line 4: slot "net" has attribute key that is not a string \(found int\)`)
 }
 
 func (s *YamlSuite) TestUnmarshalCorruptedSlotWithEmptyAttributeKey(c *C) {
@@ -876,7 +891,7 @@ slots:
     net:
         "": ok
 `))
-	c.Assert(err, ErrorMatches, `slot "net" has an empty attribute key`)
+	c.Assert(err, ErrorMatches, `line 4: slot "net" has an empty attribute key`)
 }
 
 func (s *YamlSuite) TestUnmarshalCorruptedSlotWithUnexpectedType(c *C) {
@@ -886,7 +901,199 @@ name: snap
 slots:
     net: 5
 `))
-	c.Assert(err, ErrorMatches, `slot "net" has malformed definition \(found int\)`)
+	c.Assert(err, ErrorMatches, `line 4: slot "net" has malformed definition \(found int\)`)
+}
+
+func (s *YamlSuite) TestUnmarshalCorruptedSlotReportsLine(c *C) {
+	// NOTE: yaml content cannot use tabs, indent the section with spaces.
+	_, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+plugs:
+    net:
+        interface: network
+slots:
+    db:
+        interface: content
+    "logs":
+        "": ok
+`))
+	c.Assert(err, ErrorMatches, `line 9: slot "logs" has an empty attribute key`)
+}
+
+func (s *YamlSuite) TestPlugsAndSlotsBoundToAppsInDefinition(c *C) {
+	// NOTE: yaml content cannot use tabs, indent the section with spaces.
+	info, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+plugs:
+    net:
+        interface: network
+        apps: [client]
+    home:
+        interface: home
+slots:
+    db:
+        interface: content
+        apps: [server]
+        read: [$SNAP/db]
+apps:
+    client:
+        command: client
+    server:
+        command: server
+        plugs: [net]
+hooks:
+    configure:
+`))
+	c.Assert(err, IsNil)
+
+	client := info.Apps["client"]
+	server := info.Apps["server"]
+	net := info.Plugs["net"]
+	c.Check(net.Attrs, HasLen, 0)
+	c.Check(net.Apps, DeepEquals, map[string]*snap.AppInfo{"client": client, "server": server})
+	c.Check(net.Hooks, HasLen, 0)
+	db := info.Slots["db"]
+	c.Check(db.Attrs, DeepEquals, map[string]interface{}{"read": []interface{}{"$SNAP/db"}})
+	c.Check(db.Apps, DeepEquals, map[string]*snap.AppInfo{"server": server})
+	c.Check(client.Slots, HasLen, 0)
+	// plugs without apps in their definition are still bound to all apps
+	// and hooks
+	c.Check(info.Plugs["home"].Apps, HasLen, 2)
+	c.Check(info.Plugs["home"].Hooks, HasLen, 1)
+}
+
+func (s *YamlSuite) TestPlugsAndSlotsBoundToUnknownApp(c *C) {
+	// NOTE: yaml content cannot use tabs, indent the section with spaces.
+	_, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+plugs:
+    net:
+        interface: network
+        apps: [client]
+apps:
+    server:
+        command: server
+`))
+	c.Assert(err, ErrorMatches, `line 4: plug "net" is bound to unknown app "client"`)
+
+	_, err = snap.InfoFromSnapYaml([]byte(`
+name: snap
+slots:
+    db:
+        interface: content
+        apps: [server, client]
+apps:
+    server:
+        command: server
+`))
+	c.Assert(err, ErrorMatches, `line 4: slot "db" is bound to unknown app "client"`)
+}
+
+func (s *YamlSuite) TestUnmarshalCorruptedAppsOfPlug(c *C) {
+	// NOTE: yaml content cannot use tabs, indent the section with spaces.
+	_, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+plugs:
+    net:
+        interface: network
+        apps: client
+`))
+	c.Assert(err, ErrorMatches, `line 4: apps of plug "net" is not a list of strings \(found string\)`)
+
+	_, err = snap.InfoFromSnapYaml([]byte(`
+name: snap
+slots:
+    db:
+        apps: [1]
+`))
+	c.Assert(err, ErrorMatches, `line 4: apps of slot "db" is not a list of strings \(found int\)`)
+}
+
+func (s *YamlSuite) TestPlugsAndSlotsCurrentSchema(c *C) {
+	// NOTE: yaml content cannot use tabs, indent the section with spaces.
+	info, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+plugs:
+    net:
+        interface: network
+        $schema: 1
+        foo: bar
+        schema: attribute
+`))
+	c.Assert(err, IsNil)
+	c.Check(info.Plugs["net"].Interface, Equals, "network")
+	// "schema" is not reserved, it is a regular attribute
+	c.Check(info.Plugs["net"].Attrs, DeepEquals, map[string]interface{}{"foo": "bar", "schema": "attribute"})
+
+	_, err = snap.InfoFromSnapYaml([]byte(`
+name: snap
+plugs:
+    net:
+        interface: network
+        $schema: 1
+        label: [not, a, string]
+`))
+	c.Assert(err, ErrorMatches, `line 4: label of plug "net" is not a string \(found \[\]interface {}\)`)
+}
+
+func (s *YamlSuite) TestPlugsAndSlotsBadSchema(c *C) {
+	for _, schema := range []string{"0", "-1", "two", "[1]"} {
+		_, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+slots:
+    db:
+        interface: content
+        $schema: ` + schema + `
+`))
+		c.Check(err, ErrorMatches, `line 4: schema of slot "db" is not a positive integer \(found .*\)`, Commentf("%s", schema))
+	}
+}
+
+func (s *YamlSuite) TestPlugsAndSlotsNewerSchemaAreLenient(c *C) {
+	// NOTE: yaml content cannot use tabs, indent the section with spaces.
+	info, err := snap.InfoFromSnapYaml([]byte(`
+name: snap
+plugs:
+    net:
+        interface: network
+        $schema: 2
+        label:
+            en: Network
+        apps:
+            client: full
+        $reserved: value
+        1: numeric key
+        future: attribute
+slots:
+    db:
+        interface: content
+        $schema: 99
+        label: Database
+        apps: [client]
+        read: [$SNAP/db]
+apps:
+    client:
+        command: client
+`))
+	c.Assert(err, IsNil)
+
+	net := info.Plugs["net"]
+	c.Check(net.Interface, Equals, "network")
+	c.Check(net.Label, Equals, "")
+	// fields that are not understood are preserved as attributes
+	c.Check(net.Attrs, DeepEquals, map[string]interface{}{
+		"label":  map[string]interface{}{"en": "Network"},
+		"apps":   map[string]interface{}{"client": "full"},
+		"future": "attribute",
+	})
+	// and the plug is not scoped to any app
+	c.Check(net.Apps, HasLen, 1)
+
+	// fields that are understood keep their meaning
+	db := info.Slots["db"]
+	c.Check(db.Label, Equals, "Database")
+	c.Check(db.Attrs, DeepEquals, map[string]interface{}{"read": []interface{}{"$SNAP/db"}})
+	c.Check(db.Apps, HasLen, 1)
 }
 
 func (s *YamlSuite) TestUnmarshalReservedSlotAttribute(c *C) {
@@ -898,7 +1105,7 @@ slots:
         interface: serial-port
         $baud-rate: [9600]
 `))
-	c.Assert(err, ErrorMatches, `slot "serial" uses reserved attribute "\$baud-rate"`)
+	c.Assert(err, ErrorMatches, `line 4: slot "serial" uses reserved attribute "\$baud-rate"`)
 }
 
 func (s *YamlSuite) TestUnmarshalInvalidSlotAttribute(c *C) {
@@ -910,7 +1117,7 @@ slots:
         interface: serial-port
         foo: null
 `))
-	c.Assert(err, ErrorMatches, `attribute "foo" of slot \"serial\": invalid scalar:.*`)
+	c.Assert(err, ErrorMatches, `line 4: attribute "foo" of slot \"serial\": invalid scalar:.*`)
 }
 
 func (s *YamlSuite) TestUnmarshalHook(c *C) {
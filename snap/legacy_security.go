@@ -0,0 +1,149 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// legacyCapsInterfaces maps the policy groups that apps of the first
+// generation of snaps listed under "caps" to the interfaces granting
+// equivalent permissions.
+var legacyCapsInterfaces = map[string]string{
+	"networking":       "network",
+	"network-client":   "network",
+	"network-service":  "network-bind",
+	"network-listener": "network-bind",
+	"network-admin":    "network-control",
+	"network-firewall": "firewall-control",
+	"network-status":   "network-observe",
+	"opengl":           "opengl",
+	"audio":            "audio-playback",
+	"camera":           "camera",
+}
+
+// migrateLegacySecurity returns the plugs that give an app the permissions
+// its legacy "caps" and "security-template" declarations asked for, along
+// with notes on what was migrated and on what cannot be expressed with
+// interfaces.
+//
+// The legacy declarations were loosely specified, a malformed value is
+// reported in the notes rather than failing to parse the snap.
+func migrateLegacySecurity(appName string, yApp *appYaml) (plugNames []string, notes []string) {
+	var capNames []interface{}
+	switch caps := yApp.LegacyCaps.(type) {
+	case nil:
+	case []interface{}:
+		capNames = caps
+	default:
+		// a single cap given as a scalar
+		capNames = []interface{}{caps}
+	}
+	for _, capData := range capNames {
+		capName, ok := capData.(string)
+		if !ok {
+			notes = append(notes, fmt.Sprintf("app %q: malformed cap %v cannot be migrated", appName, capData))
+			continue
+		}
+		iface, ok := legacyCapsInterfaces[capName]
+		if !ok {
+			notes = append(notes, fmt.Sprintf("app %q: cap %q cannot be expressed with interfaces", appName, capName))
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("app %q: cap %q replaced by plug %q", appName, capName, iface))
+		plugNames = append(plugNames, iface)
+	}
+	switch template := yApp.LegacySecurityTemplate.(type) {
+	case nil:
+	case string:
+		switch template {
+		case "", "default":
+			// the default template is what strict confinement provides
+		default:
+			notes = append(notes, fmt.Sprintf("app %q: security-template %q cannot be expressed with interfaces", appName, template))
+		}
+	default:
+		notes = append(notes, fmt.Sprintf("app %q: malformed security-template %v cannot be migrated", appName, template))
+	}
+	if yApp.LegacySecurityOverride != nil {
+		notes = append(notes, fmt.Sprintf("app %q: security-override cannot be expressed with interfaces", appName))
+	}
+	return plugNames, notes
+}
+
+// legacySecurityPolicyPlug returns a plug of the old-security interface that
+// wraps the raw apparmor and seccomp policy files an app listed under
+// "security-policy", along with a note on the outcome. It returns no plug if
+// the app declares no such policy or if the policy cannot be wrapped.
+func legacySecurityPolicyPlug(snapInfo *Info, appName string, yApp *appYaml) (plug *PlugInfo, note string) {
+	if yApp.LegacySecurityPolicy == nil {
+		return nil, ""
+	}
+	attrs, ok := legacySecurityPolicyAttrs(yApp.LegacySecurityPolicy)
+	if !ok {
+		return nil, fmt.Sprintf("app %q: security-policy cannot be expressed with interfaces", appName)
+	}
+	plugName := "old-security-" + appName
+	if ValidatePlugName(plugName) != nil || snapInfo.Plugs[plugName] != nil {
+		return nil, fmt.Sprintf("app %q: security-policy cannot be expressed with interfaces", appName)
+	}
+	plug = &PlugInfo{
+		Snap:      snapInfo,
+		Name:      plugName,
+		Interface: "old-security",
+		Attrs:     attrs,
+		Apps:      make(map[string]*AppInfo),
+	}
+	return plug, fmt.Sprintf("app %q: security-policy wrapped by plug %q", appName, plugName)
+}
+
+// legacySecurityPolicyAttrs converts a "security-policy" declaration, which
+// maps "apparmor" and "seccomp" to paths of policy files inside the snap, to
+// the attributes of an old-security plug.
+func legacySecurityPolicyAttrs(policy interface{}) (attrs map[string]interface{}, ok bool) {
+	m, ok := policy.(map[interface{}]interface{})
+	if !ok || len(m) == 0 {
+		return nil, false
+	}
+	attrs = make(map[string]interface{}, len(m))
+	for k, v := range m {
+		key, ok := k.(string)
+		if !ok || (key != "apparmor" && key != "seccomp") {
+			return nil, false
+		}
+		path, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		attrs[key] = path
+	}
+	return attrs, true
+}
+
+// LegacySecuritySummary returns a summary of how the legacy security
+// declarations of the snap were migrated to plugs.
+func LegacySecuritySummary(snapInfo *Info) string {
+	notes := make([]string, len(snapInfo.LegacySecurityNotes))
+	copy(notes, snapInfo.LegacySecurityNotes)
+	sort.Strings(notes)
+	return fmt.Sprintf("snap %q uses legacy security declarations: %s", snapInfo.InstanceName(), strings.Join(notes, "; "))
+}
@@ -0,0 +1,196 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2021 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snap_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type legacySecuritySuite struct {
+	testutil.BaseTest
+}
+
+var _ = Suite(&legacySecuritySuite{})
+
+func (s *legacySecuritySuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+}
+
+func (s *legacySecuritySuite) TearDownTest(c *C) {
+	s.BaseTest.TearDownTest(c)
+}
+
+func (s *legacySecuritySuite) TestCapsMigratedToPlugs(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: foo
+version: 1
+plugs:
+  network:
+    interface: network
+apps:
+  app:
+    command: foo
+    plugs: [network]
+    caps: [networking, network-service, opengl]
+  other:
+    command: bar
+`))
+	c.Assert(err, IsNil)
+
+	app := info.Apps["app"]
+	c.Check(app.Plugs, HasLen, 3)
+	for _, name := range []string{"network", "network-bind", "opengl"} {
+		plug := info.Plugs[name]
+		c.Assert(plug, NotNil, Commentf("%s", name))
+		c.Check(plug.Interface, Equals, name)
+		c.Check(app.Plugs[name], Equals, plug)
+		c.Check(plug.Apps, DeepEquals, map[string]*snap.AppInfo{"app": app})
+	}
+	c.Check(info.Apps["other"].Plugs, HasLen, 0)
+	c.Check(snap.LegacySecuritySummary(info), Equals, `snap "foo" uses legacy security declarations: `+
+		`app "app": cap "network-service" replaced by plug "network-bind"; `+
+		`app "app": cap "networking" replaced by plug "network"; `+
+		`app "app": cap "opengl" replaced by plug "opengl"`)
+}
+
+func (s *legacySecuritySuite) TestUnexpressibleDeclarations(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: foo
+version: 1
+apps:
+  app:
+    command: foo
+    caps: [teleport]
+    security-template: unconfined
+  other:
+    command: bar
+    security-policy:
+      apparmor: meta/foo.apparmor
+      selinux: meta/foo.te
+    security-override:
+      read-paths: [/etc/foo]
+  plain:
+    command: baz
+    security-template: default
+`))
+	c.Assert(err, IsNil)
+
+	c.Check(info.Plugs, HasLen, 0)
+	c.Check(info.Apps["app"].Plugs, HasLen, 0)
+	c.Check(snap.LegacySecuritySummary(info), Equals, `snap "foo" uses legacy security declarations: `+
+		`app "app": cap "teleport" cannot be expressed with interfaces; `+
+		`app "app": security-template "unconfined" cannot be expressed with interfaces; `+
+		`app "other": security-override cannot be expressed with interfaces; `+
+		`app "other": security-policy cannot be expressed with interfaces`)
+}
+
+func (s *legacySecuritySuite) TestMalformedDeclarations(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: foo
+version: 1
+apps:
+  app:
+    command: foo
+    caps: networking
+  other:
+    command: bar
+    caps: [opengl, 42, [nested]]
+    security-template: [unconfined]
+  more:
+    command: baz
+    caps: {networking: true}
+`))
+	c.Assert(err, IsNil)
+
+	// a single cap given as a scalar is migrated
+	c.Check(info.Apps["app"].Plugs, HasLen, 1)
+	c.Check(info.Apps["app"].Plugs["network"], NotNil)
+	c.Check(info.Apps["other"].Plugs, HasLen, 1)
+	c.Check(info.Apps["other"].Plugs["opengl"], NotNil)
+	c.Check(info.Apps["more"].Plugs, HasLen, 0)
+	c.Check(snap.LegacySecuritySummary(info), Equals, `snap "foo" uses legacy security declarations: `+
+		`app "app": cap "networking" replaced by plug "network"; `+
+		`app "more": malformed cap map[networking:true] cannot be migrated; `+
+		`app "other": cap "opengl" replaced by plug "opengl"; `+
+		`app "other": malformed cap 42 cannot be migrated; `+
+		`app "other": malformed cap [nested] cannot be migrated; `+
+		`app "other": malformed security-template [unconfined] cannot be migrated`)
+}
+
+func (s *legacySecuritySuite) TestSecurityPolicyWrappedByPlug(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: foo
+version: 1
+apps:
+  app:
+    command: foo
+    security-policy:
+      apparmor: meta/foo.apparmor
+      seccomp: meta/foo.seccomp
+  other:
+    command: bar
+`))
+	c.Assert(err, IsNil)
+
+	app := info.Apps["app"]
+	plug := info.Plugs["old-security-app"]
+	c.Assert(plug, NotNil)
+	c.Check(plug.Interface, Equals, "old-security")
+	c.Check(plug.Attrs, DeepEquals, map[string]interface{}{
+		"apparmor": "meta/foo.apparmor",
+		"seccomp":  "meta/foo.seccomp",
+	})
+	c.Check(plug.Apps, DeepEquals, map[string]*snap.AppInfo{"app": app})
+	c.Check(app.Plugs, DeepEquals, map[string]*snap.PlugInfo{"old-security-app": plug})
+	c.Check(info.Apps["other"].Plugs, HasLen, 0)
+	c.Check(snap.LegacySecuritySummary(info), Equals, `snap "foo" uses legacy security declarations: `+
+		`app "app": security-policy wrapped by plug "old-security-app"`)
+}
+
+func (s *legacySecuritySuite) TestSecurityPolicyPlugNameTaken(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: foo
+version: 1
+plugs:
+  old-security-app:
+    interface: network
+apps:
+  app:
+    command: foo
+    security-policy:
+      apparmor: meta/foo.apparmor
+`))
+	c.Assert(err, IsNil)
+
+	c.Check(info.Plugs["old-security-app"].Interface, Equals, "network")
+	c.Check(snap.LegacySecuritySummary(info), Equals, `snap "foo" uses legacy security declarations: `+
+		`app "app": security-policy cannot be expressed with interfaces`)
+}
+
+func (s *legacySecuritySuite) TestNoLegacyDeclarations(c *C) {
+	info, err := snap.InfoFromSnapYaml([]byte(`name: foo
+version: 1
+apps:
+  app:
+    command: foo
+    plugs: [network]
+`))
+	c.Assert(err, IsNil)
+	c.Check(info.LegacySecurityNotes, HasLen, 0)
+}
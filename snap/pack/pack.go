@@ -101,6 +101,9 @@ func CheckSkeleton(w io.Writer, sourceDir string) error {
 		if len(info.BadInterfaces) > 0 {
 			fmt.Fprintln(w, snap.BadInterfacesSummary(info))
 		}
+		if len(info.LegacySecurityNotes) > 0 {
+			fmt.Fprintln(w, snap.LegacySecuritySummary(info))
+		}
 	}
 	return err
 }
@@ -224,6 +227,14 @@ func Snap(sourceDir string, opts *Options) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	// plugs and slots that snapd would reject are reported, but do not
+	// prevent packing as they might be known to a more recent snapd
+	if len(info.BadInterfaces) > 0 {
+		logger.Noticef("%s", snap.BadInterfacesSummary(info))
+	}
+	if len(info.LegacySecurityNotes) > 0 {
+		logger.Noticef("%s", snap.LegacySecuritySummary(info))
+	}
 
 	excludes, err := excludesFile()
 	if err != nil {
@@ -33,6 +33,7 @@ import (
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
 
 	// for SanitizePlugsSlots
 	_ "github.com/snapcore/snapd/interfaces/builtin"
@@ -152,6 +153,22 @@ apps:
 	c.Check(buf.String(), Equals, "")
 }
 
+func (s *packSuite) TestPackWarnsAboutBadPlugsSlots(c *C) {
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	sourceDir := makeExampleSnapSourceDir(c, `name: hello
+version: 0
+apps:
+ foo:
+  command: bin/hello-world
+  plugs: [potato]
+`)
+	_, err := pack.Snap(sourceDir, &pack.Options{TargetDir: c.MkDir()})
+	c.Assert(err, IsNil)
+	c.Check(logbuf.String(), Matches, `(?m).* snap "hello" has bad plugs or slots: potato \(unknown interface "potato"\)`)
+}
+
 func (s *packSuite) TestPackExcludesBackups(c *C) {
 	sourceDir := makeExampleSnapSourceDir(c, "{name: hello, version: 0}")
 	target := c.MkDir()